@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	_ "github.com/psssix/archiver/pkg/compression/ans"
+	_ "github.com/psssix/archiver/pkg/compression/arith"
+	_ "github.com/psssix/archiver/pkg/compression/bwt"
+	_ "github.com/psssix/archiver/pkg/compression/gzip"
+	_ "github.com/psssix/archiver/pkg/compression/huffman"
+	_ "github.com/psssix/archiver/pkg/compression/lzss"
+	_ "github.com/psssix/archiver/pkg/compression/lzw"
+	_ "github.com/psssix/archiver/pkg/compression/rle"
+	_ "github.com/psssix/archiver/pkg/compression/std"
+	_ "github.com/psssix/archiver/pkg/compression/vlc"
+	"github.com/spf13/cobra"
+)
+
+// The pack and unpack commands of every registered codec are generated, the codecs register themselves
+// when their packages are imported above.
+func init() {
+	for _, name := range compression.List() {
+		f, _ := compression.Lookup(name)
+
+		if f.Pack != nil {
+			packCmd.AddCommand(codecPackCmd(name, f))
+		}
+		unpackCmd.AddCommand(codecUnpackCmd(name, f))
+	}
+}
+
+// codecPackCmd returns the command packing files with the codec, its flags are the packing options.
+func codecPackCmd(name string, f compression.Factory) *cobra.Command {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	newWriter := f.Pack(fs)
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s <path to source file> [path to packed file]", name),
+		Short: fmt.Sprintf("Pack file with %s", f.Description),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return packFile(args, f.Extension, newWriter)
+		},
+	}
+	cmd.Flags().AddGoFlagSet(fs)
+
+	return cmd
+}
+
+// codecUnpackCmd returns the command unpacking files packed with the codec, its flags are the unpacking options.
+func codecUnpackCmd(name string, f compression.Factory) *cobra.Command {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	newReader := f.Unpack(fs)
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s <path to source file> [path to unpacked file]", name),
+		Short: fmt.Sprintf("Unpack file packed with %s", f.Description),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return unpackFile(args, newReader)
+		},
+	}
+	cmd.Flags().AddGoFlagSet(fs)
+
+	return cmd
+}
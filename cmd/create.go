@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/psssix/archiver/pkg/ignore"
+	"github.com/spf13/cobra"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+var ErrEmptyArchivePath = errors.New("path to archive is not specified")
+
+var createCmd = &cobra.Command{
+	Use:   "create <path to archive> <paths to files and directories>...",
+	Short: "Create archive of files and directories",
+	Long: `Create archive of files and directories.
+
+The directories are packed with all their contents, the entries are recorded relative to the parent directory
+of every given path. The patterns of --include and --exclude are matched with the paths recorded in the archive,
+the included directories are packed with all their contents.
+The paths matched by the patterns of .archiverignore files are skipped, the patterns are relative
+to the directory of the file and follow the rules of .gitignore files.`,
+	RunE: create,
+}
+
+// ignoreFile is the name of the files with the patterns of the paths skipped in their directories.
+const ignoreFile = ".archiverignore"
+
+var (
+	// createCodec is the name of the codec the files are packed with.
+	createCodec string
+	// createIncludes and createExcludes are the patterns of the paths packed and skipped.
+	createIncludes []string
+	createExcludes []string
+)
+
+func init() {
+	createCmd.Flags().StringVar(&createCodec, "codec", "lzss", "name of the codec the files are packed with")
+	createCmd.Flags().StringArrayVar(
+		&createIncludes, "include", nil,
+		"pack only the files and directories matching the glob pattern, can be repeated",
+	)
+	createCmd.Flags().StringArrayVar(
+		&createExcludes, "exclude", nil,
+		"skip the files and directories matching the glob pattern, can be repeated",
+	)
+
+	rootCmd.AddCommand(createCmd)
+}
+
+func create(_ *cobra.Command, args []string) error {
+	if len(args) == 0 || args[0] == "" {
+		return ErrEmptyArchivePath
+	}
+	if len(args) == 1 {
+		return ErrEmptySourceFilePath
+	}
+
+	// the codec and the patterns are checked before the archive is created
+	if err := archive.ValidateCodec(createCodec); err != nil {
+		return err
+	}
+	var c creator
+	if err := addPatterns(&c.includes, createIncludes); err != nil {
+		return err
+	}
+	if err := addPatterns(&c.excludes, createExcludes); err != nil {
+		return err
+	}
+
+	archiveFile, paths := args[0], args[1:]
+	return writeFile(archiveFile, func(dst io.Writer) error {
+		info, err := os.Stat(archiveFile)
+		if err != nil {
+			return err
+		}
+
+		buf := bufio.NewWriter(dst)
+		c.w, c.archive = archive.NewWriter(buf), info
+		for _, p := range paths {
+			if err := c.add(p); err != nil {
+				return err
+			}
+		}
+		if err := c.w.Close(); err != nil {
+			return err
+		}
+		return buf.Flush()
+	})
+}
+
+// addPatterns adds the patterns relative to the root to the matcher.
+func addPatterns(m *ignore.Matcher, patterns []string) error {
+	for _, s := range patterns {
+		p, err := ignore.ParsePattern(s)
+		if err != nil {
+			return err
+		}
+		m.Add("", p)
+	}
+	return nil
+}
+
+// creator adds files and directories to the archive.
+type creator struct {
+	w *archive.Writer
+	// archive is the archive being created, it is never added to itself.
+	archive fs.FileInfo
+	// includes and excludes match the paths in the archive, all the paths are included without includes.
+	includes, excludes ignore.Matcher
+}
+
+// add adds the file or the directory with all its contents to the archive.
+func (c *creator) add(root string) error {
+	prefix := rootName(root)
+	// ignored matches the paths relative to the root with the patterns of the ignore files found so far
+	var ignored ignore.Matcher
+
+	return filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		name := path.Join(prefix, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if os.SameFile(info, c.archive) || c.skip(&ignored, rel, name, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			base := rel
+			if base == "." {
+				base = ""
+			}
+			if err := readIgnoreFile(&ignored, base, file); err != nil {
+				return err
+			}
+		}
+
+		if name == "." || !c.includes.Empty() && !matchParents(name, d.IsDir(), c.includes.Match) {
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			_, _ = fmt.Fprintf(os.Stderr, "skipping %s: not a regular file\n", file)
+			return nil
+		}
+
+		e := archive.Entry{Path: name, Mode: info.Mode(), ModTime: info.ModTime(), Codec: createCodec}
+		w, err := c.w.Create(e)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		return copyFile(w, file)
+	})
+}
+
+// skip reports whether the path is excluded or ignored, rel is the path relative to the root
+// and name is the path in the archive.
+func (c *creator) skip(ignored *ignore.Matcher, rel, name string, isDir bool) bool {
+	if name != "." && c.excludes.Match(name, isDir) {
+		return true
+	}
+	return rel != "." && ignored.Match(rel, isDir)
+}
+
+// readIgnoreFile adds the patterns of the ignore file in the directory to the matcher, if the file exists.
+func readIgnoreFile(m *ignore.Matcher, base, dir string) error {
+	f, err := os.Open(filepath.Join(dir, ignoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := m.ReadPatterns(base, f); err != nil {
+		return fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return nil
+}
+
+// copyFile copies the contents of the file to w.
+func copyFile(w io.Writer, file string) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// rootName returns the path in the archive of the given file or directory, it is the last element of the path,
+// so the entries are recorded relative to its parent directory. The contents of the root, the current
+// and the parent directories are recorded at the root of the archive, its path is ".".
+func rootName(root string) string {
+	name := filepath.Base(root)
+	if name == string(filepath.Separator) || name == ".." {
+		return "."
+	}
+	return name
+}
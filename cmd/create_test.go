@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates the files with the contents in the directory, creating their parent directories.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, contents := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.Nil(t, os.WriteFile(file, []byte(contents), 0644))
+	}
+}
+
+// sourceTree is the tree of the tests of the creator, *.tmp and the logs of lib are ignored by .archiverignore files.
+var sourceTree = map[string]string{
+	"src/.archiverignore":      "*.tmp\n",
+	"src/cache.tmp":            "cache",
+	"src/lib/.archiverignore":  "logs/*.log\n",
+	"src/lib/logs/lib.log":     "lib log",
+	"src/lib/logs/lib.txt":     "lib notes",
+	"src/lib/util.go":          "package lib",
+	"src/logs/app.log":         "app log",
+	"src/logs/old.log":         "old log",
+	"src/main.go":              "package main",
+	"src/notes.txt":            "notes",
+	"src/logs/archive/old.tmp": "temporary",
+}
+
+func TestCreatorAdd(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "src")
+	writeTree(t, filepath.Dir(root), sourceTree)
+
+	tests := []struct {
+		name               string
+		includes, excludes []string
+		want               []string
+	}{
+		{
+			name: "all files",
+			want: []string{
+				"src", "src/.archiverignore", "src/lib", "src/lib/.archiverignore", "src/lib/logs", "src/lib/logs/lib.txt",
+				"src/lib/util.go", "src/logs", "src/logs/app.log", "src/logs/archive", "src/logs/old.log",
+				"src/main.go", "src/notes.txt",
+			},
+		},
+		{
+			name:     "included directories at any depth",
+			includes: []string{"logs/"},
+			want: []string{
+				"src/lib/logs", "src/lib/logs/lib.txt", "src/logs", "src/logs/app.log", "src/logs/archive",
+				"src/logs/old.log",
+			},
+		},
+		{
+			name:     "included directory path",
+			includes: []string{"src/logs"},
+			want:     []string{"src/logs", "src/logs/app.log", "src/logs/archive", "src/logs/old.log"},
+		},
+		{
+			name:     "included directory without excluded files",
+			includes: []string{"logs/"},
+			excludes: []string{"old.log", "archive/"},
+			want:     []string{"src/lib/logs", "src/lib/logs/lib.txt", "src/logs", "src/logs/app.log"},
+		},
+		{
+			name:     "included files without excluded directory",
+			includes: []string{"*.go", "*.txt"},
+			excludes: []string{"lib/"},
+			want:     []string{"src/main.go", "src/notes.txt"},
+		},
+		{
+			name:     "excluded root",
+			excludes: []string{"src"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("add %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			c := creator{w: archive.NewWriter(&buf)}
+			assert.Nil(t, addPatterns(&c.includes, test.includes))
+			assert.Nil(t, addPatterns(&c.excludes, test.excludes))
+
+			assert.Nil(t, c.add(root))
+			assert.Nil(t, c.w.Close())
+
+			z, err := archive.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			assert.Nil(t, err)
+			var got []string
+			for _, e := range z.Entries {
+				got = append(got, e.Path)
+			}
+			assert.Equalf(t, test.want, got, "creator.add() with includes %q and excludes %q", test.includes, test.excludes)
+		})
+	}
+}
+
+func TestCreatorAddContents(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "src")
+	writeTree(t, filepath.Dir(root), sourceTree)
+
+	var buf bytes.Buffer
+	c := creator{w: archive.NewWriter(&buf)}
+	assert.Nil(t, addPatterns(&c.includes, []string{"src/logs"}))
+	assert.Nil(t, c.add(root))
+	assert.Nil(t, c.w.Close())
+
+	z, err := archive.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	for i := range z.Entries {
+		e := &z.Entries[i]
+		if e.IsDir() {
+			continue
+		}
+
+		assert.Equal(t, "lzss", e.Codec)
+		r, err := z.Open(e)
+		assert.Nil(t, err)
+		var data bytes.Buffer
+		_, err = data.ReadFrom(r)
+		assert.Nil(t, err)
+		assert.Nil(t, r.Close())
+		assert.Equalf(t, sourceTree[e.Path], data.String(), "unexpected contents of %s", e.Path)
+	}
+}
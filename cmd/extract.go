@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/psssix/archiver/pkg/ignore"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var extractCmd = &cobra.Command{
+	Use:   "extract <path to archive> [paths or patterns of entries]...",
+	Short: "Extract files and directories from archive",
+	Long: `Extract files and directories from archive.
+
+Without paths all the entries are extracted. Otherwise only the entries matching the paths or the glob patterns
+are extracted, with all the contents of the matching directories. A path without the glob characters *?[\
+matches the entry with this very path, the patterns follow the rules of .gitignore files. Only the packed data
+of the extracted entries is read and unpacked.`,
+	RunE: extract,
+}
+
+// extractOutputDir is the directory the entries are extracted to.
+var extractOutputDir string
+
+func init() {
+	extractCmd.Flags().StringVar(&extractOutputDir, "output-dir", ".", "directory the entries are extracted to")
+
+	rootCmd.AddCommand(extractCmd)
+}
+
+func extract(_ *cobra.Command, args []string) error {
+	if len(args) == 0 || args[0] == "" {
+		return ErrEmptyArchivePath
+	}
+
+	sel, err := newSelection(args[1:])
+	if err != nil {
+		return err
+	}
+
+	f, z, err := openArchive(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var dirs []archive.Entry
+	for i := range z.Entries {
+		e := &z.Entries[i]
+		if !sel.match(e.Path, e.IsDir()) {
+			continue
+		}
+
+		if e.IsDir() {
+			if err := os.MkdirAll(outputPath(e), 0755); err != nil {
+				return err
+			}
+			dirs = append(dirs, *e)
+			continue
+		}
+		if err := extractEntry(z, e); err != nil {
+			return err
+		}
+	}
+
+	// the modification times of the directories are changed by extracting their contents, so they are set last
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := setAttributes(outputPath(&dirs[i]), &dirs[i]); err != nil {
+			return err
+		}
+	}
+
+	if unmatched := sel.unmatched(); len(unmatched) > 0 {
+		return fmt.Errorf("no entries match %s", strings.Join(unmatched, ", "))
+	}
+	return nil
+}
+
+// selection selects the extracted entries with the paths and the patterns.
+type selection struct {
+	args     []string
+	patterns []ignore.Pattern
+	// matched tells which patterns matched any entry
+	matched []bool
+}
+
+// newSelection returns the selection of the entries matching the paths or the patterns,
+// without them all the entries are selected.
+func newSelection(args []string) (*selection, error) {
+	s := &selection{args: args, patterns: make([]ignore.Pattern, len(args)), matched: make([]bool, len(args))}
+	for i, arg := range args {
+		p, err := ignore.ParsePattern(selectionPattern(arg))
+		if err != nil {
+			return nil, err
+		}
+		s.patterns[i] = p
+	}
+	return s, nil
+}
+
+// selectionPattern returns the pattern of the argument. A path without glob characters is anchored
+// to the root of the archive, so it matches only the entry with the same path.
+func selectionPattern(arg string) string {
+	if strings.ContainsAny(arg, `*?[\`) {
+		return arg
+	}
+
+	pattern := "/" + strings.TrimPrefix(path.Clean(arg), "/")
+	if strings.HasSuffix(arg, "/") {
+		pattern += "/"
+	}
+	return pattern
+}
+
+// match reports whether the entry or any of its parent directories matches any pattern.
+func (s *selection) match(name string, isDir bool) bool {
+	if len(s.patterns) == 0 {
+		return true
+	}
+
+	return matchParents(name, isDir, func(name string, isDir bool) bool {
+		selected := false
+		for i, p := range s.patterns {
+			if p.Match(name, isDir) {
+				s.matched[i] = true
+				selected = true
+			}
+		}
+		return selected
+	})
+}
+
+// matchParents reports whether match reports true for the path in the archive or any of its parent directories,
+// so the contents of a matching directory match as well. match is called for all of them.
+func matchParents(name string, isDir bool, match func(name string, isDir bool) bool) bool {
+	matched := false
+	for ; name != "."; name, isDir = path.Dir(name), true {
+		if match(name, isDir) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// unmatched returns the quoted paths and patterns that matched no entry.
+func (s *selection) unmatched() []string {
+	var unmatched []string
+	for i, arg := range s.args {
+		if !s.matched[i] {
+			unmatched = append(unmatched, fmt.Sprintf("%q", arg))
+		}
+	}
+	return unmatched
+}
+
+// outputPath returns the path the entry is extracted to.
+func outputPath(e *archive.Entry) string {
+	return filepath.Join(extractOutputDir, filepath.FromSlash(e.Path))
+}
+
+// extractEntry extracts the file of the entry, creating its parent directories.
+func extractEntry(z *archive.Reader, e *archive.Entry) error {
+	file := outputPath(e)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	r, err := z.Open(e)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := writeFile(file, func(dst io.Writer) error {
+		_, err := io.Copy(dst, r)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return setAttributes(file, e)
+}
+
+// setAttributes sets the permissions and the modification time of the file to the ones of the entry.
+func setAttributes(file string, e *archive.Entry) error {
+	if err := os.Chmod(file, e.Mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(file, e.ModTime, e.ModTime)
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSelectionMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{name: "everything without arguments", path: "dir/a.txt", want: true},
+		{name: "exact path", args: []string{"a.txt"}, path: "a.txt", want: true},
+		{name: "exact path at other depth", args: []string{"a.txt"}, path: "dir/a.txt"},
+		{name: "exact nested path", args: []string{"dir/a.txt"}, path: "dir/a.txt", want: true},
+		{name: "exact nested path at other depth", args: []string{"dir/a.txt"}, path: "src/dir/a.txt"},
+		{name: "cleaned path", args: []string{"./dir//a.txt"}, path: "dir/a.txt", want: true},
+		{name: "rooted path", args: []string{"/dir/a.txt"}, path: "dir/a.txt", want: true},
+		{name: "directory", args: []string{"dir"}, path: "dir", isDir: true, want: true},
+		{name: "contents of directory", args: []string{"dir"}, path: "dir/sub/b.txt", want: true},
+		{name: "directory prefix", args: []string{"dir"}, path: "directory/b.txt"},
+		{name: "directory only path", args: []string{"a.txt/"}, path: "a.txt"},
+		{name: "contents of directory only path", args: []string{"dir/"}, path: "dir/a.txt", want: true},
+		{name: "pattern at any depth", args: []string{"*.txt"}, path: "dir/sub/b.txt", want: true},
+		{name: "pattern of directory", args: []string{"s?b"}, path: "dir/sub/b.txt", want: true},
+		{name: "anchored pattern", args: []string{"dir/*.txt"}, path: "src/dir/a.txt"},
+		{name: "any of arguments", args: []string{"a.txt", "*.log"}, path: "dir/c.log", want: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("match %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := newSelection(test.args)
+			assert.Nil(t, err)
+			assert.Equalf(t, test.want, s.match(test.path, test.isDir),
+				"selection.match(%q, %t) with %q", test.path, test.isDir, test.args)
+		})
+	}
+}
+
+func TestSelectionUnmatched(t *testing.T) {
+	t.Parallel()
+
+	s, err := newSelection([]string{"a.txt", "*.log", "b.log/", "missing"})
+	assert.Nil(t, err)
+	for _, name := range []string{"a.txt", "dir/a.txt", "b.log"} {
+		s.match(name, false)
+	}
+	assert.Equal(t, []string{`"b.log/"`, `"missing"`}, s.unmatched())
+
+	_, err = newSelection([]string{"["})
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+}
+
+// extractTree is the tree of the tests of the extraction.
+var extractTree = map[string]string{
+	"data/a.txt":         "root a",
+	"data/dir/a.txt":     "dir a",
+	"data/dir/c.log":     "dir c",
+	"data/dir/sub/b.txt": "sub b",
+}
+
+// dirTime is the modification time of the directories of extractTree.
+var dirTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// writeArchive writes the archive of extractTree and returns its path. The directory data/dir has
+// the permissions 0700 and the directories have the modification time dirTime.
+func writeArchive(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTree(t, dir, extractTree)
+	assert.Nil(t, os.Chmod(filepath.Join(dir, "data", "dir"), 0700))
+	for _, name := range []string{"data", "data/dir", "data/dir/sub"} {
+		assert.Nil(t, os.Chtimes(filepath.Join(dir, filepath.FromSlash(name)), dirTime, dirTime))
+	}
+
+	file := filepath.Join(dir, "data.arc")
+	f, err := os.Create(file)
+	assert.Nil(t, err)
+	c := creator{w: archive.NewWriter(f)}
+	assert.Nil(t, c.add(filepath.Join(dir, "data")))
+	assert.Nil(t, c.w.Close())
+	assert.Nil(t, f.Close())
+
+	return file
+}
+
+// extractedFiles returns the contents of the files extracted to the directory by their slash separated paths.
+func extractedFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	assert.Nil(t, filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	}))
+	return files
+}
+
+// The tests of extract change extractOutputDir, so they are not parallel.
+
+func TestExtract(t *testing.T) {
+	file := writeArchive(t)
+
+	tests := []struct {
+		name, error string
+		args        []string
+		want        []string
+	}{
+		{name: "all entries", want: []string{"data/a.txt", "data/dir/a.txt", "data/dir/c.log", "data/dir/sub/b.txt"}},
+		{name: "exact path", args: []string{"data/a.txt"}, want: []string{"data/a.txt"}},
+		{name: "directory", args: []string{"data/dir/sub"}, want: []string{"data/dir/sub/b.txt"}},
+		{
+			name:  "unmatched path",
+			args:  []string{"a.txt", "*.log"},
+			want:  []string{"data/dir/c.log"},
+			error: `no entries match "a.txt"`,
+		},
+		{name: "files by pattern", args: []string{"**/a.txt"}, want: []string{"data/a.txt", "data/dir/a.txt"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("extract %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			defer func(dir string) { extractOutputDir = dir }(extractOutputDir)
+			extractOutputDir = t.TempDir()
+
+			err := extract(nil, append([]string{file}, test.args...))
+			if test.error != "" {
+				assert.EqualError(t, err, test.error)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			files := extractedFiles(t, extractOutputDir)
+			var got []string
+			for name, contents := range files {
+				assert.Equalf(t, extractTree[name], contents, "unexpected contents of %s", name)
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			assert.Equalf(t, test.want, got, "extract() with %q", test.args)
+		})
+	}
+}
+
+func TestExtractDirectoryAttributes(t *testing.T) {
+	file := writeArchive(t)
+
+	defer func(dir string) { extractOutputDir = dir }(extractOutputDir)
+	extractOutputDir = filepath.Join(t.TempDir(), "out")
+
+	assert.Nil(t, extract(nil, []string{file, "data/dir"}))
+
+	for _, name := range []string{"data/dir", "data/dir/sub"} {
+		info, err := os.Stat(filepath.Join(extractOutputDir, filepath.FromSlash(name)))
+		assert.Nil(t, err)
+		assert.Truef(t, info.ModTime().Equal(dirTime), "unexpected modification time %v of %s", info.ModTime(), name)
+	}
+	info, err := os.Stat(filepath.Join(extractOutputDir, "data", "dir"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+}
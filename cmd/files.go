@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/rle"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrEmptySourceFilePath = errors.New("path to source file is not specified")
+var ErrEmptyPackedFilePath = errors.New("path to packed file is not specified")
+var ErrEmptyUnpackedFilePath = errors.New("path to unpacked file is not specified")
+var ErrSameFile = errors.New("source and destination are the same file")
+
+// packFile packs the source file to the packed file with the writer returned by newWriter for the given header.
+// Without the packed file path it is generated with the given extension.
+func packFile(args []string, ext string, newWriter compression.WriterFunc) error {
+	var (
+		srcFile    string
+		packedFile string
+	)
+
+	switch len(args) {
+	case 0:
+		return ErrEmptySourceFilePath
+	case 1:
+		srcFile = args[0]
+		packedFile = generateFileName(srcFile, ext)
+	default:
+		srcFile = args[0]
+		packedFile = args[1]
+	}
+
+	if srcFile == "" {
+		return ErrEmptySourceFilePath
+	}
+
+	if packedFile == "" {
+		return ErrEmptyPackedFilePath
+	}
+
+	return transformFile(srcFile, packedFile, func(dst io.Writer, src *os.File) error {
+		info, err := src.Stat()
+		if err != nil {
+			return err
+		}
+
+		h := compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(info.Size())}
+
+		var w io.WriteCloser
+		if packRLE {
+			z := rle.NewPackingWriter(dst, newWriter)
+			z.Header = h
+			w = z
+		} else if w, err = newWriter(dst, h); err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// unpackFile unpacks the source file to the unpacked file with the reader returned by newReader.
+func unpackFile(args []string, newReader compression.ReaderFunc) error {
+	var (
+		srcFile      string
+		unpackedFile string
+	)
+
+	switch len(args) {
+	case 0:
+		return ErrEmptySourceFilePath
+	case 1:
+		srcFile = args[0]
+		unpackedFile = generateFileName(srcFile, "txt")
+	default:
+		srcFile = args[0]
+		unpackedFile = args[1]
+	}
+
+	if srcFile == "" {
+		return ErrEmptySourceFilePath
+	}
+
+	if unpackedFile == "" {
+		return ErrEmptyUnpackedFilePath
+	}
+
+	return transformFile(srcFile, unpackedFile, func(dst io.Writer, src *os.File) error {
+		var r io.ReadCloser
+		if unpackRLE {
+			r = rle.NewUnpackingReader(src, newReader)
+		} else {
+			r = newReader(src)
+		}
+		defer r.Close()
+		_, err := io.Copy(dst, r)
+		return err
+	})
+}
+
+// transformFile streams the source file through transform to the destination file, see writeFile.
+// It fails with ErrSameFile if the destination file is the source file.
+func transformFile(srcFile, dstFile string, transform func(dst io.Writer, src *os.File) error) error {
+	src, err := os.Open(srcFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := os.Stat(dstFile); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return ErrSameFile
+	}
+
+	return writeFile(dstFile, func(dst io.Writer) error {
+		return transform(dst, src)
+	})
+}
+
+// writeFile writes the destination file with write. The data is written to a temporary file in the same
+// directory, which replaces the destination file only if writing succeeds, so a failure leaves it intact.
+func writeFile(dstFile string, write func(dst io.Writer) error) (err error) {
+	dst, err := os.CreateTemp(filepath.Dir(dstFile), "."+filepath.Base(dstFile)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(dst.Name(), dstFile)
+		}
+		if err != nil {
+			_ = os.Remove(dst.Name())
+		}
+	}()
+
+	// the temporary file is created with the permissions 0600
+	if err := dst.Chmod(0644); err != nil {
+		return err
+	}
+	return write(dst)
+}
+
+// openArchive opens the archive file and reads its index, the file is closed if reading fails.
+// It is the caller's responsibility to close the returned file when done.
+func openArchive(file string) (*os.File, *archive.Reader, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	z, err := archive.NewReader(f, info.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	return f, z, nil
+}
+
+func generateFileName(file, ext string) string {
+	name := filepath.Base(file)
+	return strings.TrimSuffix(name, filepath.Ext(file)) + "." + ext
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// lookupWriter returns the writer of the registered codec with the default options.
+func lookupWriter(t *testing.T, name string) compression.WriterFunc {
+	t.Helper()
+
+	f, ok := compression.Lookup(name)
+	assert.Truef(t, ok, "codec %s is not registered", name)
+	return f.Pack(flag.NewFlagSet(name, flag.ContinueOnError))
+}
+
+// lookupReader returns the reader of the registered codec with the default options.
+func lookupReader(t *testing.T, name string) compression.ReaderFunc {
+	t.Helper()
+
+	f, ok := compression.Lookup(name)
+	assert.Truef(t, ok, "codec %s is not registered", name)
+	return f.Unpack(flag.NewFlagSet(name, flag.ContinueOnError))
+}
+
+// detectingReader unpacks the data with the detected codec, like the unpack command.
+func detectingReader(src io.Reader) io.ReadCloser {
+	return compression.NewDetectingReader(src)
+}
+
+func TestTransformFileKeepsFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		transform func(t *testing.T, src, dst string) error
+		sameFile  bool
+		err       error
+	}{
+		{
+			name: "packing to source file",
+			transform: func(t *testing.T, src, dst string) error {
+				return packFile([]string{src, dst}, "vlc", lookupWriter(t, "vlc"))
+			},
+			sameFile: true,
+			err:      ErrSameFile,
+		},
+		{
+			name: "unpacking to source file",
+			transform: func(t *testing.T, src, dst string) error {
+				return unpackFile([]string{src, dst}, detectingReader)
+			},
+			sameFile: true,
+			err:      ErrSameFile,
+		},
+		{
+			name: "unpacking unrecognized file to existing file",
+			transform: func(t *testing.T, src, dst string) error {
+				return unpackFile([]string{src, dst}, detectingReader)
+			},
+			err: compression.ErrUnrecognized,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("keeping files when %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			src, dst := filepath.Join(dir, "notes.txt"), filepath.Join(dir, "notes.out")
+			assert.Nil(t, os.WriteFile(src, []byte("plain notes"), 0644))
+			assert.Nil(t, os.WriteFile(dst, []byte("existing notes"), 0644))
+			if test.sameFile {
+				dst = src
+			}
+
+			assert.ErrorIs(t, test.transform(t, src, dst), test.err)
+
+			data, err := os.ReadFile(src)
+			assert.Nil(t, err)
+			assert.Equal(t, "plain notes", string(data))
+			if !test.sameFile {
+				data, err = os.ReadFile(dst)
+				assert.Nil(t, err)
+				assert.Equal(t, "existing notes", string(data))
+			}
+			files, err := os.ReadDir(dir)
+			assert.Nil(t, err)
+			assert.Lenf(t, files, 2, "temporary files are left in %s", dir)
+		})
+	}
+}
+
+func TestTransformFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src, packed, unpacked := filepath.Join(dir, "notes.txt"), filepath.Join(dir, "notes.vlc"), filepath.Join(dir, "out.txt")
+	assert.Nil(t, os.WriteFile(src, []byte("plain notes"), 0644))
+	assert.Nil(t, os.WriteFile(unpacked, []byte("existing notes, replaced"), 0644))
+
+	assert.Nil(t, packFile([]string{src, packed}, "vlc", lookupWriter(t, "vlc")))
+	assert.Nil(t, unpackFile([]string{packed, unpacked}, detectingReader))
+
+	data, err := os.ReadFile(unpacked)
+	assert.Nil(t, err)
+	assert.Equal(t, "plain notes", string(data))
+	info, err := os.Stat(unpacked)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+// TestPackFileRLE changes packRLE and unpackRLE, so it is not parallel.
+func TestPackFileRLE(t *testing.T) {
+	defer func(pack, unpack bool) { packRLE, unpackRLE = pack, unpack }(packRLE, unpackRLE)
+
+	dir := t.TempDir()
+	src, packed := filepath.Join(dir, "runs.bin"), filepath.Join(dir, "runs.huffman")
+	data := bytes.Repeat(append(bytes.Repeat([]byte{'a'}, 300), "some text between runs"...), 50)
+	assert.Nil(t, os.WriteFile(src, data, 0644))
+
+	packRLE = true
+	assert.Nil(t, packFile([]string{src, packed}, "huffman", lookupWriter(t, "huffman")))
+
+	tests := []struct {
+		name      string
+		rle       bool
+		newReader compression.ReaderFunc
+	}{
+		{name: "detected codec", newReader: detectingReader},
+		{name: "codec with rle flag", rle: true, newReader: lookupReader(t, "huffman")},
+	}
+
+	for _, test := range tests {
+		unpackRLE = test.rle
+		unpacked := filepath.Join(t.TempDir(), "runs.bin")
+		assert.Nilf(t, unpackFile([]string{packed, unpacked}, test.newReader), "unpacking with %s", test.name)
+		got, err := os.ReadFile(unpacked)
+		assert.Nil(t, err)
+		assert.Truef(t, bytes.Equal(data, got), "unexpected data unpacked with %s", test.name)
+	}
+
+	corrupted, total, err := testPackedFile(packed)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, corrupted)
+	assert.Equal(t, 1, total)
+
+	entries, err := listPackedFile(packed)
+	assert.Nil(t, err)
+	assert.Equal(t, "rle+huffman", entries[0].Codec)
+	assert.Equal(t, sizeOf(uint64(len(data))), entries[0].Size)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list <path to archive or packed file>",
+	Short: "List entries of archive without unpacking them",
+	Long: `List entries of archive without unpacking them.
+
+For a single packed file its codec is detected, the size of the original data is shown
+when it is recorded in the header.`,
+	RunE: list,
+}
+
+// listJSON makes the list command print the entries as JSON.
+var listJSON bool
+
+func init() {
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "print the entries as a JSON array")
+
+	rootCmd.AddCommand(listCmd)
+}
+
+// listEntry is the listed entry, Size is nil when the size of the original data is unknown
+// and Ratio is nil when the size is unknown or zero.
+type listEntry struct {
+	Path       string    `json:"path"`
+	Size       *uint64   `json:"size"`
+	PackedSize uint64    `json:"packed_size"`
+	Ratio      *float64  `json:"ratio"`
+	Codec      string    `json:"codec"`
+	Mode       string    `json:"mode"`
+	ModTime    time.Time `json:"mtime"`
+}
+
+func list(_ *cobra.Command, args []string) error {
+	if len(args) == 0 || args[0] == "" {
+		return ErrEmptyArchivePath
+	}
+
+	entries, err := listArchive(args[0])
+	if errors.Is(err, archive.ErrFormat) {
+		entries, err = listPackedFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if listJSON {
+		return printJSON(os.Stdout, entries)
+	}
+	return printEntries(os.Stdout, entries)
+}
+
+// listArchive returns the entries of the archive, only the index is read.
+func listArchive(file string) ([]listEntry, error) {
+	f, z, err := openArchive(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make([]listEntry, 0, len(z.Entries))
+	for _, e := range z.Entries {
+		size := e.Size
+		entries = append(entries, listEntry{
+			Path:       e.Path,
+			Size:       &size,
+			PackedSize: e.PackedSize,
+			Ratio:      ratio(e.Size, e.PackedSize),
+			Codec:      e.Codec,
+			Mode:       e.Mode.String(),
+			ModTime:    e.ModTime,
+		})
+	}
+
+	return entries, nil
+}
+
+// listPackedFile returns the single entry of the packed file with the detected codec, it returns
+// archive.ErrFormat if the codec is not detected. Only the header of the file is read.
+func listPackedFile(file string) ([]listEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, compression.DetectLen)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	codec, err := compression.Detect(header[:n])
+	if errors.Is(err, compression.ErrUnrecognized) {
+		return nil, archive.ErrFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e := listEntry{
+		Path:       file,
+		PackedSize: uint64(info.Size()),
+		Codec:      codec,
+		Mode:       info.Mode().String(),
+		ModTime:    info.ModTime(),
+	}
+	// the headers of the codecs of this module record the size of the original data
+	h, err := compression.ReadHeader(io.NewSectionReader(f, 0, info.Size()))
+	if err == nil && h.Flags&compression.FlagSize != 0 {
+		e.Size = &h.Size
+		e.Ratio = ratio(h.Size, e.PackedSize)
+	}
+	// the data packed once more starts with the header of its codec, see compression.FlagPacked
+	if err == nil && h.Flags&compression.FlagPacked != 0 {
+		n, err := f.ReadAt(header, int64(compression.HeaderSize))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if packed, err := compression.Detect(header[:n]); err == nil {
+			e.Codec += "+" + packed
+		}
+	}
+
+	return []listEntry{e}, nil
+}
+
+// ratio returns the ratio of the packed size to the original size, it is nil for empty data.
+func ratio(size, packedSize uint64) *float64 {
+	if size == 0 {
+		return nil
+	}
+	r := float64(packedSize) / float64(size)
+	return &r
+}
+
+// printEntries prints the entries as a table followed by the totals.
+func printEntries(w io.Writer, entries []listEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	var (
+		size, packedSize uint64
+		known            = true
+	)
+	_, _ = fmt.Fprintln(tw, "SIZE\tPACKED\tRATIO\tCODEC\tMODE\tMODIFIED\tPATH")
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(
+			tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			formatSize(e.Size), e.PackedSize, formatRatio(e.Ratio), e.Codec, e.Mode,
+			e.ModTime.Local().Format("2006-01-02 15:04:05"), e.Path,
+		)
+
+		if e.Size == nil {
+			known = false
+		} else {
+			size += *e.Size
+		}
+		packedSize += e.PackedSize
+	}
+
+	total, totalRatio := "-", "-"
+	if known {
+		total, totalRatio = fmt.Sprint(size), formatRatio(ratio(size, packedSize))
+	}
+	_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t\t\t\t%d entries\n", total, packedSize, totalRatio, len(entries))
+
+	return tw.Flush()
+}
+
+// printJSON prints the entries as an indented JSON array.
+func printJSON(w io.Writer, entries []listEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func formatSize(size *uint64) string {
+	if size == nil {
+		return "-"
+	}
+	return fmt.Sprint(*size)
+}
+
+func formatRatio(ratio *float64) string {
+	if ratio == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *ratio*100)
+}
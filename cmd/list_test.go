@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// sizeOf returns the pointer to the size of the listed entry.
+func sizeOf(size uint64) *uint64 {
+	return &size
+}
+
+func TestRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		size, packedSize uint64
+		want             string
+	}{
+		{size: 0, packedSize: 0, want: "-"},
+		{size: 0, packedSize: 10, want: "-"},
+		{size: 200, packedSize: 50, want: "25.0%"},
+		{size: 3, packedSize: 2, want: "66.7%"},
+		{size: 10, packedSize: 15, want: "150.0%"},
+	}
+
+	for _, test := range tests {
+		test := test
+		name := fmt.Sprintf("ratio of %d bytes packed to %d bytes", test.size, test.packedSize)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, test.want, formatRatio(ratio(test.size, test.packedSize)),
+				"formatRatio(ratio(%d, %d))", test.size, test.packedSize)
+		})
+	}
+}
+
+// listedEntries are the entries of the tests of the output, the size of the second one is unknown.
+func listedEntries(loc *time.Location) []listEntry {
+	return []listEntry{
+		{
+			Path:       "data/a.txt",
+			Size:       sizeOf(200),
+			PackedSize: 50,
+			Ratio:      ratio(200, 50),
+			Codec:      "lzss",
+			Mode:       "-rw-r--r--",
+			ModTime:    time.Date(2021, 2, 3, 4, 5, 6, 0, loc),
+		},
+		{
+			Path:       "data/b.txt.Z",
+			PackedSize: 7,
+			Codec:      "compress",
+			Mode:       "-rw-------",
+			ModTime:    time.Date(2022, 12, 31, 23, 59, 58, 0, loc),
+		},
+	}
+}
+
+func TestPrintEntries(t *testing.T) {
+	t.Parallel()
+
+	entries := listedEntries(time.Local)
+	tests := []struct {
+		name    string
+		entries []listEntry
+		want    string
+	}{
+		{
+			name: "no entries",
+			want: "SIZE  PACKED  RATIO  CODEC  MODE  MODIFIED  PATH\n" +
+				"0     0       -                             0 entries\n",
+		},
+		{
+			name:    "entries of known size",
+			entries: entries[:1],
+			want: "SIZE  PACKED  RATIO  CODEC  MODE        MODIFIED             PATH\n" +
+				"200   50      25.0%  lzss   -rw-r--r--  2021-02-03 04:05:06  data/a.txt\n" +
+				"200   50      25.0%                                          1 entries\n",
+		},
+		{
+			name:    "entries of unknown size",
+			entries: entries,
+			want: "SIZE  PACKED  RATIO  CODEC     MODE        MODIFIED             PATH\n" +
+				"200   50      25.0%  lzss      -rw-r--r--  2021-02-03 04:05:06  data/a.txt\n" +
+				"-     7       -      compress  -rw-------  2022-12-31 23:59:58  data/b.txt.Z\n" +
+				"-     57      -                                                 2 entries\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("print %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			assert.Nil(t, printEntries(&buf, test.entries))
+			assert.Equal(t, test.want, buf.String())
+		})
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	assert.Nil(t, printJSON(&buf, listedEntries(time.UTC)))
+	assert.JSONEq(t, `[
+		{
+			"path": "data/a.txt",
+			"size": 200,
+			"packed_size": 50,
+			"ratio": 0.25,
+			"codec": "lzss",
+			"mode": "-rw-r--r--",
+			"mtime": "2021-02-03T04:05:06Z"
+		},
+		{
+			"path": "data/b.txt.Z",
+			"size": null,
+			"packed_size": 7,
+			"ratio": null,
+			"codec": "compress",
+			"mode": "-rw-------",
+			"mtime": "2022-12-31T23:59:58Z"
+		}
+	]`, buf.String())
+}
+
+func TestListArchive(t *testing.T) {
+	t.Parallel()
+
+	entries, err := listArchive(writeArchive(t))
+	assert.Nil(t, err)
+
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+		assert.NotNilf(t, e.Size, "unknown size of %s", e.Path)
+		if e.Mode[0] == 'd' {
+			assert.Nilf(t, e.Ratio, "ratio of directory %s", e.Path)
+			continue
+		}
+		assert.Equalf(t, uint64(len(extractTree[e.Path])), *e.Size, "unexpected size of %s", e.Path)
+		assert.Equalf(t, ratio(*e.Size, e.PackedSize), e.Ratio, "unexpected ratio of %s", e.Path)
+	}
+	assert.Equal(t, []string{
+		"data", "data/a.txt", "data/dir", "data/dir/a.txt", "data/dir/c.log", "data/dir/sub", "data/dir/sub/b.txt",
+	}, paths)
+}
+
+func TestListPackedFile(t *testing.T) {
+	t.Parallel()
+
+	f, ok := compression.Lookup("lzss")
+	assert.True(t, ok)
+	data := bytes.Repeat([]byte("packed data "), 100)
+	packed, err := compression.PackWith(f.Pack(flag.NewFlagSet("lzss", flag.ContinueOnError)), data)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name, codec string
+		data        []byte
+		size        *uint64
+		err         error
+	}{
+		{name: "packed file with size", codec: "lzss", data: packed, size: sizeOf(uint64(len(data)))},
+		// "abc" packed the way compress -b 9 packs it, its header has no size
+		{name: "packed file without size", codec: "compress", data: []byte{0x1f, 0x9d, 0x89, 0x61, 0xc4, 0x8c, 0x01}},
+		{name: "unknown file", data: []byte("plain text"), err: archive.ErrFormat},
+		{name: "empty file", data: []byte{}, err: archive.ErrFormat},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("list %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), "file")
+			assert.Nil(t, os.WriteFile(file, test.data, 0644))
+			assert.Nil(t, os.Chmod(file, 0644))
+
+			entries, err := listPackedFile(file)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			if !assert.Len(t, entries, 1) {
+				return
+			}
+			assert.Equal(t, []listEntry{{
+				Path:       file,
+				Size:       test.size,
+				PackedSize: uint64(len(test.data)),
+				Ratio:      entries[0].Ratio,
+				Codec:      test.codec,
+				Mode:       "-rw-r--r--",
+				ModTime:    entries[0].ModTime,
+			}}, entries)
+			if test.size != nil {
+				assert.Equal(t, ratio(*test.size, uint64(len(test.data))), entries[0].Ratio)
+			} else {
+				assert.Nil(t, entries[0].Ratio)
+			}
+		})
+	}
+}
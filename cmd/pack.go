@@ -7,6 +7,11 @@ var packCmd = &cobra.Command{
 	Short: "Pack file ",
 }
 
+// packRLE makes the pack commands run-length encode the data before packing it.
+var packRLE bool
+
 func init() {
+	packCmd.PersistentFlags().BoolVar(&packRLE, "rle", false, "run-length encode the data before packing it")
+
 	rootCmd.AddCommand(packCmd)
 }
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/archive"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test <path to archive or packed file>",
+	Short: "Test integrity of archive by unpacking all its entries",
+	Long: `Test integrity of archive by unpacking all its entries.
+
+The unpacked data is discarded, its size and checksum are compared with the ones recorded when it was packed.
+Every corrupted entry is reported, the command fails if any entry is corrupted. A single packed file
+is unpacked with the codec detected by its header.`,
+	RunE: test,
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+}
+
+func test(_ *cobra.Command, args []string) error {
+	if len(args) == 0 || args[0] == "" {
+		return ErrEmptyArchivePath
+	}
+
+	corrupted, total, err := testArchive(args[0])
+	if errors.Is(err, archive.ErrFormat) {
+		corrupted, total, err = testPackedFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	return reportTest(os.Stdout, corrupted, total)
+}
+
+// reportTest prints that all the tested entries are OK to w, it fails if any entry is corrupted.
+func reportTest(w io.Writer, corrupted, total int) error {
+	if corrupted > 0 {
+		return fmt.Errorf("%d of %d entries are corrupted", corrupted, total)
+	}
+	_, err := fmt.Fprintf(w, "OK: %d entries\n", total)
+	return err
+}
+
+// testArchive unpacks every file of the archive, reporting the corrupted ones.
+// It returns the number of the corrupted and of all the tested entries.
+func testArchive(file string) (corrupted, total int, err error) {
+	f, z, err := openArchive(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	for i := range z.Entries {
+		e := &z.Entries[i]
+		if e.IsDir() {
+			continue
+		}
+
+		total++
+		if err := testEntry(z, e); err != nil {
+			corrupted++
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", e.Path, err)
+		}
+	}
+
+	return corrupted, total, nil
+}
+
+// testEntry unpacks the file of the entry, discarding the data.
+func testEntry(z *archive.Reader, e *archive.Entry) error {
+	r, err := z.Open(e)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(io.Discard, r)
+	return err
+}
+
+// testPackedFile unpacks the packed file with the detected codec, it fails with compression.ErrUnrecognized
+// if the codec is not detected.
+func testPackedFile(file string) (corrupted, total int, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	r := compression.NewDetectingReader(f)
+	defer r.Close()
+
+	_, err = io.Copy(io.Discard, r)
+	if errors.Is(err, compression.ErrUnrecognized) {
+		return 0, 0, err
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return 1, 1, nil
+	}
+	return 0, 1, nil
+}
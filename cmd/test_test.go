@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// corruptArchive flips the last packed byte of the files of the archive with the given paths.
+func corruptArchive(t *testing.T, file string, paths ...string) {
+	t.Helper()
+
+	f, z, err := openArchive(file)
+	assert.Nil(t, err)
+	entries := z.Entries
+	assert.Nil(t, f.Close())
+
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	for _, e := range entries {
+		for _, p := range paths {
+			if e.Path == p {
+				data[e.Offset+e.PackedSize-1] ^= 0xff
+			}
+		}
+	}
+	assert.Nil(t, os.WriteFile(file, data, 0644))
+}
+
+func TestTestArchive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		corrupted  []string
+		want       int
+		wantReport string
+	}{
+		{name: "intact archive"},
+		{
+			name:       "archive with corrupted entries",
+			corrupted:  []string{"data/a.txt", "data/dir/sub/b.txt"},
+			want:       2,
+			wantReport: "2 of 4 entries are corrupted",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("testing %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := writeArchive(t)
+			corruptArchive(t, file, test.corrupted...)
+
+			corrupted, total, err := testArchive(file)
+			assert.Nil(t, err)
+			assert.Equalf(t, test.want, corrupted, "corrupted entries of %q", test.corrupted)
+			assert.Equal(t, len(extractTree), total, "the directories are not tested")
+
+			if test.wantReport != "" {
+				assert.EqualError(t, testCmd.RunE(testCmd, []string{file}), test.wantReport)
+			}
+		})
+	}
+}
+
+func TestTestPackedFile(t *testing.T) {
+	t.Parallel()
+
+	data := bytes.Repeat([]byte("packed data "), 100)
+	packed, err := compression.PackWith(lookupWriter(t, "lzss"), data)
+	assert.Nil(t, err)
+	corruptedPacked := append([]byte{}, packed...)
+	corruptedPacked[len(corruptedPacked)-1] ^= 0xff
+
+	tests := []struct {
+		name       string
+		data       []byte
+		want       int
+		wantReport string
+		err        error
+	}{
+		{name: "intact packed file", data: packed},
+		{name: "corrupted packed file", data: corruptedPacked, want: 1, wantReport: "1 of 1 entries are corrupted"},
+		{
+			name:       "unknown file",
+			data:       []byte("plain text"),
+			err:        compression.ErrUnrecognized,
+			wantReport: "compression: unrecognized format",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("testing %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), "file")
+			assert.Nil(t, os.WriteFile(file, test.data, 0644))
+
+			corrupted, total, err := testPackedFile(file)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.want, corrupted)
+				assert.Equal(t, 1, total)
+			}
+
+			// the archive is not recognized, so the packed file is tested
+			if test.wantReport != "" {
+				assert.EqualError(t, testCmd.RunE(testCmd, []string{file}), test.wantReport)
+			}
+		})
+	}
+}
+
+func TestReportTest(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	assert.Nil(t, reportTest(&buf, 0, 1))
+	assert.Nil(t, reportTest(&buf, 0, 4))
+	assert.Equal(t, "OK: 1 entries\nOK: 4 entries\n", buf.String())
+
+	buf.Reset()
+	assert.EqualError(t, reportTest(&buf, 1, 4), "1 of 4 entries are corrupted")
+	assert.Empty(t, buf.String())
+}
@@ -1,12 +1,29 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/spf13/cobra"
+	"io"
+)
 
 var unpackCmd = &cobra.Command{
-	Use:   "unpack",
-	Short: "Unpack file ",
+	Use:   "unpack <path to source file> [path to unpacked file]",
+	Short: "Unpack file with the codec detected by its header, or with the codec given as the subcommand",
+	RunE:  unpackDetected,
 }
 
+// unpackRLE makes the unpack commands read the data packed with the --rle flag of the pack commands,
+// run-length decoding the data unpacked with the codec of the command. The detecting command does not need it.
+var unpackRLE bool
+
 func init() {
+	unpackCmd.PersistentFlags().BoolVar(&unpackRLE, "rle", false, "run-length decode the data after unpacking it, for the data packed with --rle")
+
 	rootCmd.AddCommand(unpackCmd)
 }
+
+func unpackDetected(_ *cobra.Command, args []string) error {
+	return unpackFile(args, func(src io.Reader) io.ReadCloser {
+		return compression.NewDetectingReader(src)
+	})
+}
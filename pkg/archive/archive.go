@@ -0,0 +1,213 @@
+// Package archive implements reading and writing of archives holding many files packed with the codecs
+// registered in the compression package.
+//
+// An archive starts with the magic and the version. The packed data of the entries follows, each packed
+// with its own codec. The index of the entries is written after them, and the archive ends with the footer:
+// the offset of the index (8 bytes, big endian) and the magic again. So the entries can be listed and
+// unpacked one by one without reading the rest of the archive.
+package archive
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// Magic is the signature every archive starts and ends with.
+const Magic = "ARCA"
+
+// Version is the version of the archive format written by this package.
+const Version = 1
+
+const (
+	headerSize = len(Magic) + 1
+	footerSize = 8 + len(Magic)
+)
+
+var (
+	ErrFormat    = errors.New("archive: not an archive")
+	ErrCorrupted = errors.New("archive: archive is corrupted")
+)
+
+// Entry describes a file or a directory in the archive.
+type Entry struct {
+	// Path is the slash-separated path of the entry, relative to the root of the archive.
+	Path    string
+	Mode    fs.FileMode
+	ModTime time.Time
+	// Codec is the name of the codec the data of the entry is packed with, it is empty for directories.
+	Codec string
+	// Size is the size of the original data.
+	Size uint64
+	// CRC32 is the checksum of the original data, see compression.UpdateChecksum.
+	CRC32 uint32
+	// PackedSize is the size of the packed data.
+	PackedSize uint64
+	// Offset is the offset of the packed data from the start of the archive.
+	Offset uint64
+}
+
+// IsDir reports whether the entry is a directory, it has no data.
+func (e *Entry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// appendIndex appends the index of the entries to dst and returns the extended slice.
+//
+// Layout: the number of entries, then for every entry: the path, the mode, the modification time
+// in nanoseconds since the Unix epoch, the codec, the size, the checksum, the packed size and the offset.
+// Numbers are uvarints, except the signed modification time, which is a varint, and the checksum, which takes
+// 4 bytes, big endian. Strings are prefixed with their length.
+func appendIndex(dst []byte, entries []Entry) []byte {
+	dst = appendUvarint(dst, uint64(len(entries)))
+
+	for _, e := range entries {
+		dst = appendString(dst, e.Path)
+		dst = appendUvarint(dst, uint64(e.Mode))
+		dst = appendVarint(dst, e.ModTime.UnixNano())
+		dst = appendString(dst, e.Codec)
+		dst = appendUvarint(dst, e.Size)
+		dst = appendUint32(dst, e.CRC32)
+		dst = appendUvarint(dst, e.PackedSize)
+		dst = appendUvarint(dst, e.Offset)
+	}
+
+	return dst
+}
+
+// parseIndex parses the index written by appendIndex, the packed data of the entries must lie
+// before indexOffset.
+func parseIndex(index []byte, indexOffset uint64) ([]Entry, error) {
+	p := parser{data: index}
+
+	n := p.uvarint()
+	// every entry takes at least 12 bytes
+	if n > uint64(len(index))/12 {
+		return nil, fmt.Errorf("%w: index of %d entries is too long", ErrCorrupted, n)
+	}
+
+	entries := make([]Entry, 0, n)
+	for i := uint64(0); i < n && p.err == nil; i++ {
+		e := Entry{
+			Path:       p.string(),
+			Mode:       fs.FileMode(p.uvarint()),
+			ModTime:    time.Unix(0, p.varint()),
+			Codec:      p.string(),
+			Size:       p.uvarint(),
+			CRC32:      p.uint32(),
+			PackedSize: p.uvarint(),
+			Offset:     p.uvarint(),
+		}
+		if p.err != nil {
+			break
+		}
+
+		if !fs.ValidPath(e.Path) || e.Path == "." {
+			return nil, fmt.Errorf("%w: invalid path %q", ErrCorrupted, e.Path)
+		}
+		if e.Offset < uint64(headerSize) || e.Offset > indexOffset || e.PackedSize > indexOffset-e.Offset {
+			return nil, fmt.Errorf("%w: packed data of %q is out of the archive", ErrCorrupted, e.Path)
+		}
+		entries = append(entries, e)
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("%w: invalid index: %v", ErrCorrupted, p.err)
+	}
+	if len(p.data) != 0 {
+		return nil, fmt.Errorf("%w: %d bytes after the index", ErrCorrupted, len(p.data))
+	}
+
+	return entries, nil
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(dst, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(dst []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(dst, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(dst, buf[:]...)
+}
+
+func appendString(dst []byte, s string) []byte {
+	return append(appendUvarint(dst, uint64(len(s))), s...)
+}
+
+// parser parses the values of the index, the first error stops parsing.
+type parser struct {
+	data []byte
+	err  error
+}
+
+var errTruncated = errors.New("index is truncated")
+
+func (p *parser) uvarint() uint64 {
+	if p.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(p.data)
+	if n <= 0 {
+		p.err = errTruncated
+		return 0
+	}
+	p.data = p.data[n:]
+
+	return v
+}
+
+func (p *parser) varint() int64 {
+	if p.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(p.data)
+	if n <= 0 {
+		p.err = errTruncated
+		return 0
+	}
+	p.data = p.data[n:]
+
+	return v
+}
+
+func (p *parser) uint32() uint32 {
+	if p.err != nil {
+		return 0
+	}
+	if len(p.data) < 4 {
+		p.err = errTruncated
+		return 0
+	}
+
+	v := binary.BigEndian.Uint32(p.data)
+	p.data = p.data[4:]
+
+	return v
+}
+
+func (p *parser) string() string {
+	n := p.uvarint()
+	if p.err != nil {
+		return ""
+	}
+	if n > uint64(len(p.data)) {
+		p.err = errTruncated
+		return ""
+	}
+
+	s := string(p.data[:n])
+	p.data = p.data[n:]
+
+	return s
+}
@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	_ "github.com/psssix/archiver/pkg/compression/lzss"
+	_ "github.com/psssix/archiver/pkg/compression/rle"
+	_ "github.com/psssix/archiver/pkg/compression/std"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+// file is the entry of a file with its data.
+type file struct {
+	Entry
+	data string
+}
+
+// modTime is the modification time of the test entries.
+var modTime = time.Date(2022, 5, 1, 12, 30, 0, 0, time.UTC)
+
+// packed returns the archive of the files.
+func packed(files ...file) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, f := range files {
+		fw, err := w.Create(f.Entry)
+		if err != nil {
+			panic(err)
+		}
+		_, _ = fw.Write([]byte(f.data))
+	}
+	_ = w.Close()
+
+	return buf.Bytes()
+}
+
+// footer returns the footer of the archive with the index at the offset.
+func footer(indexOffset uint64) []byte {
+	buf := make([]byte, 8, footerSize)
+	binary.BigEndian.PutUint64(buf, indexOffset)
+	return append(buf, Magic...)
+}
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		entries []Entry
+	}{
+		{name: "no entries", entries: []Entry{}},
+		{
+			name: "entries",
+			entries: []Entry{
+				{Path: "dir", Mode: fs.ModeDir | 0755, ModTime: modTime, Offset: 5},
+				{Path: "dir/a.txt", Mode: 0644, ModTime: modTime, Codec: "lzss", Size: 1000, CRC32: 0xcbf43926, PackedSize: 300, Offset: 5},
+				{Path: "b.txt", Mode: 0600, ModTime: time.Unix(0, -1), Codec: "rle", Size: 3, PackedSize: 20, Offset: 305},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("index of %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entries, err := parseIndex(appendIndex(nil, test.entries), 1000)
+			assert.Nil(t, err)
+			assert.Equal(t, len(test.entries), len(entries))
+			for i := range entries {
+				assert.True(t, test.entries[i].ModTime.Equal(entries[i].ModTime))
+				entries[i].ModTime = test.entries[i].ModTime
+			}
+			assert.Equal(t, test.entries, entries)
+		})
+	}
+}
+
+func TestParseIndexError(t *testing.T) {
+	t.Parallel()
+
+	entry := Entry{Path: "a.txt", Mode: 0644, Codec: "lzss", Size: 10, PackedSize: 20, Offset: 5}
+	index := appendIndex(nil, []Entry{entry})
+
+	withPath := func(path string) []byte {
+		e := entry
+		e.Path = path
+		return appendIndex(nil, []Entry{e})
+	}
+
+	tests := []struct {
+		name        string
+		index       []byte
+		indexOffset uint64
+		error       string
+	}{
+		{name: "empty index", index: []byte{}, indexOffset: 100, error: "archive: archive is corrupted: invalid index: index is truncated"},
+		{name: "truncated index", index: index[:len(index)-1], indexOffset: 100, error: "archive: archive is corrupted: invalid index: index is truncated"},
+		{name: "too many entries", index: []byte{100, 1, 2, 3}, indexOffset: 100, error: "archive: archive is corrupted: index of 100 entries is too long"},
+		{name: "extra bytes", index: append(index, 0), indexOffset: 100, error: "archive: archive is corrupted: 1 bytes after the index"},
+		{name: "absolute path", index: withPath("/etc/passwd"), indexOffset: 100, error: `archive: archive is corrupted: invalid path "/etc/passwd"`},
+		{name: "path out of the root", index: withPath("../a.txt"), indexOffset: 100, error: `archive: archive is corrupted: invalid path "../a.txt"`},
+		{name: "root path", index: withPath("."), indexOffset: 100, error: `archive: archive is corrupted: invalid path "."`},
+		{name: "data after the index", index: index, indexOffset: 24, error: `archive: archive is corrupted: packed data of "a.txt" is out of the archive`},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("parsing %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseIndex(test.index, test.indexOffset)
+			assert.EqualError(t, err, test.error)
+		})
+	}
+}
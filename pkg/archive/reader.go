@@ -0,0 +1,123 @@
+package archive
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// Reader reads the entries of an archive.
+type Reader struct {
+	// Entries are the entries of the archive in the order they were written.
+	Entries []Entry
+	r       io.ReaderAt
+}
+
+// NewReader returns a new Reader reading the archive of the given size from r.
+//
+// It reads the header, the footer and the index, the packed data of the entries is read by Open.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(headerSize+footerSize) {
+		return nil, ErrFormat
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[:len(Magic)]) != Magic {
+		return nil, ErrFormat
+	}
+	if v := header[len(Magic)]; v == 0 || v > Version {
+		return nil, fmt.Errorf("archive: unsupported format version %d, latest supported is %d", v, Version)
+	}
+
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(footer, size-int64(footerSize)); err != nil {
+		return nil, err
+	}
+	if string(footer[8:]) != Magic {
+		return nil, fmt.Errorf("%w: invalid footer, the archive may be truncated", ErrCorrupted)
+	}
+
+	indexOffset := binary.BigEndian.Uint64(footer)
+	indexEnd := uint64(size) - uint64(footerSize)
+	if indexOffset < uint64(headerSize) || indexOffset > indexEnd {
+		return nil, fmt.Errorf("%w: index offset %d is out of the archive", ErrCorrupted, indexOffset)
+	}
+
+	index := make([]byte, indexEnd-indexOffset)
+	if _, err := r.ReadAt(index, int64(indexOffset)); err != nil {
+		return nil, err
+	}
+	entries, err := parseIndex(index, indexOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{Entries: entries, r: r}, nil
+}
+
+// Open returns the reader unpacking the data of the entry, only the packed data of the entry is read.
+//
+// The reader fails with ErrCorrupted when the size or the checksum of the unpacked data differs from the entry.
+// It is the caller's responsibility to call Close on the reader when done.
+func (z *Reader) Open(e *Entry) (io.ReadCloser, error) {
+	if e.IsDir() {
+		return nil, fmt.Errorf("archive: %q is a directory", e.Path)
+	}
+
+	f, ok := compression.Lookup(e.Codec)
+	if !ok {
+		return nil, fmt.Errorf("archive: %q is packed with unknown codec %q", e.Path, e.Codec)
+	}
+	packed := io.NewSectionReader(z.r, int64(e.Offset), int64(e.PackedSize))
+
+	return &entryReader{
+		rc:       f.Unpack(flag.NewFlagSet(e.Codec, flag.ContinueOnError))(packed),
+		path:     e.Path,
+		size:     e.Size,
+		checksum: e.CRC32,
+	}, nil
+}
+
+// entryReader checks the size and the checksum of the unpacked data of the entry.
+type entryReader struct {
+	rc   io.ReadCloser
+	path string
+	size uint64
+	// checksum is the checksum of the entry, crc is the checksum of the data read so far
+	checksum uint32
+	read     uint64
+	crc      uint32
+}
+
+func (r *entryReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.read += uint64(n)
+	r.crc = compression.UpdateChecksum(r.crc, p[:n])
+
+	if r.read > r.size || err == io.EOF && r.read != r.size {
+		return n, fmt.Errorf("%w: %q unpacked to %d bytes, but its size is %d", ErrCorrupted, r.path, r.read, r.size)
+	}
+	if err == io.EOF && r.crc != r.checksum {
+		return n, fmt.Errorf(
+			"%w: %q unpacked with checksum %08x, but its checksum is %08x", ErrCorrupted, r.path, r.crc, r.checksum,
+		)
+	}
+	if err == io.ErrUnexpectedEOF {
+		// the packed data of the entry is cut by the data of the next one or the index
+		return n, fmt.Errorf("%w: packed data of %q is truncated", ErrCorrupted, r.path)
+	}
+	if err != nil && err != io.EOF {
+		return n, fmt.Errorf("archive: %q: %w", r.path, err)
+	}
+
+	return n, err
+}
+
+func (r *entryReader) Close() error {
+	return r.rc.Close()
+}
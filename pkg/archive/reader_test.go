@@ -0,0 +1,153 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	files := []file{
+		{Entry: Entry{Path: "dir", Mode: fs.ModeDir | 0755, ModTime: modTime}},
+		{Entry: Entry{Path: "dir/a.txt", Mode: 0644, ModTime: modTime, Codec: "lzss"}, data: "abcabcabc"},
+		{Entry: Entry{Path: "dir/empty.txt", Mode: 0644, ModTime: modTime, Codec: "lzss"}},
+		{Entry: Entry{Path: "b.bin", Mode: 0600, ModTime: modTime, Codec: "rle"}, data: strings.Repeat("b", 1000)},
+	}
+	data := packed(files...)
+
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, len(files), len(r.Entries))
+
+	for i, f := range files {
+		e := &r.Entries[i]
+		assert.Equal(t, f.Path, e.Path)
+		assert.Equal(t, f.Mode, e.Mode)
+		assert.True(t, f.ModTime.Equal(e.ModTime))
+		assert.Equal(t, uint64(len(f.data)), e.Size)
+		assert.Equal(t, crc32.ChecksumIEEE([]byte(f.data)), e.CRC32)
+		if e.IsDir() {
+			continue
+		}
+
+		assert.Equal(t, f.Codec, e.Codec)
+		rc, err := r.Open(e)
+		assert.Nil(t, err)
+		unpacked, err := io.ReadAll(rc)
+		assert.Nil(t, err)
+		assert.Equal(t, f.data, string(unpacked))
+		assert.Nil(t, rc.Close())
+	}
+}
+
+func TestReaderOpenReadsEntryOnly(t *testing.T) {
+	t.Parallel()
+
+	data := packed(
+		file{Entry: Entry{Path: "a.txt", Codec: "lzss"}, data: "first"},
+		file{Entry: Entry{Path: "b.txt", Codec: "lzss"}, data: "second"},
+	)
+	r, _ := NewReader(bytes.NewReader(data), int64(len(data)))
+
+	// the packed data of the first entry is damaged, the second one is still readable
+	copy(data[r.Entries[0].Offset:], "damaged")
+
+	rc, err := r.Open(&r.Entries[1])
+	assert.Nil(t, err)
+	unpacked, err := io.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("second"), unpacked)
+}
+
+func TestNewReaderError(t *testing.T) {
+	t.Parallel()
+
+	valid := packed(file{Entry: Entry{Path: "a.txt", Codec: "lzss"}, data: "abc"})
+	later := append([]byte{}, valid...)
+	later[4] = Version + 1
+	badOffset := append(valid[:len(valid)-footerSize:len(valid)-footerSize], footer(uint64(len(valid)))...)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		error string
+	}{
+		{name: "empty data", data: []byte{}, error: "archive: not an archive"},
+		{name: "single packed file", data: valid[headerSize:], error: "archive: not an archive"},
+		{name: "later version", data: later, error: "archive: unsupported format version 2, latest supported is 1"},
+		{name: "truncated archive", data: valid[:len(valid)-1], error: "archive: archive is corrupted: invalid footer, the archive may be truncated"},
+		{
+			name:  "index offset out of the archive",
+			data:  badOffset,
+			error: fmt.Sprintf("archive: archive is corrupted: index offset %d is out of the archive", len(valid)),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("reading %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewReader(bytes.NewReader(test.data), int64(len(test.data)))
+			assert.EqualError(t, err, test.error)
+		})
+	}
+}
+
+func TestReaderOpenError(t *testing.T) {
+	t.Parallel()
+
+	data := packed(
+		file{Entry: Entry{Path: "dir", Mode: fs.ModeDir}},
+		file{Entry: Entry{Path: "a.txt", Codec: "lzss"}, data: "abc"},
+	)
+	r, _ := NewReader(bytes.NewReader(data), int64(len(data)))
+
+	_, err := r.Open(&r.Entries[0])
+	assert.EqualError(t, err, `archive: "dir" is a directory`)
+
+	unknown := r.Entries[1]
+	unknown.Codec = "unknown"
+	_, err = r.Open(&unknown)
+	assert.EqualError(t, err, `archive: "a.txt" is packed with unknown codec "unknown"`)
+}
+
+func TestReaderOpenCorrupted(t *testing.T) {
+	t.Parallel()
+
+	data := packed(file{Entry: Entry{Path: "a.txt", Codec: "lzss"}, data: "abcabcabc"})
+	r, _ := NewReader(bytes.NewReader(data), int64(len(data)))
+
+	tests := []struct {
+		name    string
+		entry   func(e Entry) Entry
+		wantErr error
+	}{
+		{name: "larger size", entry: func(e Entry) Entry { e.Size++; return e }, wantErr: ErrCorrupted},
+		{name: "smaller size", entry: func(e Entry) Entry { e.Size--; return e }, wantErr: ErrCorrupted},
+		{name: "changed checksum", entry: func(e Entry) Entry { e.CRC32 ^= 1; return e }, wantErr: ErrCorrupted},
+		{name: "truncated data", entry: func(e Entry) Entry { e.PackedSize--; return e }, wantErr: ErrCorrupted},
+		{name: "damaged data", entry: func(e Entry) Entry { e.Offset++; e.PackedSize--; return e }, wantErr: compression.ErrHeader},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("reading entry with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			e := test.entry(r.Entries[0])
+			rc, err := r.Open(&e)
+			assert.Nil(t, err)
+			_, err = io.ReadAll(rc)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package archive
+
+import (
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+	"io/fs"
+)
+
+var ErrClosed = errors.New("archive: archive is closed")
+
+// Writer writes an archive to the underlying writer.
+//
+// Entries are added with Create, the data of an entry is written to the writer returned by Create before
+// the next call to Create or Close. Close writes the index, the archive is not complete until then.
+type Writer struct {
+	w           *countingWriter
+	wroteHeader bool
+	entries     []Entry
+	// current is the writer of the data of the last entry, it is nil for directories.
+	current *entryWriter
+	err     error
+}
+
+// NewWriter returns a new Writer writing an archive to w.
+//
+// It is the caller's responsibility to call Close on the Writer when done.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: &countingWriter{w: w}}
+}
+
+// Create adds the entry to the archive and returns the writer packing its data with the codec of the entry
+// with the default options. The Size, CRC32, PackedSize and Offset of the entry are set by the Writer.
+//
+// The data of a directory is empty, its writer fails to write anything.
+func (z *Writer) Create(e Entry) (io.Writer, error) {
+	if z.err != nil {
+		return nil, z.err
+	}
+	if !fs.ValidPath(e.Path) || e.Path == "." {
+		return nil, fmt.Errorf("archive: invalid path %q", e.Path)
+	}
+	if z.err = z.closeEntry(); z.err != nil {
+		return nil, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return nil, z.err
+	}
+
+	e.Size, e.CRC32, e.PackedSize, e.Offset = 0, 0, 0, z.w.n
+	if e.IsDir() {
+		e.Codec = ""
+		z.entries = append(z.entries, e)
+		return dirWriter{path: e.Path}, nil
+	}
+
+	if err := ValidateCodec(e.Codec); err != nil {
+		return nil, err
+	}
+	f, _ := compression.Lookup(e.Codec)
+	w, err := f.Pack(flag.NewFlagSet(e.Codec, flag.ContinueOnError))(z.w, compression.Header{})
+	if err != nil {
+		return nil, err
+	}
+
+	z.entries = append(z.entries, e)
+	z.current = &entryWriter{w: w}
+
+	return z.current, nil
+}
+
+// ValidateCodec returns an error if the files of the entries can't be packed with the codec: it is not registered
+// or it only unpacks.
+func ValidateCodec(name string) error {
+	if f, ok := compression.Lookup(name); !ok || f.Pack == nil {
+		return fmt.Errorf("archive: unknown codec %q", name)
+	}
+	return nil
+}
+
+// Close finishes the data of the last entry and writes the index and the footer.
+// It does not close the underlying writer.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.closeEntry(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	index := appendIndex(nil, z.entries)
+	footer := make([]byte, footerSize)
+	binary.BigEndian.PutUint64(footer, z.w.n)
+	copy(footer[8:], Magic)
+	if _, z.err = z.w.Write(append(index, footer...)); z.err != nil {
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+	z.wroteHeader = true
+
+	_, err := z.w.Write(append([]byte(Magic), Version))
+	return err
+}
+
+// closeEntry closes the writer of the last entry and records the sizes of its data.
+func (z *Writer) closeEntry() error {
+	if z.current == nil {
+		return nil
+	}
+
+	current := z.current
+	z.current = nil
+	if err := current.close(); err != nil {
+		return err
+	}
+
+	e := &z.entries[len(z.entries)-1]
+	e.Size = current.n
+	e.CRC32 = current.crc
+	e.PackedSize = z.w.n - e.Offset
+
+	return nil
+}
+
+// entryWriter counts and checksums the data of the entry written to the codec writer,
+// it can't be used after being closed.
+type entryWriter struct {
+	w      io.WriteCloser
+	n      uint64
+	crc    uint32
+	closed bool
+}
+
+func (w *entryWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	n, err := w.w.Write(p)
+	w.n += uint64(n)
+	w.crc = compression.UpdateChecksum(w.crc, p[:n])
+	return n, err
+}
+
+func (w *entryWriter) close() error {
+	w.closed = true
+	return w.w.Close()
+}
+
+type dirWriter struct {
+	path string
+}
+
+func (w dirWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("archive: write to directory %q", w.path)
+}
+
+// countingWriter counts the bytes written to the underlying writer, so the offsets are known without seeking.
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += uint64(n)
+	return n, err
+}
@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"bytes"
+	"github.com/psssix/archiver/pkg/compression/lzss"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io/fs"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	data := packed(
+		file{Entry: Entry{Path: "dir", Mode: fs.ModeDir | 0755, ModTime: modTime}},
+		file{Entry: Entry{Path: "dir/a.txt", Mode: 0644, ModTime: modTime, Codec: "lzss"}, data: "abcabcabc"},
+	)
+
+	// the entries are packed with the empty header, the index keeps the size and the checksum
+	var lzssPacked bytes.Buffer
+	lw := lzss.NewWriter(&lzssPacked)
+	_, _ = lw.Write([]byte("abcabcabc"))
+	_ = lw.Close()
+
+	want := append([]byte(Magic), Version)
+	want = append(want, lzssPacked.Bytes()...)
+	indexOffset := uint64(len(want))
+	want = appendIndex(want, []Entry{
+		{Path: "dir", Mode: fs.ModeDir | 0755, ModTime: modTime, Offset: 5},
+		{
+			Path:       "dir/a.txt",
+			Mode:       0644,
+			ModTime:    modTime,
+			Codec:      "lzss",
+			Size:       9,
+			CRC32:      crc32.ChecksumIEEE([]byte("abcabcabc")),
+			PackedSize: uint64(lzssPacked.Len()),
+			Offset:     5,
+		},
+	})
+	want = append(want, footer(indexOffset)...)
+
+	assert.Equal(t, want, data)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []byte("ARCA\x01\x00\x00\x00\x00\x00\x00\x00\x00\x05ARCA"), packed())
+}
+
+func TestWriterCreateError(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+
+	_, err := w.Create(Entry{Path: "../a.txt", Codec: "lzss"})
+	assert.EqualError(t, err, `archive: invalid path "../a.txt"`)
+
+	_, err = w.Create(Entry{Path: "a.txt", Codec: "unknown"})
+	assert.EqualError(t, err, `archive: unknown codec "unknown"`)
+
+	_, err = w.Create(Entry{Path: "a.txt", Codec: "bzip2"})
+	assert.EqualError(t, err, `archive: unknown codec "bzip2"`, "codecs that only unpack can't pack entries")
+}
+
+func TestValidateCodec(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, ValidateCodec("lzss"))
+	assert.EqualError(t, ValidateCodec("unknown"), `archive: unknown codec "unknown"`)
+	assert.EqualError(t, ValidateCodec("bzip2"), `archive: unknown codec "bzip2"`, "codecs that only unpack can't pack entries")
+}
+
+func TestWriterDirectory(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	dw, err := w.Create(Entry{Path: "dir", Mode: fs.ModeDir})
+	assert.Nil(t, err)
+
+	_, err = dw.Write([]byte("abc"))
+	assert.EqualError(t, err, `archive: write to directory "dir"`)
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	fw, _ := w.Create(Entry{Path: "a.txt", Codec: "lzss"})
+	_, _ = w.Create(Entry{Path: "b.txt", Codec: "lzss"})
+
+	_, err := fw.Write([]byte("abc"))
+	assert.ErrorIs(t, err, ErrClosed, "the writer of an entry must be closed by the next Create")
+
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+	_, err = w.Create(Entry{Path: "c.txt", Codec: "lzss"})
+	assert.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,173 @@
+package bitio
+
+import (
+	"bufio"
+	"io"
+)
+
+// BitReader reads bits from the underlying reader, the most significant bit of a byte first.
+type BitReader struct {
+	r     io.ByteReader
+	acc   uint64
+	nbits uint
+	count uint64
+}
+
+// NewBitReader returns a new BitReader reading from r.
+//
+// If r does not also implement io.ByteReader, the BitReader may read more data than necessary from r.
+func NewBitReader(r io.Reader) *BitReader {
+	return &BitReader{r: NewByteReader(r)}
+}
+
+// ReadBits reads n bits and returns them as the lowest bits of the result, the first read bit is the most
+// significant one.
+//
+// It returns io.EOF if no bits are left and io.ErrUnexpectedEOF if less than n bits are left.
+func (r *BitReader) ReadBits(n uint) (uint64, error) {
+	if n > 64 {
+		return 0, ErrTooManyBits
+	}
+
+	if n > maxChunk {
+		high, err := r.ReadBits(n - maxChunk)
+		if err != nil {
+			return 0, err
+		}
+		low, err := r.ReadBits(maxChunk)
+		if err != nil {
+			return 0, NoEOF(err)
+		}
+		return high<<maxChunk | low, nil
+	}
+
+	v, err := r.PeekBits(n)
+	if err != nil {
+		return 0, err
+	}
+	r.nbits -= n
+	r.count += uint64(n)
+
+	return v, nil
+}
+
+// ReadBit reads a single bit and returns true if it is 1.
+func (r *BitReader) ReadBit() (bool, error) {
+	if r.nbits == 0 {
+		if err := r.fill(1); err != nil {
+			return false, err
+		}
+	}
+
+	r.nbits--
+	r.count++
+
+	return r.acc>>r.nbits&1 == 1, nil
+}
+
+// PeekBits returns the next n bits, up to 56, without consuming them.
+//
+// It returns io.EOF if no bits are left and io.ErrUnexpectedEOF if less than n bits are left.
+func (r *BitReader) PeekBits(n uint) (uint64, error) {
+	if n > maxChunk {
+		return 0, ErrTooManyBits
+	}
+	if err := r.fill(n); err != nil {
+		return 0, err
+	}
+
+	return r.acc >> (r.nbits - n) & (1<<n - 1), nil
+}
+
+// Discard skips the next n bits, usually the ones already seen with PeekBits.
+func (r *BitReader) Discard(n uint) error {
+	if n > r.nbits {
+		if _, err := r.ReadBits(n); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	r.nbits -= n
+	r.count += uint64(n)
+
+	return nil
+}
+
+// Align discards the rest of the current byte, so the next bit is read from a new byte.
+func (r *BitReader) Align() {
+	r.count += uint64(r.nbits % 8)
+	r.nbits -= r.nbits % 8
+}
+
+// ReadByte reads the next 8 bits, it makes BitReader an io.ByteReader.
+func (r *BitReader) ReadByte() (byte, error) {
+	b, err := r.ReadBits(8)
+	return byte(b), err
+}
+
+// Read reads len(p) bytes of 8 bits each, it makes BitReader an io.Reader.
+func (r *BitReader) Read(p []byte) (int, error) {
+	for i := range p {
+		b, err := r.ReadByte()
+		if err != nil {
+			return i, err
+		}
+		p[i] = b
+	}
+
+	return len(p), nil
+}
+
+// Count returns the number of bits read so far, including the discarded ones.
+func (r *BitReader) Count() uint64 {
+	return r.count
+}
+
+// Reset discards the BitReader's state and makes it equivalent to the result of NewBitReader(src).
+func (r *BitReader) Reset(src io.Reader) {
+	r.r = NewByteReader(src)
+	r.acc = 0
+	r.nbits = 0
+	r.count = 0
+}
+
+func (r *BitReader) fill(n uint) error {
+	for r.nbits < n {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && r.nbits > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		r.acc = r.acc<<8 | uint64(b)
+		r.nbits += 8
+	}
+
+	return nil
+}
+
+// ByteReader is the reader of the packed data that reads it both by slices and byte by byte.
+type ByteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// NewByteReader returns r if it implements ByteReader, otherwise it wraps r with a bufio.Reader,
+// which may read more data than necessary from r.
+func NewByteReader(r io.Reader) ByteReader {
+	if br, ok := r.(ByteReader); ok {
+		return br
+	}
+	return bufio.NewReader(r)
+}
+
+// NoEOF converts io.EOF to io.ErrUnexpectedEOF, for the data that must not end where it is read.
+func NoEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
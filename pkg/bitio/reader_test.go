@@ -0,0 +1,199 @@
+package bitio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBitReaderReadBits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		n    []uint
+		want []uint64
+	}{
+		{name: "nothing", data: []byte{0xff}, n: []uint{0}, want: []uint64{0}},
+		{name: "whole byte", data: []byte{0b10100101}, n: []uint{8}, want: []uint64{0b10100101}},
+		{
+			name: "codes of \"Ted\"",
+			data: []byte{0b00100010, 0b01101001, 0b01000000},
+			n:    []uint{6, 4, 3, 5},
+			want: []uint64{0b001000, 0b1001, 0b101, 0b00101},
+		},
+		{
+			name: "64 bits after 3 bits",
+			data: []byte{0b10110000, 0, 0, 0, 0, 0, 0, 0, 0b00100000},
+			n:    []uint{3, 64},
+			want: []uint64{0b101, 0x8000_0000_0000_0001},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("read %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewBitReader(bytes.NewReader(test.data))
+			got := make([]uint64, 0, len(test.n))
+			for _, n := range test.n {
+				v, err := r.ReadBits(n)
+				assert.Nil(t, err)
+				got = append(got, v)
+			}
+			assert.Equalf(t, test.want, got, "BitReader.ReadBits(%v)", test.n)
+		})
+	}
+}
+
+func TestBitReaderReadBit(t *testing.T) {
+	t.Parallel()
+
+	r := NewBitReader(bytes.NewReader([]byte{0b10110010, 0b10000000}))
+	got := make([]bool, 0, 9)
+	for i := 0; i < 9; i++ {
+		bit, err := r.ReadBit()
+		assert.Nil(t, err)
+		got = append(got, bit)
+	}
+	assert.Equal(t, []bool{true, false, true, true, false, false, true, false, true}, got)
+	assert.Equal(t, uint64(9), r.Count())
+}
+
+func TestBitReaderPeekBits(t *testing.T) {
+	t.Parallel()
+
+	r := NewBitReader(bytes.NewReader([]byte{0b00100010, 0b01101001}))
+
+	v, err := r.PeekBits(6)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0b001000), v)
+	assert.Equal(t, uint64(0), r.Count(), "BitReader.PeekBits() must not consume bits")
+
+	v, _ = r.ReadBits(6)
+	assert.Equal(t, uint64(0b001000), v)
+
+	v, err = r.PeekBits(10)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0b1001101001), v)
+
+	_, err = r.PeekBits(11)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = r.PeekBits(57)
+	assert.ErrorIs(t, err, ErrTooManyBits)
+}
+
+func TestBitReaderDiscard(t *testing.T) {
+	t.Parallel()
+
+	r := NewBitReader(bytes.NewReader([]byte{0b00100010, 0b01101001, 0b01000000}))
+	_, _ = r.PeekBits(6)
+	assert.Nil(t, r.Discard(6))
+	assert.Nil(t, r.Discard(12), "BitReader.Discard() must read not peeked bits")
+	assert.Equal(t, uint64(18), r.Count())
+
+	v, err := r.ReadBits(6)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), v)
+	assert.ErrorIs(t, r.Discard(1), io.EOF)
+}
+
+func TestBitReaderAlign(t *testing.T) {
+	t.Parallel()
+
+	r := NewBitReader(bytes.NewReader([]byte{0b11000000, 0b10000000}))
+	r.Align()
+	assert.Equal(t, uint64(0), r.Count(), "BitReader.Align() must not skip aligned data")
+
+	_, _ = r.ReadBits(2)
+	r.Align()
+	assert.Equal(t, uint64(8), r.Count())
+
+	b, err := r.ReadByte()
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0b10000000), b)
+}
+
+func TestBitReaderRead(t *testing.T) {
+	t.Parallel()
+
+	r := NewBitReader(bytes.NewReader([]byte{0b10100101, 0b11110000, 0b00001111}))
+	_, _ = r.ReadBits(4)
+
+	p := make([]byte, 2)
+	n, err := r.Read(p)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []byte{0b01011111, 0b00000000}, p)
+
+	n, err = r.Read(p)
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestBitReaderEOF(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		n    uint
+		err  error
+	}{
+		{name: "empty data", data: []byte{}, n: 1, err: io.EOF},
+		{name: "not enough bits", data: []byte{0xff}, n: 9, err: io.ErrUnexpectedEOF},
+		{name: "not enough bits for 64 bits", data: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, n: 64, err: io.ErrUnexpectedEOF},
+		{name: "too many bits", data: []byte{0xff}, n: 65, err: ErrTooManyBits},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("read error on %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			v, err := NewBitReader(iotest.OneByteReader(bytes.NewReader(test.data))).ReadBits(test.n)
+			assert.Emptyf(t, v, "BitReader.ReadBits(%d) not empty result when error", test.n)
+			assert.ErrorIsf(t, err, test.err, "BitReader.ReadBits(%d) unexpected error", test.n)
+		})
+	}
+
+	_, err := NewBitReader(bytes.NewReader([]byte{})).ReadBit()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestBitReaderReset(t *testing.T) {
+	t.Parallel()
+
+	r := NewBitReader(bytes.NewReader([]byte{0xff}))
+	_, _ = r.ReadBits(3)
+
+	r.Reset(bytes.NewReader([]byte{0b01000000}))
+	assert.Equal(t, uint64(0), r.Count())
+	v, err := r.ReadBits(2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0b01), v)
+}
+
+func TestNewByteReader(t *testing.T) {
+	t.Parallel()
+
+	br := bytes.NewReader([]byte("Ted"))
+	assert.Same(t, br, NewByteReader(br))
+	assert.IsType(t, &bufio.Reader{}, NewByteReader(iotest.OneByteReader(br)))
+}
+
+func TestNoEOF(t *testing.T) {
+	t.Parallel()
+
+	assert.ErrorIs(t, NoEOF(io.EOF), io.ErrUnexpectedEOF)
+	assert.ErrorIs(t, NoEOF(ErrTooManyBits), ErrTooManyBits)
+	assert.Nil(t, NoEOF(nil))
+}
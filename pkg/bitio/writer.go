@@ -0,0 +1,116 @@
+package bitio
+
+import (
+	"errors"
+	"io"
+)
+
+// bufferSize is the number of bytes collected by BitWriter before writing them to the underlying writer.
+const bufferSize = 4096
+
+// maxChunk is the maximum number of bits added to the accumulator at once:
+// it keeps at most 7 not yet written bits, so 57 more bits always fit into 64.
+const maxChunk = 56
+
+var ErrTooManyBits = errors.New("bitio: more than 64 bits requested")
+
+// BitWriter writes bits to the underlying writer, the most significant bit of a byte first.
+type BitWriter struct {
+	w     io.Writer
+	buf   []byte
+	acc   uint64
+	nbits uint
+	count uint64
+	err   error
+}
+
+// NewBitWriter returns a new BitWriter writing to w.
+//
+// Bits are buffered, it is the caller's responsibility to call Flush when done.
+func NewBitWriter(w io.Writer) *BitWriter {
+	return &BitWriter{w: w, buf: make([]byte, 0, bufferSize)}
+}
+
+// WriteBits writes n lowest bits of v, the most significant one first.
+func (w *BitWriter) WriteBits(v uint64, n uint) error {
+	if n > 64 {
+		return ErrTooManyBits
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	w.count += uint64(n)
+
+	for n > 0 {
+		k := n
+		if k > maxChunk {
+			k = maxChunk
+		}
+		n -= k
+
+		w.acc = w.acc<<k | (v>>n)&(1<<k-1)
+		w.nbits += k
+
+		for w.nbits >= 8 {
+			w.nbits -= 8
+			w.buf = append(w.buf, byte(w.acc>>w.nbits))
+		}
+	}
+
+	if len(w.buf) >= bufferSize {
+		return w.writeBuffer()
+	}
+
+	return nil
+}
+
+// WriteBit writes a single bit: 1 if bit is true, 0 otherwise.
+func (w *BitWriter) WriteBit(bit bool) error {
+	if bit {
+		return w.WriteBits(1, 1)
+	}
+	return w.WriteBits(0, 1)
+}
+
+// Align pads the current byte with zero bits, so the next bit is written to a new byte.
+func (w *BitWriter) Align() error {
+	if w.nbits == 0 {
+		return w.err
+	}
+	return w.WriteBits(0, 8-w.nbits)
+}
+
+// Flush aligns the written bits to a byte and writes all buffered bytes to the underlying writer.
+func (w *BitWriter) Flush() error {
+	if err := w.Align(); err != nil {
+		return err
+	}
+	return w.writeBuffer()
+}
+
+// Count returns the number of bits written so far, including the padding.
+func (w *BitWriter) Count() uint64 {
+	return w.count
+}
+
+// Reset discards the BitWriter's state and makes it equivalent to the result of NewBitWriter(w).
+func (w *BitWriter) Reset(dst io.Writer) {
+	w.w = dst
+	w.buf = w.buf[:0]
+	w.acc = 0
+	w.nbits = 0
+	w.count = 0
+	w.err = nil
+}
+
+func (w *BitWriter) writeBuffer() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	_, w.err = w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+
+	return w.err
+}
@@ -0,0 +1,166 @@
+package bitio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/iotest"
+)
+
+type bits struct {
+	v uint64
+	n uint
+}
+
+func TestBitWriterWriteBits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		bits  []bits
+		want  []byte
+		count uint64
+	}{
+		{name: "nothing", bits: []bits{}, want: []byte{}, count: 0},
+		{name: "one bit", bits: []bits{{v: 1, n: 1}}, want: []byte{0b10000000}, count: 1},
+		{name: "whole byte", bits: []bits{{v: 0b10100101, n: 8}}, want: []byte{0b10100101}, count: 8},
+		{
+			name:  "codes of \"Ted\"",
+			bits:  []bits{{v: 0b001000, n: 6}, {v: 0b1001, n: 4}, {v: 0b101, n: 3}, {v: 0b00101, n: 5}},
+			want:  []byte{0b00100010, 0b01101001, 0b01000000},
+			count: 18,
+		},
+		{
+			name:  "only lowest bits",
+			bits:  []bits{{v: 0xff, n: 4}, {v: 0xf0, n: 4}},
+			want:  []byte{0b11110000},
+			count: 8,
+		},
+		{
+			name:  "64 bits after 3 bits",
+			bits:  []bits{{v: 0b101, n: 3}, {v: 0x8000_0000_0000_0001, n: 64}},
+			want:  []byte{0b10110000, 0, 0, 0, 0, 0, 0, 0, 0b00100000},
+			count: 67,
+		},
+		{name: "zero bits", bits: []bits{{v: 1, n: 0}, {v: 1, n: 1}}, want: []byte{0b10000000}, count: 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("write %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewBitWriter(buf)
+			for _, b := range test.bits {
+				assert.Nil(t, w.WriteBits(b.v, b.n))
+			}
+			assert.Equal(t, test.count, w.Count())
+			assert.Nil(t, w.Flush())
+			assert.Equalf(t, test.want, buf.Bytes(), "BitWriter.WriteBits(%v)", test.bits)
+		})
+	}
+}
+
+func TestBitWriterWriteBit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewBitWriter(&buf)
+	for _, bit := range []bool{true, false, true, true, false, false, true, false, true} {
+		assert.Nil(t, w.WriteBit(bit))
+	}
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, []byte{0b10110010, 0b10000000}, buf.Bytes())
+}
+
+func TestBitWriterAlign(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewBitWriter(&buf)
+	assert.Nil(t, w.Align())
+	assert.Equal(t, uint64(0), w.Count(), "BitWriter.Align() must not pad aligned data")
+
+	_ = w.WriteBits(0b11, 2)
+	assert.Nil(t, w.Align())
+	assert.Equal(t, uint64(8), w.Count())
+
+	_ = w.WriteBits(0b1, 1)
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, []byte{0b11000000, 0b10000000}, buf.Bytes())
+}
+
+func TestBitWriterLargeOutput(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewBitWriter(&buf)
+	for i := 0; i < 2*bufferSize+10; i++ {
+		_ = w.WriteBits(uint64(i), 8)
+	}
+	assert.Equal(t, 2*bufferSize, buf.Len(), "BitWriter must write full buffers before Flush")
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, 2*bufferSize+10, buf.Len())
+	assert.Equal(t, byte(0xff), buf.Bytes()[0xff])
+}
+
+func TestBitWriterError(t *testing.T) {
+	t.Parallel()
+
+	w := NewBitWriter(&bytes.Buffer{})
+	assert.ErrorIs(t, w.WriteBits(0, 65), ErrTooManyBits)
+
+	errWrite := errors.New("write error")
+	w = NewBitWriter(writerFunc(func(p []byte) (int, error) { return 0, errWrite }))
+	_ = w.WriteBits(1, 1)
+	assert.ErrorIs(t, w.Flush(), errWrite)
+	assert.ErrorIs(t, w.WriteBits(1, 1), errWrite, "BitWriter must keep the error")
+}
+
+func TestBitWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewBitWriter(&first)
+	_ = w.WriteBits(0b101, 3)
+
+	w.Reset(&second)
+	_ = w.WriteBits(0b11, 2)
+	assert.Nil(t, w.Flush())
+
+	assert.Empty(t, first.Bytes())
+	assert.Equal(t, []byte{0b11000000}, second.Bytes())
+	assert.Equal(t, uint64(8), w.Count())
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewBitWriter(&buf)
+	for n := uint(0); n <= 64; n++ {
+		_ = w.WriteBits(0xdead_beef_cafe_babe, n)
+	}
+	assert.Nil(t, w.Flush())
+
+	r := NewBitReader(iotest.OneByteReader(&buf))
+	for n := uint(0); n <= 64; n++ {
+		v, err := r.ReadBits(n)
+		assert.Nil(t, err)
+		want := uint64(0xdead_beef_cafe_babe)
+		if n < 64 {
+			want &= 1<<n - 1
+		}
+		assert.Equalf(t, want, v, "BitReader.ReadBits(%d)", n)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
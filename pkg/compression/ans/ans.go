@@ -0,0 +1,73 @@
+package ans
+
+import (
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+func init() {
+	compression.Register("ans", compression.Factory{
+		Description: "asymmetric numeral systems and normalized symbol frequencies",
+		Extension:   "ans",
+		Magic:       compression.HeaderMagic(compression.ANS),
+		Pack: func(fs *flag.FlagSet) compression.WriterFunc {
+			opts := DefaultOptions
+			fs.Var(&opts.Method, "method", "coding method, rans for range ANS or tans for tabled ANS")
+			compression.RangeVar(
+				fs, &opts.TableLog, "table-log", opts.TableLog, MinTableLog, MaxTableLog,
+				"binary logarithm of the sum of normalized frequencies, from 9 to 15",
+			)
+
+			return func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+				return newWriter(w, h, opts)
+			}
+		},
+		Unpack: func(_ *flag.FlagSet) compression.ReaderFunc {
+			return newReader
+		},
+	})
+}
+
+type Codec struct {
+	opts Options
+}
+
+// New returns the Codec packing with DefaultOptions.
+func New() Codec {
+	return Codec{opts: DefaultOptions}
+}
+
+// NewOptions returns the Codec packing with the given options, it returns an error if they are out of range.
+func NewOptions(opts Options) (Codec, error) {
+	if err := opts.validate(); err != nil {
+		return Codec{}, fmt.Errorf("ans: %w", err)
+	}
+	return Codec{opts: opts}, nil
+}
+
+func (c Codec) Pack(data []byte) ([]byte, error) {
+	return compression.PackWith(func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+		return newWriter(w, h, c.opts)
+	}, data)
+}
+
+func (_ Codec) Unpack(data []byte) ([]byte, error) {
+	return compression.UnpackWith(newReader, data)
+}
+
+// newWriter returns the Writer packing the data to w with the Header and the options.
+func newWriter(w io.Writer, h compression.Header, opts Options) (io.WriteCloser, error) {
+	z, err := NewWriterOptions(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	z.Header = h
+	return z, nil
+}
+
+// newReader returns the Reader unpacking the data read from r.
+func newReader(r io.Reader) io.ReadCloser {
+	return NewReader(r)
+}
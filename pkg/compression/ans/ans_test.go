@@ -0,0 +1,244 @@
+package ans
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+var rans = Options{Method: RANS, TableLog: 12}
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		opts      Options
+	}{
+		{str: "", opts: DefaultOptions},
+		{str: "abracadabra", opts: DefaultOptions},
+		{str: "abracadabra", opts: rans},
+		{str: "abracadabra", opts: Options{Method: TANS, TableLog: MinTableLog}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q with %s and table of %d bits", test.str, test.opts.Method, test.opts.TableLog)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewOptions(test.opts)
+			assert.Nil(t, err)
+			bytes, err := c.Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, packed(test.str, test.opts), bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		opts       Options
+	}{
+		{want: "", opts: DefaultOptions},
+		{want: "abracadabra", opts: DefaultOptions},
+		{want: "abracadabra", opts: rans},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q with %s", test.want, test.opts.Method)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := packed(test.want, test.opts)
+			unpacked, err := New().Unpack(data)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", data)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", data)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "unicode text", data: []byte("Привет, мир ∑ π")},
+		{name: "every byte value", data: allBytes},
+		{name: "random data", data: random},
+		{name: "several blocks", data: bytes.Repeat([]byte("Some pretty SUBsequence, 42!\n"), 2*blockSize/29+1)},
+	}
+
+	for _, opts := range []Options{DefaultOptions, rans} {
+		for _, test := range tests {
+			test, opts := test, opts
+			test.name = fmt.Sprintf("packing and unpacking %s with %s", test.name, opts.Method)
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				c, _ := NewOptions(opts)
+				packed, err := c.Pack(test.data)
+				assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.data)
+				unpacked, err := c.Unpack(packed)
+				assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+				assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.data)
+			})
+		}
+	}
+}
+
+func TestCodecBeatsPrefixCodes(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = 'a'
+		if rnd.Intn(10) == 0 {
+			data[i] = 'b'
+		}
+	}
+
+	prefixCoded, err := huffman.New().Pack(data)
+	assert.Nil(t, err)
+
+	for _, opts := range []Options{DefaultOptions, rans} {
+		c, _ := NewOptions(opts)
+		packed, err := c.Pack(data)
+		assert.Nil(t, err)
+		assert.Lessf(t, len(packed), len(prefixCoded)*3/5, "%s must beat prefix codes on skewed data", opts.Method)
+	}
+}
+
+func TestNewOptionsError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opts  Options
+		error string
+	}{
+		{opts: Options{}, error: "ans: unknown method 0"},
+		{opts: Options{Method: 3, TableLog: 12}, error: "ans: unknown method 3"},
+		{opts: Options{Method: RANS, TableLog: 8}, error: "ans: table log 8 out of range [9, 15]"},
+		{opts: Options{Method: TANS, TableLog: 16}, error: "ans: table log 16 out of range [9, 15]"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("creating codec with %+v", test.opts), func(t *testing.T) {
+			t.Parallel()
+			_, err := NewOptions(test.opts)
+			assert.EqualErrorf(t, err, test.error, "NewOptions(%v) unexpected error", test.opts)
+		})
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	t.Parallel()
+
+	for _, m := range []Method{RANS, TANS} {
+		got, err := ParseMethod(m.String())
+		assert.Nil(t, err)
+		assert.Equal(t, m, got)
+	}
+
+	_, err := ParseMethod("fse")
+	assert.EqualError(t, err, `ans: unknown method "fse"`)
+	assert.Equal(t, "method(7)", Method(7).String())
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte("abracadabra"))
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.ANS), err)
+}
+
+func TestRegisteredFlags(t *testing.T) {
+	t.Parallel()
+
+	f, _ := compression.Lookup("ans")
+
+	fs := flag.NewFlagSet("ans", flag.ContinueOnError)
+	newWriter := f.Pack(fs)
+	assert.Nil(t, fs.Parse([]string{"-method", "rans", "-table-log", "10"}))
+
+	var buf bytes.Buffer
+	w, err := newWriter(&buf, compression.Header{})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("abcabcabc"))
+	assert.Nil(t, w.Close())
+	want := header(compression.Header{}, Options{Method: RANS, TableLog: 10})
+	assert.Equal(t, want, buf.Bytes()[:len(want)], "the registered writer must pack with the options of the flags")
+
+	unpacked, err := io.ReadAll(f.Unpack(flag.NewFlagSet("ans", flag.ContinueOnError))(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabc"), unpacked)
+}
+
+// header returns the header of data packed with ans followed by the options.
+func header(h compression.Header, opts Options) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.ANS
+	_ = compression.WriteHeader(&buf, h)
+
+	return append(buf.Bytes(), byte(opts.Method), byte(opts.TableLog))
+}
+
+// block returns the block of str coded with the options.
+func block(str string, opts Options) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	buf = buf[:binary.PutUvarint(buf, uint64(len(str)))]
+
+	data := bytes.NewBuffer(buf)
+	w := bitio.NewBitWriter(data)
+	_ = Encode(w, []byte(str), opts)
+	_ = w.Flush()
+
+	return data.Bytes()
+}
+
+// endMark is the block marking the end of the packed data.
+var endMark = []byte{0}
+
+// packed returns the data written by Codec.Pack for str: the header, the options, a single block, the end mark
+// and the checksum.
+func packed(str string, opts Options) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))}, opts)
+	if str != "" {
+		data = append(data, block(str, opts)...)
+	}
+	return append(append(data, endMark...), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
@@ -0,0 +1,50 @@
+package ans
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+)
+
+// Encode writes the frequency table of data normalized to sum up to 1<<opts.TableLog followed by data coded
+// with opts.Method to w. The number of bytes is not written, it must be passed to Decode separately.
+//
+// It lets other codecs use ANS as the final entropy stage of their blocks. Empty data is not written at all.
+func Encode(w *bitio.BitWriter, data []byte, opts Options) error {
+	if err := opts.validate(); err != nil {
+		return fmt.Errorf("ans: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	t := newTable(data, opts.TableLog)
+	if err := t.write(w); err != nil {
+		return err
+	}
+
+	if opts.Method == RANS {
+		return ransEncode(w, data, t)
+	}
+	return tansEncode(w, data, t)
+}
+
+// Decode reads the frequency table and n bytes written by Encode with the same options from r, appends
+// the decoded bytes to dst and returns the extended slice.
+func Decode(r *bitio.BitReader, n int, dst []byte, opts Options) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return dst, fmt.Errorf("ans: %w", err)
+	}
+	if n == 0 {
+		return dst, nil
+	}
+
+	t, err := readTable(r, opts.TableLog)
+	if err != nil {
+		return dst, err
+	}
+
+	if opts.Method == RANS {
+		return ransDecode(r, n, dst, t)
+	}
+	return tansDecode(r, n, dst, t)
+}
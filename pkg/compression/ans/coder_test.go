@@ -0,0 +1,88 @@
+package ans
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression/bwt"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/psssix/archiver/pkg/compression/mtf"
+	"github.com/psssix/archiver/pkg/compression/rle"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+	}{
+		{str: ""},
+		{str: "aaa"},
+		{str: "abracadabra"},
+		{str: "Съешь же ещё этих мягких французских булок"},
+	}
+
+	for _, method := range []Method{RANS, TANS} {
+		for _, test := range tests {
+			test, opts := test, Options{Method: method, TableLog: DefaultOptions.TableLog}
+			test.name = fmt.Sprintf("encode and decode %q with %s", test.str, opts.Method)
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				buf := new(bytes.Buffer)
+				bw := bitio.NewBitWriter(buf)
+				assert.Nil(t, Encode(bw, []byte(test.str), opts))
+				assert.Nil(t, bw.Flush())
+				if test.str == "" {
+					assert.Zero(t, buf.Len(), "Encode() must write nothing for empty data")
+				}
+
+				got, err := Decode(bitio.NewBitReader(buf), len(test.str), []byte("x"), opts)
+				assert.Nil(t, err)
+				assert.Equalf(t, "x"+test.str, string(got), "Decode() must reverse Encode() and append to dst")
+			})
+		}
+	}
+}
+
+func TestEncodeDecodeInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{Method: TANS, TableLog: 4}
+	assert.EqualError(t, Encode(bitio.NewBitWriter(&bytes.Buffer{}), []byte("a"), opts), "ans: table log 4 out of range [9, 15]")
+	_, err := Decode(bitio.NewBitReader(&bytes.Buffer{}), 1, nil, opts)
+	assert.EqualError(t, err, "ans: table log 4 out of range [9, 15]")
+}
+
+func TestEncodeAsFinalStage(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog, then the dog sleeps. ", 2000))
+	transformed, primary := bwt.Transform(nil, data)
+	encoded := rle.Encode(nil, mtf.Encode(nil, transformed))
+
+	var prefixCoded bytes.Buffer
+	hw := bitio.NewBitWriter(&prefixCoded)
+	assert.Nil(t, huffman.Encode(hw, encoded))
+	assert.Nil(t, hw.Flush())
+
+	for _, method := range []Method{RANS, TANS} {
+		opts := Options{Method: method, TableLog: DefaultOptions.TableLog}
+
+		var buf bytes.Buffer
+		w := bitio.NewBitWriter(&buf)
+		assert.Nil(t, Encode(w, encoded, opts))
+		assert.Nil(t, w.Flush())
+		assert.Lessf(t, buf.Len(), prefixCoded.Len(), "%s must code the pipeline output better than Huffman code", method)
+
+		decoded, err := Decode(bitio.NewBitReader(&buf), len(encoded), nil, opts)
+		assert.Nil(t, err)
+		unpacked, err := rle.Decode(nil, decoded)
+		assert.Nil(t, err)
+		unpacked, err = bwt.Inverse(nil, mtf.Decode(nil, unpacked), primary)
+		assert.Nil(t, err)
+		assert.Equal(t, data, unpacked)
+	}
+}
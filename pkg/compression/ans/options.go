@@ -0,0 +1,71 @@
+package ans
+
+import "fmt"
+
+const (
+	MinTableLog = 9
+	MaxTableLog = 15
+)
+
+// Method is the variant of asymmetric numeral systems coding the data.
+type Method uint8
+
+const (
+	// RANS is range ANS: the state is updated arithmetically and renormalized by bytes.
+	RANS Method = iota + 1
+	// TANS is tabled ANS: the state transitions are precomputed, so decoding is a table lookup per symbol.
+	TANS
+)
+
+var methodNames = map[Method]string{
+	RANS: "rans",
+	TANS: "tans",
+}
+
+func (m Method) String() string {
+	if name, ok := methodNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("method(%d)", uint8(m))
+}
+
+// ParseMethod returns the Method with the given name.
+func ParseMethod(name string) (Method, error) {
+	for m, n := range methodNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("ans: unknown method %q", name)
+}
+
+// Set sets the Method by its name, so the Method can be used as a flag.Value.
+func (m *Method) Set(name string) error {
+	method, err := ParseMethod(name)
+	if err != nil {
+		return err
+	}
+	*m = method
+	return nil
+}
+
+// Options configure the coding of the Writer. The Reader gets them from the packed data.
+type Options struct {
+	Method Method
+	// TableLog is the binary logarithm of the sum of the normalized frequencies. Larger tables approximate
+	// the frequencies better, but take more space in the packed data and more memory.
+	TableLog uint
+}
+
+// DefaultOptions are the options of the Writer returned by NewWriter.
+var DefaultOptions = Options{Method: TANS, TableLog: 12}
+
+func (o Options) validate() error {
+	if _, ok := methodNames[o.Method]; !ok {
+		return fmt.Errorf("unknown method %d", uint8(o.Method))
+	}
+	if o.TableLog < MinTableLog || o.TableLog > MaxTableLog {
+		return fmt.Errorf("table log %d out of range [%d, %d]", o.TableLog, MinTableLog, MaxTableLog)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package ans
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+)
+
+// ransLow is the lower bound of the rANS state after renormalization, the state stays below ransLow<<8.
+const ransLow = 1 << 23
+
+// ransEncode writes the rANS coded data to w: the final state in 32 bits followed by the renormalization bytes.
+//
+// The symbols are coded in reverse order, so the decoder gets them in the original order. The bytes are
+// collected in reverse order too and written backwards.
+func ransEncode(w *bitio.BitWriter, data []byte, t *table) error {
+	out := make([]byte, 0, len(data)/2+4)
+
+	x := uint32(ransLow)
+	for i := len(data) - 1; i >= 0; i-- {
+		s := data[i]
+		f := t.freq[s]
+
+		xMax := (ransLow >> t.log << 8) * f
+		for x >= xMax {
+			out = append(out, byte(x))
+			x >>= 8
+		}
+		x = x/f<<t.log + x%f + t.cum[s]
+	}
+
+	if err := w.WriteBits(uint64(x), 32); err != nil {
+		return err
+	}
+	for i := len(out) - 1; i >= 0; i-- {
+		if err := w.WriteBits(uint64(out[i]), 8); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ransDecode reads n bytes coded by ransEncode from r, appends them to dst and returns the extended slice.
+func ransDecode(r *bitio.BitReader, n int, dst []byte, t *table) ([]byte, error) {
+	symbols := t.symbols()
+	mask := t.size() - 1
+
+	v, err := r.ReadBits(32)
+	if err != nil {
+		return dst, bitio.NoEOF(err)
+	}
+	x := uint32(v)
+	if x < ransLow {
+		return dst, fmt.Errorf("%w: invalid initial state %d", ErrCorrupted, x)
+	}
+
+	for i := 0; i < n; i++ {
+		slot := x & mask
+		s := symbols[slot]
+		dst = append(dst, s)
+
+		x = t.freq[s]*(x>>t.log) + slot - t.cum[s]
+		for x < ransLow {
+			b, err := r.ReadBits(8)
+			if err != nil {
+				return dst, bitio.NoEOF(err)
+			}
+			x = x<<8 | uint32(b)
+		}
+	}
+
+	if x != ransLow {
+		return dst, fmt.Errorf("%w: invalid final state %d", ErrCorrupted, x)
+	}
+
+	return dst, nil
+}
+
+// symbols returns the byte of every slot of the table: the slots from cum to cum+freq belong to the byte.
+func (t *table) symbols() []byte {
+	symbols := make([]byte, 0, t.size())
+	for s, f := range t.freq {
+		for i := uint32(0); i < f; i++ {
+			symbols = append(symbols, byte(s))
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,97 @@
+package ans
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestRANS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		log       uint
+		want      []byte
+	}{
+		// a single byte takes the whole table, so the state never changes
+		{str: "aaa", log: 9, want: []byte{0, 0x80, 0, 0}},
+		{str: "abracadabra", log: 9},
+		{str: string(bytes.Repeat([]byte("abracadabra"), 1000)), log: MaxTableLog},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("coding %.20q with table of %d bits", test.str, test.log)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			table := newTable([]byte(test.str), test.log)
+
+			var buf bytes.Buffer
+			w := bitio.NewBitWriter(&buf)
+			assert.Nil(t, ransEncode(w, []byte(test.str), table))
+			assert.Nil(t, w.Flush())
+			if test.want != nil {
+				assert.Equal(t, test.want, buf.Bytes())
+			}
+
+			r := bytes.NewReader(buf.Bytes())
+			got, err := ransDecode(bitio.NewBitReader(r), len(test.str), []byte("x"), table)
+			assert.Nil(t, err)
+			assert.Equal(t, "x"+test.str, string(got), "ransDecode() must reverse ransEncode() and append to dst")
+			assert.Zero(t, r.Len(), "ransDecode() must read all the data")
+		})
+	}
+}
+
+func TestRANSDecodeCorrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, error string
+		n                int
+		data             []byte
+		err              error
+	}{
+		{name: "truncated state", str: "a", n: 1, data: []byte{0, 0x80}, err: io.ErrUnexpectedEOF, error: "unexpected EOF"},
+		{
+			name:  "small initial state",
+			str:   "a",
+			n:     1,
+			data:  []byte{0, 0x7F, 0xFF, 0xFF},
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: invalid initial state 8388607",
+		},
+		{
+			name:  "invalid final state",
+			str:   "a",
+			n:     1,
+			data:  []byte{0, 0x80, 0, 1},
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: invalid final state 8388609",
+		},
+		{
+			name:  "missing renormalization bytes",
+			str:   "abracadabra",
+			n:     11,
+			data:  []byte{0, 0x80, 0, 0},
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			table := newTable([]byte(test.str), 9)
+			_, err := ransDecode(bitio.NewBitReader(bytes.NewReader(test.data)), test.n, nil, table)
+			assert.ErrorIsf(t, err, test.err, "ransDecode(%v)", test.data)
+			assert.EqualErrorf(t, err, test.error, "ransDecode(%v)", test.data)
+		})
+	}
+}
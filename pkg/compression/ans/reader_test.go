@@ -0,0 +1,147 @@
+package ans
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "several blocks", data: bytes.Repeat([]byte("abracadabra"), blockSize/11*3)},
+	}
+
+	for _, opts := range []Options{DefaultOptions, rans} {
+		for _, test := range tests {
+			test, opts := test, opts
+			test.name = fmt.Sprintf("unpacking %s with %s byte by byte", test.name, opts.Method)
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				c, _ := NewOptions(opts)
+				packed, _ := c.Pack(test.data)
+				r := NewReader(iotest.OneByteReader(bytes.NewReader(packed)))
+				data, err := io.ReadAll(r)
+				assert.Nil(t, err)
+				assert.Equal(t, test.data, data)
+				assert.Equal(t, uint64(len(test.data)), r.Header.Size)
+			})
+		}
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	abracadabra := block("abracadabra", DefaultOptions)
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "invalid options",
+			data:  append(header(compression.Header{}, Options{Method: 3, TableLog: 12}), endMark...),
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: unknown method 3",
+		},
+		{
+			name:  "missing options",
+			data:  header(compression.Header{}, DefaultOptions)[:compression.HeaderSize+1],
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name: "size is less than header size",
+			data: append(
+				append(header(compression.Header{Flags: compression.FlagSize, Size: 12}, DefaultOptions), abracadabra...),
+				endMark...,
+			),
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: unpacked 11 bytes, but header size is 12",
+		},
+		{
+			name: "size exceeds header size",
+			data: append(
+				append(header(compression.Header{Flags: compression.FlagSize, Size: 10}, DefaultOptions), abracadabra...),
+				endMark...,
+			),
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: unpacked data exceeds header size 10",
+		},
+		{
+			name:  "invalid frequency table",
+			data:  append(append(header(compression.Header{}, DefaultOptions), 3, 0x7F, 0x80), endMark...),
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: frequencies sum up to 0 instead of 4096",
+		},
+		{
+			name:  "too long block",
+			data:  append(append(header(compression.Header{}, DefaultOptions), 0x81, 0x80, 0x08), endMark...),
+			err:   ErrCorrupted,
+			error: "ans: packed data is corrupted: block of 131073 bytes is too long",
+		},
+		{
+			name:  "truncated block",
+			data:  append(header(compression.Header{}, DefaultOptions), abracadabra[:len(abracadabra)-2]...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "missing end mark",
+			data:  append(header(compression.Header{}, DefaultOptions), abracadabra...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	packed, _ := New().Pack([]byte("abracadabra"))
+	r := NewReader(bytes.NewReader(packed))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	c, _ := NewOptions(rans)
+	first, _ := c.Pack([]byte("abracadabra"))
+	second, _ := New().Pack([]byte("Ted"))
+
+	r := NewReader(bytes.NewReader(first))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(second))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
@@ -0,0 +1,140 @@
+package ans
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+)
+
+// table holds the frequencies of the bytes normalized to sum up to 1<<log, every present byte has
+// a non-zero frequency.
+type table struct {
+	log  uint
+	freq [256]uint32
+	// cum is the sum of the frequencies of the bytes below.
+	cum [256]uint32
+}
+
+// newTable returns the table of the byte frequencies of data normalized to sum up to 1<<log.
+//
+// Frequencies are scaled down keeping at least 1 for every present byte, the rounding error is corrected
+// on the most frequent bytes, where it costs the least.
+func newTable(data []byte, log uint) *table {
+	t := &table{log: log}
+
+	var count [256]uint64
+	for _, b := range data {
+		count[b]++
+	}
+
+	size := uint64(1) << log
+	var sum uint64
+	most := 0
+	for s, c := range count {
+		if c == 0 {
+			continue
+		}
+		f := c * size / uint64(len(data))
+		if f == 0 {
+			f = 1
+		}
+		t.freq[s] = uint32(f)
+		sum += f
+		if c > count[most] {
+			most = s
+		}
+	}
+
+	if sum < size {
+		t.freq[most] += uint32(size - sum)
+	}
+	for ; sum > size; sum-- {
+		largest := 0
+		for s, f := range t.freq {
+			if f > t.freq[largest] {
+				largest = s
+			}
+		}
+		t.freq[largest]--
+	}
+	t.sum()
+
+	return t
+}
+
+func (t *table) sum() {
+	var cum uint32
+	for s, f := range t.freq {
+		t.cum[s] = cum
+		cum += f
+	}
+}
+
+// size returns the sum of the normalized frequencies.
+func (t *table) size() uint32 {
+	return 1 << t.log
+}
+
+// write writes the frequencies to w. Every present byte is written as bit 1 followed by its frequency less 1
+// in log bits, every run of absent bytes is written as bit 0 followed by its length less 1 in 8 bits.
+func (t *table) write(w *bitio.BitWriter) error {
+	for s := 0; s < len(t.freq); {
+		if t.freq[s] != 0 {
+			if err := w.WriteBits(1<<t.log|uint64(t.freq[s]-1), 1+t.log); err != nil {
+				return err
+			}
+			s++
+			continue
+		}
+
+		run := 1
+		for s+run < len(t.freq) && t.freq[s+run] == 0 {
+			run++
+		}
+		if err := w.WriteBits(uint64(run-1), 9); err != nil {
+			return err
+		}
+		s += run
+	}
+
+	return nil
+}
+
+// readTable reads the frequencies written by table.write from r, they must sum up to 1<<log.
+func readTable(r *bitio.BitReader, log uint) (*table, error) {
+	t := &table{log: log}
+
+	var sum uint32
+	for s := 0; s < len(t.freq); {
+		present, err := r.ReadBit()
+		if err != nil {
+			return nil, bitio.NoEOF(err)
+		}
+
+		if present {
+			f, err := r.ReadBits(log)
+			if err != nil {
+				return nil, bitio.NoEOF(err)
+			}
+			t.freq[s] = uint32(f) + 1
+			sum += t.freq[s]
+			s++
+			continue
+		}
+
+		run, err := r.ReadBits(8)
+		if err != nil {
+			return nil, bitio.NoEOF(err)
+		}
+		if s+int(run)+1 > len(t.freq) {
+			return nil, fmt.Errorf("%w: run of absent bytes exceeds the table", ErrCorrupted)
+		}
+		s += int(run) + 1
+	}
+
+	if sum != t.size() {
+		return nil, fmt.Errorf("%w: frequencies sum up to %d instead of %d", ErrCorrupted, sum, t.size())
+	}
+	t.sum()
+
+	return t, nil
+}
@@ -0,0 +1,144 @@
+package ans
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewTable(t *testing.T) {
+	t.Parallel()
+
+	rare := strings.Repeat("a", 10000) + "b"
+
+	tests := []struct {
+		name, str string
+		log       uint
+		want      map[byte]uint32
+	}{
+		{str: "a", log: 9, want: map[byte]uint32{'a': 512}},
+		{str: "ab", log: 9, want: map[byte]uint32{'a': 256, 'b': 256}},
+		{str: "abb", log: 9, want: map[byte]uint32{'a': 170, 'b': 342}},
+		{str: "abracadabra", log: 9, want: map[byte]uint32{'a': 234, 'b': 93, 'c': 46, 'd': 46, 'r': 93}},
+		{str: rare, log: 9, want: map[byte]uint32{'a': 511, 'b': 1}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("normalizing frequencies of %.20q to %d bits", test.str, test.log)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var want [256]uint32
+			for s, f := range test.want {
+				want[s] = f
+			}
+
+			table := newTable([]byte(test.str), test.log)
+			assert.Equalf(t, want, table.freq, "newTable(%v, %d)", test.str, test.log)
+
+			var cum uint32
+			for s, f := range table.freq {
+				assert.Equalf(t, cum, table.cum[s], "cumulative frequency of %d", s)
+				cum += f
+			}
+			assert.Equal(t, table.size(), cum, "frequencies must sum up to the table size")
+		})
+	}
+}
+
+func TestNewTableKeepsRareBytes(t *testing.T) {
+	t.Parallel()
+
+	data := bytes.Repeat([]byte{0}, 1<<20)
+	for b := 1; b < 256; b++ {
+		data = append(data, byte(b))
+	}
+
+	table := newTable(data, MinTableLog)
+	for s, f := range table.freq {
+		assert.NotZerof(t, f, "frequency of present byte %d", s)
+	}
+	assert.Equal(t, uint32(1<<MinTableLog-255), table.freq[0], "the most frequent byte must pay for the rare ones")
+}
+
+func TestTableWriteRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		log       uint
+		bString   string
+	}{
+		{
+			str:     "a",
+			log:     9,
+			bString: "0" + "01100000" + "1" + "111111111" + "0" + "10011101",
+		},
+		{
+			str:     "ab",
+			log:     9,
+			bString: "0" + "01100000" + "1" + "011111111" + "1" + "011111111" + "0" + "10011100",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("writing and reading frequency table of %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			table := newTable([]byte(test.str), test.log)
+
+			var buf bytes.Buffer
+			w := bitio.NewBitWriter(&buf)
+			assert.Nil(t, table.write(w))
+			assert.Equal(t, uint64(len(test.bString)), w.Count())
+			assert.Nil(t, w.Flush())
+			assert.Equal(t, fromBitString(test.bString), buf.Bytes())
+
+			got, err := readTable(bitio.NewBitReader(&buf), test.log)
+			assert.Nil(t, err)
+			assert.Equal(t, table, got)
+		})
+	}
+}
+
+func TestReadTableCorrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, bString string
+		err           error
+	}{
+		{name: "empty data", bString: "", err: io.ErrUnexpectedEOF},
+		{name: "truncated frequency", bString: "0" + "01100000" + "1" + "1111", err: io.ErrUnexpectedEOF},
+		{name: "small sum", bString: "0" + "01100000" + "1" + "011111111" + "0" + "10011110", err: ErrCorrupted},
+		{name: "too long run", bString: "0" + "11111111" + "0" + "00000000", err: ErrCorrupted},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("reading frequency table from %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readTable(bitio.NewBitReader(bytes.NewReader(fromBitString(test.bString))), 9)
+			assert.ErrorIsf(t, err, test.err, "readTable(%v)", test.bString)
+		})
+	}
+}
+
+// fromBitString returns bytes of the string of '0' and '1', the last byte is padded with zeros.
+func fromBitString(bString string) []byte {
+	var buf bytes.Buffer
+
+	w := bitio.NewBitWriter(&buf)
+	for _, c := range bString {
+		_ = w.WriteBit(c == '1')
+	}
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
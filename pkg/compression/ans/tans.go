@@ -0,0 +1,132 @@
+package ans
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"math/bits"
+)
+
+// spread returns the byte of every tANS state: the occurrences of every byte are spread over the table with
+// an odd step, so the states of every byte are scattered evenly.
+func (t *table) spread() []byte {
+	size := t.size()
+	step := size>>1 + size>>3 + 3
+	mask := size - 1
+
+	states := make([]byte, size)
+	var pos uint32
+	for s, f := range t.freq {
+		for i := uint32(0); i < f; i++ {
+			states[pos] = byte(s)
+			pos = (pos + step) & mask
+		}
+	}
+
+	return states
+}
+
+// tansSymbol holds the encoding transform of a byte: a state x in [size, 2*size) gives away
+// (x + deltaBits) >> 16 lowest bits, and the rest of it plus deltaState indexes the next state.
+type tansSymbol struct {
+	deltaBits  uint32
+	deltaState int32
+}
+
+// tansEncode writes the tANS coded data to w: the final state less the table size in log bits followed by
+// the bits the states gave away.
+//
+// The symbols are coded in reverse order, so the decoder gets them in the original order. The bits are
+// collected in reverse order too and written backwards.
+func tansEncode(w *bitio.BitWriter, data []byte, t *table) error {
+	size := t.size()
+
+	// next lists the states of every byte in ascending order, starting at its cum
+	next := make([]uint32, size)
+	cum := t.cum
+	for u, s := range t.spread() {
+		next[cum[s]] = size + uint32(u)
+		cum[s]++
+	}
+
+	var symbols [256]tansSymbol
+	for s, f := range t.freq {
+		switch f {
+		case 0:
+		case 1:
+			symbols[s] = tansSymbol{deltaBits: uint32(t.log)<<16 - size, deltaState: int32(t.cum[s]) - 1}
+		default:
+			maxBits := uint32(t.log) - uint32(bits.Len32(f-1)-1)
+			symbols[s] = tansSymbol{deltaBits: maxBits<<16 - f<<maxBits, deltaState: int32(t.cum[s]) - int32(f)}
+		}
+	}
+
+	type chunk struct {
+		bits uint32
+		n    uint8
+	}
+	out := make([]chunk, len(data))
+
+	x := size
+	for i := len(data) - 1; i >= 0; i-- {
+		sym := symbols[data[i]]
+		n := (x + sym.deltaBits) >> 16
+		out[i] = chunk{bits: x & (1<<n - 1), n: uint8(n)}
+		x = next[int32(x>>n)+sym.deltaState]
+	}
+
+	if err := w.WriteBits(uint64(x-size), t.log); err != nil {
+		return err
+	}
+	for _, c := range out {
+		if err := w.WriteBits(uint64(c.bits), uint(c.n)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tansState is a decoding table entry: the byte of the state, the number of bits to read and the base
+// of the next state they are added to.
+type tansState struct {
+	symbol byte
+	n      uint8
+	base   uint32
+}
+
+// tansDecode reads n bytes coded by tansEncode from r, appends them to dst and returns the extended slice.
+func tansDecode(r *bitio.BitReader, n int, dst []byte, t *table) ([]byte, error) {
+	size := t.size()
+
+	states := make([]tansState, size)
+	next := t.freq
+	for u, s := range t.spread() {
+		x := next[s]
+		next[s]++
+		k := t.log - uint(bits.Len32(x)-1)
+		states[u] = tansState{symbol: s, n: uint8(k), base: x<<k - size}
+	}
+
+	v, err := r.ReadBits(t.log)
+	if err != nil {
+		return dst, bitio.NoEOF(err)
+	}
+	x := uint32(v)
+
+	for i := 0; i < n; i++ {
+		st := states[x]
+		dst = append(dst, st.symbol)
+
+		v, err := r.ReadBits(uint(st.n))
+		if err != nil {
+			return dst, bitio.NoEOF(err)
+		}
+		x = st.base + uint32(v)
+	}
+
+	if x != 0 {
+		return dst, fmt.Errorf("%w: invalid final state %d", ErrCorrupted, x)
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,107 @@
+package ans
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestSpread(t *testing.T) {
+	t.Parallel()
+
+	table := newTable([]byte("ab"), MinTableLog)
+	states := table.spread()
+
+	count := map[byte]int{}
+	for _, s := range states {
+		count[s]++
+	}
+	assert.Equal(t, map[byte]int{'a': 256, 'b': 256}, count, "spread() must fill the table with the frequencies")
+	// the step of 323 states scatters the bytes instead of filling halves of the table
+	assert.Equal(t, []byte("abaabbab"), states[:8])
+}
+
+func TestTANS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		log       uint
+		bString   string
+	}{
+		// a single byte takes the whole table, so the state never changes and gives away no bits
+		{str: "aaa", log: 9, bString: "000000000"},
+		{str: "abracadabra", log: 9},
+		{str: string(bytes.Repeat([]byte("abracadabra"), 1000)), log: MaxTableLog},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("coding %.20q with table of %d bits", test.str, test.log)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			table := newTable([]byte(test.str), test.log)
+
+			var buf bytes.Buffer
+			w := bitio.NewBitWriter(&buf)
+			assert.Nil(t, tansEncode(w, []byte(test.str), table))
+			if test.bString != "" {
+				assert.Equal(t, uint64(len(test.bString)), w.Count())
+			}
+			assert.Nil(t, w.Flush())
+			if test.bString != "" {
+				assert.Equal(t, fromBitString(test.bString), buf.Bytes())
+			}
+
+			r := bytes.NewReader(buf.Bytes())
+			got, err := tansDecode(bitio.NewBitReader(r), len(test.str), []byte("x"), table)
+			assert.Nil(t, err)
+			assert.Equal(t, "x"+test.str, string(got), "tansDecode() must reverse tansEncode() and append to dst")
+			assert.Zero(t, r.Len(), "tansDecode() must read all the data")
+		})
+	}
+}
+
+func TestTANSDecodeCorrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, error string
+		n                int
+		bString          string
+		err              error
+	}{
+		{name: "truncated state", str: "a", n: 1, bString: "0000", err: io.ErrUnexpectedEOF, error: "unexpected EOF"},
+		{
+			name:    "invalid final state",
+			str:     "a",
+			n:       1,
+			bString: "000000001",
+			err:     ErrCorrupted,
+			error:   "ans: packed data is corrupted: invalid final state 1",
+		},
+		{
+			name:    "missing bits",
+			str:     "abracadabra",
+			n:       11,
+			bString: "000000000",
+			err:     io.ErrUnexpectedEOF,
+			error:   "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			table := newTable([]byte(test.str), 9)
+			_, err := tansDecode(bitio.NewBitReader(bytes.NewReader(fromBitString(test.bString))), test.n, nil, table)
+			assert.ErrorIsf(t, err, test.err, "tansDecode(%v)", test.bString)
+			assert.EqualErrorf(t, err, test.error, "tansDecode(%v)", test.bString)
+		})
+	}
+}
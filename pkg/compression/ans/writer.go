@@ -0,0 +1,204 @@
+package ans
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// blockSize is the number of bytes packed with their own frequency table.
+const blockSize = 128 * 1024
+
+var (
+	ErrClosed    = errors.New("ans: stream is closed")
+	ErrCorrupted = errors.New("ans: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it with asymmetric numeral systems.
+//
+// The Header is followed by the options: Method and TableLog, a byte each. Then the data is packed by blocks
+// of up to blockSize bytes, each with the frequencies normalized from its own byte frequencies: the number
+// of bytes in the block as uvarint and the output of Encode padded to whole bytes. An empty block marks
+// the end of the data.
+//
+// With FlagChecksum set in the Header, the packed data is followed by the checksum of the original data.
+//
+// The Header is written before the first block, so its Size and Flags may be set until the first call
+// to Write, Flush or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           io.Writer
+	wroteHeader bool
+	opts        Options
+	bw          *bitio.BitWriter
+	block       []byte
+	written     uint64
+	crc         uint32
+	err         error
+}
+
+// NewWriter returns a new Writer packing the data to w with DefaultOptions.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	z, _ := NewWriterOptions(w, DefaultOptions)
+	return z
+}
+
+// NewWriterOptions is like NewWriter but packs with the given options.
+//
+// It returns an error if the options are invalid.
+func NewWriterOptions(w io.Writer, opts Options) (*Writer, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("ans: %w", err)
+	}
+
+	return &Writer{
+		w:     w,
+		opts:  opts,
+		bw:    bitio.NewBitWriter(w),
+		block: make([]byte, 0, blockSize),
+	}, nil
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	for written := 0; written < len(p); {
+		n := copy(z.block[len(z.block):cap(z.block)], p[written:])
+		z.block = z.block[:len(z.block)+n]
+
+		if len(z.block) == cap(z.block) {
+			if z.err = z.writeBlock(); z.err != nil {
+				return written, z.err
+			}
+		}
+
+		z.crc = compression.UpdateChecksum(z.crc, p[written:written+n])
+		written += n
+		z.written += uint64(n)
+	}
+
+	return len(p), nil
+}
+
+// Flush packs all the data written so far as a block and writes it to the underlying writer.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.err = z.writeBlock()
+	return z.err
+}
+
+// Close writes the rest of the packed data and the end of the data mark to the underlying writer.
+// It does not close the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeUvarint(0); z.err != nil {
+		return z.err
+	}
+	if z.err = z.bw.Flush(); z.err != nil {
+		return z.err
+	}
+	if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+		return z.err
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("ans: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriterOptions(w, opts) with
+// the Writer's options.
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.bw.Reset(w)
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.block = z.block[:0]
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.ANS
+	z.wroteHeader = true
+
+	if err := compression.WriteHeader(z.w, z.Header); err != nil {
+		return err
+	}
+	_, err := z.w.Write([]byte{byte(z.opts.Method), byte(z.opts.TableLog)})
+
+	return err
+}
+
+// writeBlock packs the data collected since the previous block as a block, if any.
+func (z *Writer) writeBlock() error {
+	if len(z.block) == 0 {
+		return nil
+	}
+
+	if err := z.writeUvarint(uint64(len(z.block))); err != nil {
+		return err
+	}
+	if err := Encode(z.bw, z.block, z.opts); err != nil {
+		return err
+	}
+	if err := z.bw.Flush(); err != nil {
+		return err
+	}
+
+	z.block = z.block[:0]
+
+	return nil
+}
+
+func (z *Writer) writeUvarint(v uint64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	for _, b := range buf[:binary.PutUvarint(buf, v)] {
+		if err := z.bw.WriteBits(uint64(b), 8); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package ans
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+		opts      Options
+	}{
+		{str: "", partSize: 1, opts: DefaultOptions},
+		{str: "abracadabra", partSize: 1, opts: DefaultOptions},
+		{str: "abracadabra", partSize: 4, opts: rans},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes with %s", test.str, test.partSize, test.opts.Method)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w, err := NewWriterOptions(buf, test.opts)
+			assert.Nil(t, err)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := header(compression.Header{}, test.opts)
+			if test.str != "" {
+				want = append(want, block(test.str, test.opts)...)
+			}
+			assert.Equalf(t, append(want, endMark...), buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	_, _ = w.Write([]byte("aaa"))
+	assert.Nil(t, w.Flush())
+	want := append(header(compression.Header{}, DefaultOptions), block("aaa", DefaultOptions)...)
+	assert.Equal(t, want, buf.Bytes(), "Writer.Flush() must write all packed data as a block")
+
+	_, _ = w.Write([]byte("ab"))
+	assert.Nil(t, w.Close())
+	want = append(want, block("ab", DefaultOptions)...)
+	assert.Equal(t, append(want, endMark...), buf.Bytes())
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("aaa"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "ans: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("aaa"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w, _ := NewWriterOptions(&first, rans)
+	_, _ = w.Write([]byte("abracadabra"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("aaa"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}, rans), first.Bytes())
+	assert.Equal(t, append(append(header(compression.Header{}, rans), block("aaa", rans)...), endMark...), second.Bytes())
+}
+
+func TestNewWriterOptionsError(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewWriterOptions(&bytes.Buffer{}, Options{Method: TANS, TableLog: 20})
+	assert.Nil(t, w)
+	assert.EqualError(t, err, "ans: table log 20 out of range [9, 15]")
+}
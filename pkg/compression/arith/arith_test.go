@@ -0,0 +1,232 @@
+package arith
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		opts      Options
+		want      []byte
+	}{
+		// the end of the data symbol is the last of 257 equally frequent symbols
+		{str: "", opts: DefaultOptions, want: []byte{0, 0xFF, 0, 0xFF, 0, 0}},
+		{str: "abracadabra", opts: DefaultOptions, want: coded(0, "abracadabra")},
+		{str: "abracadabra", opts: Options{Order: 1}, want: coded(1, "abracadabra")},
+		{str: "abracadabra", opts: Options{Order: 2}, want: coded(2, "abracadabra")},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q with model of order %d", test.str, test.opts.Order)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewOptions(test.opts)
+			assert.Nil(t, err)
+			bytes, err := c.Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, packed(test.str, test.opts, test.want), bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		opts       Options
+		bytes      []byte
+	}{
+		{want: "", opts: DefaultOptions, bytes: []byte{0, 0xFF, 0, 0xFF, 0, 0}},
+		{want: "abracadabra", opts: DefaultOptions, bytes: coded(0, "abracadabra")},
+		{want: "abracadabra", opts: Options{Order: 2}, bytes: coded(2, "abracadabra")},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q with model of order %d", test.want, test.opts.Order)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := packed(test.want, test.opts, test.bytes)
+			unpacked, err := New().Unpack(data)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", data)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", data)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "unicode text", data: []byte("Привет, мир ∑ π")},
+		{name: "every byte value", data: allBytes},
+		{name: "long runs", data: bytes.Repeat([]byte{0xFF}, 100000)},
+		{name: "random data", data: random},
+	}
+
+	for order := uint(0); order <= MaxOrder; order++ {
+		for _, test := range tests {
+			test, order := test, order
+			test.name = fmt.Sprintf("packing and unpacking %s with model of order %d", test.name, order)
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				c, _ := NewOptions(Options{Order: order})
+				packed, err := c.Pack(test.data)
+				assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.data)
+				unpacked, err := c.Unpack(packed)
+				assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+				assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.data)
+			})
+		}
+	}
+}
+
+func TestCodecNearEntropy(t *testing.T) {
+	t.Parallel()
+
+	// a of probability 0.9 and b of 0.1 take 0.469 bits per byte, while a prefix code needs a whole bit
+	rnd := rand.New(rand.NewSource(1))
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = 'a'
+		if rnd.Intn(10) == 0 {
+			data[i] = 'b'
+		}
+	}
+	entropy := -(0.9*math.Log2(0.9) + 0.1*math.Log2(0.1)) * float64(len(data)) / 8
+
+	packed, err := New().Pack(data)
+	assert.Nil(t, err)
+	assert.Less(t, float64(len(packed)), entropy*1.05, "Codec.Pack() must be close to the entropy")
+
+	prefixCoded, err := huffman.New().Pack(data)
+	assert.Nil(t, err)
+	assert.Less(t, len(packed), len(prefixCoded)*3/5, "Codec.Pack() must beat prefix codes on skewed data")
+}
+
+func TestCodecContextModels(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog, then the dog sleeps. ", 2000))
+
+	sizes := make([]int, MaxOrder+1)
+	for order := range sizes {
+		c, _ := NewOptions(Options{Order: uint(order)})
+		packed, err := c.Pack(data)
+		assert.Nil(t, err)
+		sizes[order] = len(packed)
+	}
+
+	assert.Less(t, sizes[1], sizes[0], "model of order 1 must pack text better than order 0")
+	assert.Less(t, sizes[2], sizes[1], "model of order 2 must pack text better than order 1")
+}
+
+func TestNewOptionsError(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewOptions(Options{Order: 3})
+	assert.EqualError(t, err, "arith: order 3 out of range [0, 2]")
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte("abracadabra"))
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.Arith), err)
+}
+
+func TestRegisteredFlags(t *testing.T) {
+	t.Parallel()
+
+	f, _ := compression.Lookup("arith")
+
+	fs := flag.NewFlagSet("arith", flag.ContinueOnError)
+	newWriter := f.Pack(fs)
+	assert.Nil(t, fs.Parse([]string{"-order", "2"}))
+
+	var buf bytes.Buffer
+	w, err := newWriter(&buf, compression.Header{})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("abcabcabc"))
+	assert.Nil(t, w.Close())
+	want := header(compression.Header{}, Options{Order: 2})
+	assert.Equal(t, want, buf.Bytes()[:len(want)], "the registered writer must pack with the options of the flags")
+
+	unpacked, err := io.ReadAll(f.Unpack(flag.NewFlagSet("arith", flag.ContinueOnError))(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabc"), unpacked)
+}
+
+// header returns the header of data packed with arith followed by the options.
+func header(h compression.Header, opts Options) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.Arith
+	_ = compression.WriteHeader(&buf, h)
+
+	return append(buf.Bytes(), byte(opts.Order))
+}
+
+// coded returns the range coded bytes of str and the end of the data symbol with the model of the order.
+func coded(order uint, str string) []byte {
+	m, e := newModel(order), newEncoder()
+
+	symbols := make([]int, 0, len(str)+1)
+	for i := 0; i < len(str); i++ {
+		symbols = append(symbols, int(str[i]))
+	}
+	for _, s := range append(symbols, eof) {
+		f := m.current()
+		cum, freq := f.interval(s)
+		e.encode(cum, freq, f.total)
+		m.update(s)
+	}
+	e.flush()
+
+	return e.out
+}
+
+// packed returns the data written by Codec.Pack for str: the header, the options, the range coded bytes
+// and the checksum.
+func packed(str string, opts Options, coded []byte) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))}, opts)
+	return append(append(data, coded...), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
@@ -0,0 +1,130 @@
+package arith
+
+const (
+	// eof is the symbol marking the end of the data.
+	eof        = 256
+	numSymbols = 257
+	// groupSize is the number of symbols whose frequencies are summed up together, it speeds up
+	// the lookup of cumulative frequencies.
+	groupSize = 16
+	numGroups = (numSymbols + groupSize - 1) / groupSize
+	// increment is added to the frequency of a symbol every time it is coded.
+	increment = 32
+)
+
+// frequencies are adaptive frequencies of the symbols, every symbol starts with frequency 1 and gets more
+// frequent every time it is coded. When the total exceeds maxTotal all frequencies are halved, so the recent
+// symbols weigh more than the old ones.
+type frequencies struct {
+	freq   [numSymbols]uint16
+	groups [numGroups]uint32
+	total  uint32
+}
+
+func newFrequencies() *frequencies {
+	f := &frequencies{}
+	for s := range f.freq {
+		f.freq[s] = 1
+	}
+	f.sum()
+	return f
+}
+
+// interval returns the cumulative frequency of the symbols below s and the frequency of s.
+func (f *frequencies) interval(s int) (uint32, uint32) {
+	var cum uint32
+
+	g := s / groupSize
+	for _, n := range f.groups[:g] {
+		cum += n
+	}
+	for _, n := range f.freq[g*groupSize : s] {
+		cum += uint32(n)
+	}
+
+	return cum, uint32(f.freq[s])
+}
+
+// find returns the symbol whose interval contains target, its cumulative frequency and frequency.
+func (f *frequencies) find(target uint32) (int, uint32, uint32) {
+	var (
+		cum uint32
+		s   int
+	)
+
+	for g, n := range f.groups {
+		if cum+n > target {
+			s = g * groupSize
+			break
+		}
+		cum += n
+	}
+	for cum+uint32(f.freq[s]) <= target {
+		cum += uint32(f.freq[s])
+		s++
+	}
+
+	return s, cum, uint32(f.freq[s])
+}
+
+// update makes s more frequent.
+func (f *frequencies) update(s int) {
+	f.freq[s] += increment
+	f.groups[s/groupSize] += increment
+	f.total += increment
+
+	if f.total > maxTotal {
+		for s, n := range f.freq {
+			f.freq[s] = (n + 1) / 2
+		}
+		f.sum()
+	}
+}
+
+func (f *frequencies) sum() {
+	f.groups = [numGroups]uint32{}
+	f.total = 0
+
+	for s, n := range f.freq {
+		f.groups[s/groupSize] += uint32(n)
+		f.total += uint32(n)
+	}
+}
+
+// model predicts the next symbol by the frequencies of the context: the previous order bytes.
+// The frequencies of a context are allocated when it is seen first.
+type model struct {
+	contexts []*frequencies
+	mask     uint32
+	ctx      uint32
+}
+
+func newModel(order uint) *model {
+	return &model{
+		contexts: make([]*frequencies, 1<<(8*order)),
+		mask:     1<<(8*order) - 1,
+	}
+}
+
+// current returns the frequencies of the current context.
+func (m *model) current() *frequencies {
+	f := m.contexts[m.ctx]
+	if f == nil {
+		f = newFrequencies()
+		m.contexts[m.ctx] = f
+	}
+	return f
+}
+
+// update makes s more frequent in the current context and moves to the context following s.
+func (m *model) update(s int) {
+	m.current().update(s)
+	m.ctx = (m.ctx<<8 | uint32(s)) & m.mask
+}
+
+func (m *model) reset() {
+	for i := range m.contexts {
+		m.contexts[i] = nil
+	}
+	m.ctx = 0
+}
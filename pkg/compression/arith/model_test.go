@@ -0,0 +1,106 @@
+package arith
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFrequencies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		updates []int
+		s       int
+		cum     uint32
+		freq    uint32
+		total   uint32
+	}{
+		{name: "initial frequencies", s: 'a', cum: 'a', freq: 1, total: numSymbols},
+		{name: "frequencies updated with a", updates: []int{'a'}, s: 'a', cum: 'a', freq: 1 + increment, total: numSymbols + increment},
+		{name: "frequencies updated with a", updates: []int{'a'}, s: 'b', cum: 'b' + increment, freq: 1, total: numSymbols + increment},
+		{name: "frequencies updated with eof", updates: []int{eof, eof}, s: eof, cum: 256, freq: 1 + 2*increment, total: numSymbols + 2*increment},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("interval of %d in %s", test.s, test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			f := newFrequencies()
+			for _, s := range test.updates {
+				f.update(s)
+			}
+
+			cum, freq := f.interval(test.s)
+			assert.Equal(t, test.cum, cum)
+			assert.Equal(t, test.freq, freq)
+			assert.Equal(t, test.total, f.total)
+
+			for target := cum; target < cum+freq; target++ {
+				s, c, n := f.find(target)
+				assert.Equalf(t, []uint32{uint32(test.s), cum, freq}, []uint32{uint32(s), c, n}, "find(%d)", target)
+			}
+		})
+	}
+}
+
+func TestFrequenciesRescale(t *testing.T) {
+	t.Parallel()
+
+	f := newFrequencies()
+	for i := 0; i < 3000; i++ {
+		f.update(i % 3)
+		assert.LessOrEqual(t, f.total, uint32(maxTotal), "total must never exceed maxTotal")
+	}
+
+	var total uint32
+	for s := 0; s < numSymbols; s++ {
+		_, freq := f.interval(s)
+		assert.NotZerof(t, freq, "symbol %d must stay codable", s)
+		total += freq
+	}
+	assert.Equal(t, f.total, total)
+
+	cum, freq := f.interval(eof)
+	assert.Equal(t, f.total, cum+freq)
+}
+
+func TestModelContexts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		order    uint
+		contexts int
+	}{
+		{order: 0, contexts: 1},
+		{order: 1, contexts: 3},
+		{order: 2, contexts: 4},
+	}
+
+	for _, test := range tests {
+		test := test
+		name := fmt.Sprintf("contexts of order %d after abab", test.order)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			m := newModel(test.order)
+			for _, s := range "abab" {
+				_ = m.current()
+				m.update(int(s))
+			}
+			_ = m.current()
+
+			used := 0
+			for _, f := range m.contexts {
+				if f != nil {
+					used++
+				}
+			}
+			assert.Equal(t, test.contexts, used)
+
+			m.reset()
+			assert.Equal(t, newModel(test.order), m, "model.reset() must forget all contexts")
+		})
+	}
+}
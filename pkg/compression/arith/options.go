@@ -0,0 +1,23 @@
+package arith
+
+import "fmt"
+
+// MaxOrder is the maximum order of the context model.
+const MaxOrder = 2
+
+// Options configure the model of the Writer. The Reader gets them from the packed data.
+type Options struct {
+	// Order is the number of previous bytes predicting the next one: every combination of them has its own
+	// adaptive frequencies. Higher orders give better ratio on text, but adapt slower and use more memory.
+	Order uint
+}
+
+// DefaultOptions are the options of the Writer returned by NewWriter.
+var DefaultOptions = Options{Order: 0}
+
+func (o Options) validate() error {
+	if o.Order > MaxOrder {
+		return fmt.Errorf("order %d out of range [0, %d]", o.Order, MaxOrder)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package arith
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"io"
+)
+
+const (
+	// topValue is the lower bound of the range after normalization.
+	topValue = 1 << 24
+	// maxTotal is the maximum total frequency of a model, it keeps the range divided by it at least 1<<8.
+	maxTotal = 1 << 16
+	// flushSize is the number of bytes the encoder flushes and the decoder starts with.
+	flushSize = 5
+)
+
+// encoder is a range encoder: it narrows the range [low, low+rng) to the part of every coded symbol and writes
+// the top bytes of low as soon as they are settled. A carry may still change the bytes written after the last
+// one below 0xFF, so they are kept as cache and cacheSize until it is known.
+type encoder struct {
+	low       uint64
+	rng       uint32
+	cache     byte
+	cacheSize int
+	out       []byte
+}
+
+func newEncoder() *encoder {
+	e := &encoder{}
+	e.reset()
+	return e
+}
+
+func (e *encoder) reset() {
+	e.low = 0
+	e.rng = 0xFFFFFFFF
+	e.cache = 0
+	e.cacheSize = 1
+	e.out = e.out[:0]
+}
+
+// encode codes the symbol with the cumulative frequency cum and the frequency freq of the total.
+func (e *encoder) encode(cum, freq, total uint32) {
+	r := e.rng / total
+	e.low += uint64(r) * uint64(cum)
+	e.rng = r * freq
+
+	for e.rng < topValue {
+		e.rng <<= 8
+		e.shiftLow()
+	}
+}
+
+// flush writes the rest of low, the output holds all coded symbols after it.
+func (e *encoder) flush() {
+	for i := 0; i < flushSize; i++ {
+		e.shiftLow()
+	}
+}
+
+func (e *encoder) shiftLow() {
+	if uint32(e.low) < 0xFF000000 || e.low >= 1<<32 {
+		carry := byte(e.low >> 32)
+		for b := e.cache; e.cacheSize > 0; e.cacheSize-- {
+			e.out = append(e.out, b+carry)
+			b = 0xFF
+		}
+		e.cache = byte(e.low >> 24)
+	}
+	e.cacheSize++
+	e.low = e.low & 0x00FFFFFF << 8
+}
+
+// decoder is the range decoder of the data written by encoder, code is the position in the range.
+type decoder struct {
+	r    io.ByteReader
+	code uint32
+	rng  uint32
+	// step is the range divided by the total of the last target call.
+	step uint32
+}
+
+// init starts decoding the data read from r.
+func (d *decoder) init(r io.ByteReader) error {
+	d.r = r
+	d.code = 0
+	d.rng = 0xFFFFFFFF
+
+	for i := 0; i < flushSize; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return bitio.NoEOF(err)
+		}
+		// the encoder always starts with the empty cache
+		if i == 0 && b != 0 {
+			return fmt.Errorf("%w: invalid first byte %d", ErrCorrupted, b)
+		}
+		d.code = d.code<<8 | uint32(b)
+	}
+
+	return nil
+}
+
+// target returns the cumulative frequency within the total the next symbol covers.
+func (d *decoder) target(total uint32) (uint32, error) {
+	d.step = d.rng / total
+
+	v := d.code / d.step
+	if v >= total {
+		return 0, fmt.Errorf("%w: code is out of range", ErrCorrupted)
+	}
+
+	return v, nil
+}
+
+// decode consumes the symbol with the cumulative frequency cum and the frequency freq of the total passed
+// to target.
+func (d *decoder) decode(cum, freq uint32) error {
+	d.code -= d.step * cum
+	d.rng = d.step * freq
+
+	for d.rng < topValue {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return bitio.NoEOF(err)
+		}
+		d.code = d.code<<8 | uint32(b)
+		d.rng <<= 8
+	}
+
+	return nil
+}
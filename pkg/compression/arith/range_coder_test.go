@@ -0,0 +1,121 @@
+package arith
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// symbol is a symbol coded with fixed frequencies.
+type symbol struct {
+	cum, freq, total uint32
+}
+
+// encodeSymbols returns the output of the encoder coding the symbols.
+func encodeSymbols(symbols []symbol) []byte {
+	e := newEncoder()
+	for _, s := range symbols {
+		e.encode(s.cum, s.freq, s.total)
+	}
+	e.flush()
+	return e.out
+}
+
+// randomSymbols returns n random symbols of the total.
+func randomSymbols(rnd *rand.Rand, n int, total uint32) []symbol {
+	symbols := make([]symbol, n)
+	for i := range symbols {
+		cum := rnd.Uint32() % total
+		symbols[i] = symbol{cum: cum, freq: 1 + rnd.Uint32()%(total-cum), total: total}
+	}
+	return symbols
+}
+
+// repeatSymbol returns n copies of s.
+func repeatSymbol(s symbol, n int) []symbol {
+	symbols := make([]symbol, n)
+	for i := range symbols {
+		symbols[i] = s
+	}
+	return symbols
+}
+
+func TestRangeCoder(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		name    string
+		symbols []symbol
+	}{
+		{name: "no symbols"},
+		{name: "a single symbol", symbols: []symbol{{cum: 3, freq: 2, total: 10}}},
+		{name: "random symbols", symbols: randomSymbols(rnd, 10000, 100)},
+		{name: "random symbols of the maximum total", symbols: randomSymbols(rnd, 10000, maxTotal)},
+		// the topmost symbols keep low close to the overflow, so carries propagate through the cached bytes
+		{name: "topmost symbols", symbols: repeatSymbol(symbol{cum: maxTotal - 1, freq: 1, total: maxTotal}, 1000)},
+		{name: "lowest symbols", symbols: repeatSymbol(symbol{cum: 0, freq: 1, total: maxTotal}, 1000)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("coding %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := encodeSymbols(test.symbols)
+
+			r := bytes.NewReader(data)
+			var d decoder
+			assert.Nil(t, d.init(r))
+			for i, s := range test.symbols {
+				target, err := d.target(s.total)
+				assert.Nilf(t, err, "decoder.target() of symbol %d", i)
+				assert.Truef(t, target >= s.cum && target < s.cum+s.freq, "decoder.target() of symbol %d", i)
+				assert.Nilf(t, d.decode(s.cum, s.freq), "decoder.decode() of symbol %d", i)
+			}
+			assert.Zero(t, r.Len(), "decoder must read all the data written by encoder")
+		})
+	}
+}
+
+func TestRangeCoderCarry(t *testing.T) {
+	t.Parallel()
+
+	// a long series of 0xFF bytes is settled only when the next byte is known
+	symbols := append(
+		repeatSymbol(symbol{cum: maxTotal - 1, freq: 1, total: maxTotal}, 100),
+		symbol{cum: 0, freq: 1, total: 2},
+	)
+	data := encodeSymbols(symbols)
+	assert.Contains(t, string(data), "\xff\xff\xff\xff", "the topmost symbols must produce bytes 0xFF")
+
+	var d decoder
+	assert.Nil(t, d.init(bytes.NewReader(data)))
+	for _, s := range symbols {
+		target, err := d.target(s.total)
+		assert.Nil(t, err)
+		assert.Equal(t, s.cum, target)
+		assert.Nil(t, d.decode(s.cum, s.freq))
+	}
+}
+
+func TestRangeCoderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	var d decoder
+	assert.ErrorIs(t, d.init(bytes.NewReader([]byte{0, 1})), io.ErrUnexpectedEOF)
+	assert.ErrorIs(t, d.init(bytes.NewReader([]byte{1, 0, 0, 0, 0})), ErrCorrupted)
+
+	// the code 0xFFFFFFFF is above the part of the range covered by the total of 3
+	assert.Nil(t, d.init(bytes.NewReader([]byte{0, 0xFF, 0xFF, 0xFF, 0xFF})))
+	_, err := d.target(3)
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	assert.Nil(t, d.init(bytes.NewReader([]byte{0, 0, 0, 0, 0})))
+	_, _ = d.target(maxTotal)
+	assert.ErrorIs(t, d.decode(0, 1), io.ErrUnexpectedEOF)
+}
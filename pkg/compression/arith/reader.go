@@ -0,0 +1,140 @@
+package arith
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header and the options are read and validated on the first call to Read.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	opts       Options
+	model      *model
+	dec        decoder
+	buf        []byte
+	decoded    []byte
+	read       uint64
+	crc        uint32
+	err        error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:   bitio.NewByteReader(r),
+		buf: make([]byte, 0, bufferSize),
+	}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.err = z.readHeaderOptions()
+		z.readHeader = true
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.decode(z.buf[:0])
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Reader) readHeaderOptions() error {
+	var err error
+
+	z.Header, err = compression.ReadCodecHeader(z.r, compression.Arith)
+	if err != nil {
+		return err
+	}
+
+	order, err := z.r.ReadByte()
+	if err != nil {
+		return bitio.NoEOF(err)
+	}
+	z.opts = Options{Order: uint(order)}
+	if err = z.opts.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	if z.model == nil || len(z.model.contexts) != 1<<(8*z.opts.Order) {
+		z.model = newModel(z.opts.Order)
+	} else {
+		z.model.reset()
+	}
+
+	return z.dec.init(z.r)
+}
+
+// decode decodes symbols until bufferSize bytes are decoded, appends them to dst and returns the extended slice.
+// It returns io.EOF with the rest of the data at the end of the data symbol.
+func (z *Reader) decode(dst []byte) ([]byte, error) {
+	for len(dst) < bufferSize {
+		f := z.model.current()
+		target, err := z.dec.target(f.total)
+		if err != nil {
+			return dst, err
+		}
+		s, cum, freq := f.find(target)
+		if err = z.dec.decode(cum, freq); err != nil {
+			return dst, err
+		}
+		z.model.update(s)
+
+		if s == eof {
+			z.read += uint64(len(dst))
+			z.crc = compression.UpdateChecksum(z.crc, dst)
+			if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+				return dst, fmt.Errorf("%w: unpacked %d bytes, but header size is %d", ErrCorrupted, z.read, z.Header.Size)
+			}
+			if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+				return dst, err
+			}
+			return dst, io.EOF
+		}
+
+		dst = append(dst, byte(s))
+		if z.Header.Flags&compression.FlagSize != 0 && z.read+uint64(len(dst)) > z.Header.Size {
+			return dst, fmt.Errorf("%w: unpacked data exceeds header size %d", ErrCorrupted, z.Header.Size)
+		}
+	}
+
+	z.read += uint64(len(dst))
+	z.crc = compression.UpdateChecksum(z.crc, dst)
+
+	return dst, nil
+}
@@ -0,0 +1,132 @@
+package arith
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "several buffers", data: bytes.Repeat([]byte("abracadabra"), bufferSize/11*3)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s byte by byte", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, _ := New().Pack(test.data)
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(packed)))
+			data, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, data)
+			assert.Equal(t, uint64(len(test.data)), r.Header.Size)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	abracadabra := coded(0, "abracadabra")
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "invalid options",
+			data:  append(header(compression.Header{}, Options{Order: 3}), abracadabra...),
+			err:   ErrCorrupted,
+			error: "arith: packed data is corrupted: order 3 out of range [0, 2]",
+		},
+		{
+			name:  "missing options",
+			data:  header(compression.Header{}, DefaultOptions)[:compression.HeaderSize],
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "size is less than header size",
+			data:  append(header(compression.Header{Flags: compression.FlagSize, Size: 12}, DefaultOptions), abracadabra...),
+			err:   ErrCorrupted,
+			error: "arith: packed data is corrupted: unpacked 11 bytes, but header size is 12",
+		},
+		{
+			name:  "size exceeds header size",
+			data:  append(header(compression.Header{Flags: compression.FlagSize, Size: 10}, DefaultOptions), abracadabra...),
+			err:   ErrCorrupted,
+			error: "arith: packed data is corrupted: unpacked data exceeds header size 10",
+		},
+		{
+			name:  "invalid first byte",
+			data:  append(header(compression.Header{}, DefaultOptions), 1, 0, 0, 0, 0),
+			err:   ErrCorrupted,
+			error: "arith: packed data is corrupted: invalid first byte 1",
+		},
+		{
+			name:  "code out of range",
+			data:  append(header(compression.Header{}, DefaultOptions), 0, 0xFF, 0xFF, 0xFF, 0xFF),
+			err:   ErrCorrupted,
+			error: "arith: packed data is corrupted: code is out of range",
+		},
+		{
+			name:  "truncated data",
+			data:  append(header(compression.Header{}, DefaultOptions), abracadabra[:len(abracadabra)-1]...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	packed, _ := New().Pack([]byte("abracadabra"))
+	r := NewReader(bytes.NewReader(packed))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	c, _ := NewOptions(Options{Order: 2})
+	first, _ := c.Pack([]byte("abracadabra"))
+	second, _ := New().Pack([]byte("Ted"))
+
+	r := NewReader(bytes.NewReader(first))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(second))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
@@ -0,0 +1,166 @@
+package arith
+
+import (
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// bufferSize is the number of packed bytes the Writer collects before writing them.
+const bufferSize = 4096
+
+var (
+	ErrClosed    = errors.New("arith: stream is closed")
+	ErrCorrupted = errors.New("arith: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it with a range coder and an adaptive model.
+//
+// The Header is followed by the order of the model, a byte. Then the range coded bytes of the whole data follow,
+// ended with the end of the data symbol.
+//
+// The range coder spans the whole data, so the Writer has no Flush: the data is readable only after Close.
+//
+// With FlagChecksum set in the Header, the packed data is followed by the checksum of the original data.
+//
+// The Header is written before the first packed byte, so its Size and Flags may be set until the first call
+// to Write or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           io.Writer
+	wroteHeader bool
+	opts        Options
+	model       *model
+	enc         *encoder
+	written     uint64
+	crc         uint32
+	err         error
+}
+
+// NewWriter returns a new Writer packing the data to w with DefaultOptions.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	z, _ := NewWriterOptions(w, DefaultOptions)
+	return z
+}
+
+// NewWriterOptions is like NewWriter but packs with the given options.
+//
+// It returns an error if the options are out of range.
+func NewWriterOptions(w io.Writer, opts Options) (*Writer, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("arith: %w", err)
+	}
+
+	return &Writer{
+		w:     w,
+		opts:  opts,
+		model: newModel(opts.Order),
+		enc:   newEncoder(),
+	}, nil
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	for _, b := range p {
+		z.encode(int(b))
+	}
+	z.written += uint64(len(p))
+	z.crc = compression.UpdateChecksum(z.crc, p)
+
+	if len(z.enc.out) >= bufferSize {
+		if z.err = z.writeOut(); z.err != nil {
+			return 0, z.err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close writes the rest of the packed data and the end of the data symbol to the underlying writer.
+// It does not close the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.encode(eof)
+	z.enc.flush()
+	if z.err = z.writeOut(); z.err != nil {
+		return z.err
+	}
+	if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+		return z.err
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("arith: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriterOptions(w, opts) with
+// the Writer's options.
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.model.reset()
+	z.enc.reset()
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.Arith
+	z.wroteHeader = true
+
+	if err := compression.WriteHeader(z.w, z.Header); err != nil {
+		return err
+	}
+	_, err := z.w.Write([]byte{byte(z.opts.Order)})
+
+	return err
+}
+
+// encode codes the symbol s with the frequencies of the current context and updates them.
+func (z *Writer) encode(s int) {
+	f := z.model.current()
+	cum, freq := f.interval(s)
+	z.enc.encode(cum, freq, f.total)
+	z.model.update(s)
+}
+
+// writeOut writes the packed bytes collected so far to the underlying writer.
+func (z *Writer) writeOut() error {
+	_, err := z.w.Write(z.enc.out)
+	z.enc.out = z.enc.out[:0]
+
+	return err
+}
@@ -0,0 +1,103 @@
+package arith
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+		opts      Options
+	}{
+		{str: "", partSize: 1, opts: DefaultOptions},
+		{str: "abracadabra", partSize: 1, opts: DefaultOptions},
+		{str: "abracadabra", partSize: 4, opts: Options{Order: 1}},
+		{str: string(bytes.Repeat([]byte("abracadabra"), 1000)), partSize: 3000, opts: Options{Order: 2}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %d bytes by parts of %d bytes with model of order %d", len(test.str), test.partSize, test.opts.Order)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w, err := NewWriterOptions(buf, test.opts)
+			assert.Nil(t, err)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := append(header(compression.Header{}, test.opts), coded(test.opts.Order, test.str)...)
+			assert.Equalf(t, want, buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("aaa"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "arith: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("aaa"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w, _ := NewWriterOptions(&first, Options{Order: 1})
+	_, _ = w.Write([]byte("abracadabra"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("abracadabra"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}, Options{Order: 1}), first.Bytes())
+	assert.Equal(
+		t,
+		append(header(compression.Header{}, Options{Order: 1}), coded(1, "abracadabra")...),
+		second.Bytes(),
+		"Writer.Reset() must discard the model",
+	)
+}
+
+func TestNewWriterOptionsError(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewWriterOptions(&bytes.Buffer{}, Options{Order: 5})
+	assert.Nil(t, w)
+	assert.EqualError(t, err, "arith: order 5 out of range [0, 2]")
+}
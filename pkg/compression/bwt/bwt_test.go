@@ -0,0 +1,190 @@
+package bwt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/psssix/archiver/pkg/compression/lzss"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+// banana is the block of "banana": its transform "annbaa" with primary index 4 is move-to-front encoded as
+// 97, 110, 0, 99, 2, 0 and run-length encoded as a single literal.
+var banana = block(6, 4, []byte{5, 97, 110, 0, 99, 2, 0})
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      []byte
+	}{
+		{str: "", want: packed("")},
+		{str: "banana", want: packed("banana", banana)},
+		{str: strings.Repeat("a", 200), want: packed(strings.Repeat("a", 200), block(200, 200, []byte{0, 97, 129, 0, 186, 0}))},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			bytes, err := New().Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, test.want, bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		bytes      []byte
+	}{
+		{want: "", bytes: packed("")},
+		{want: "banana", bytes: packed("banana", banana)},
+		{want: "bananabanana", bytes: packed("bananabanana", banana, banana)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q", test.want)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			unpacked, err := New().Unpack(test.bytes)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", test.bytes)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", test.bytes)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "unicode text", data: []byte("Привет, мир ∑ π")},
+		{name: "every byte value", data: allBytes},
+		{name: "long runs", data: bytes.Repeat([]byte{0}, 1000)},
+		{name: "several blocks", data: bytes.Repeat([]byte("Some pretty SUBsequence, 42!\n"), 2*blockSize/29+1)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing and unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, err := New().Pack(test.data)
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.data)
+			unpacked, err := New().Unpack(packed)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+			assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.data)
+		})
+	}
+}
+
+func TestCodecBeatsOtherCodecsOnText(t *testing.T) {
+	t.Parallel()
+
+	data := text(200000)
+
+	packed, err := New().Pack(data)
+	assert.Nil(t, err)
+
+	for _, codec := range []compression.Codec{huffman.New(), lzss.New()} {
+		other, err := codec.Pack(data)
+		assert.Nil(t, err)
+		assert.Lessf(t, len(packed), len(other), "Codec.Pack() must pack text better than %T", codec)
+	}
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte{0b00100010, 0b01101001, 0b01000000})
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.BWT), err)
+}
+
+// text returns about n bytes of text made of a small vocabulary in an irregular order.
+func text(n int) []byte {
+	words := strings.Fields("the archive of a quick brown fox jumps over lazy dogs while packing data into blocks " +
+		"and every block is sorted transformed encoded again with codes built from frequencies")
+
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < n; i++ {
+		buf.WriteString(words[(i*i+i/7)%len(words)])
+		if i%13 == 12 {
+			buf.WriteString(".\n")
+		} else {
+			buf.WriteByte(' ')
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// header returns the header of data packed with bwt.
+func header(h compression.Header) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.BWT
+	_ = compression.WriteHeader(&buf, h)
+
+	return buf.Bytes()
+}
+
+// block returns the block of the given size with the given primary index, encoded are its bytes after
+// the move-to-front transform and the run-length encoding.
+func block(size, primary int, encoded []byte) []byte {
+	buf := make([]byte, 0, 3*binary.MaxVarintLen64)
+	for _, v := range []int{size, primary, len(encoded)} {
+		buf = append(buf, make([]byte, binary.MaxVarintLen64)...)
+		buf = buf[:len(buf)-binary.MaxVarintLen64+binary.PutUvarint(buf[len(buf)-binary.MaxVarintLen64:], uint64(v))]
+	}
+
+	data := bytes.NewBuffer(buf)
+	w := bitio.NewBitWriter(data)
+	_ = huffman.Encode(w, encoded)
+	_ = w.Flush()
+
+	return data.Bytes()
+}
+
+// endMark is the block marking the end of the packed data.
+var endMark = []byte{0}
+
+// packed returns the data written by Codec.Pack for str: the header, the blocks, the end mark and the checksum.
+func packed(str string, blocks ...[]byte) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))})
+	for _, b := range blocks {
+		data = append(data, b...)
+	}
+	return append(append(data, endMark...), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
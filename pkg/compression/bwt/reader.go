@@ -0,0 +1,155 @@
+package bwt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/psssix/archiver/pkg/compression/mtf"
+	"github.com/psssix/archiver/pkg/compression/rle"
+	"io"
+)
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header is read and validated on the first call to Read.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	br         *bitio.BitReader
+	buf        []byte
+	encoded    []byte
+	decoded    []byte
+	read       uint64
+	crc        uint32
+	err        error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	br := bitio.NewByteReader(r)
+
+	return &Reader{
+		r:  br,
+		br: bitio.NewBitReader(br),
+	}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.Header, z.err = compression.ReadCodecHeader(z.r, compression.BWT)
+		z.readHeader = true
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.readBlock()
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.br.Reset(z.r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+// readBlock reads and decodes the next block, it returns io.EOF at the end of the data mark.
+func (z *Reader) readBlock() ([]byte, error) {
+	size, err := binary.ReadUvarint(z.br)
+	if err != nil {
+		return nil, bitio.NoEOF(err)
+	}
+
+	if size == 0 {
+		if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+			return nil, fmt.Errorf("%w: unpacked %d bytes, but header size is %d", ErrCorrupted, z.read, z.Header.Size)
+		}
+		if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if size > blockSize {
+		return nil, fmt.Errorf("%w: block of %d bytes is too long", ErrCorrupted, size)
+	}
+
+	primary, err := binary.ReadUvarint(z.br)
+	if err != nil {
+		return nil, bitio.NoEOF(err)
+	}
+	if primary > size {
+		return nil, fmt.Errorf("%w: primary index %d is out of range", ErrCorrupted, primary)
+	}
+	// run-length encoding grows the data by at most a byte per literal of 128 bytes
+	encodedSize, err := binary.ReadUvarint(z.br)
+	if err != nil {
+		return nil, bitio.NoEOF(err)
+	}
+	if encodedSize > size+size/128+1 {
+		return nil, fmt.Errorf("%w: encoded block of %d bytes is too long", ErrCorrupted, encodedSize)
+	}
+
+	z.encoded, err = huffman.Decode(z.br, int(encodedSize), z.encoded[:0])
+	if err != nil {
+		return nil, stageError(bitio.NoEOF(err))
+	}
+	z.br.Align()
+
+	z.buf, err = rle.Decode(z.buf[:0], z.encoded)
+	if err != nil {
+		return nil, stageError(err)
+	}
+	if uint64(len(z.buf)) != size {
+		return nil, fmt.Errorf("%w: block of %d bytes decoded to %d bytes", ErrCorrupted, size, len(z.buf))
+	}
+	z.encoded = mtf.Decode(z.encoded[:0], z.buf)
+	z.buf, err = Inverse(z.buf[:0], z.encoded, int(primary))
+	if err != nil {
+		return nil, err
+	}
+
+	z.read += size
+	if z.Header.Flags&compression.FlagSize != 0 && z.read > z.Header.Size {
+		return nil, fmt.Errorf("%w: unpacked data exceeds header size %d", ErrCorrupted, z.Header.Size)
+	}
+
+	z.crc = compression.UpdateChecksum(z.crc, z.buf)
+
+	return z.buf, nil
+}
+
+// stageError reports corrupted data found by a stage of the pipeline as corrupted data of the stream.
+func stageError(err error) error {
+	if errors.Is(err, huffman.ErrCorrupted) || errors.Is(err, rle.ErrCorrupted) {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package bwt
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "several blocks", data: text(blockSize * 2)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s byte by byte", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, _ := New().Pack(test.data)
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(packed)))
+			data, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, data)
+			assert.Equal(t, uint64(len(test.data)), r.Header.Size)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	encoded := []byte{5, 97, 110, 0, 99, 2, 0}
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "size is less than header size",
+			data:  append(append(header(compression.Header{Flags: compression.FlagSize, Size: 7}), banana...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: unpacked 6 bytes, but header size is 7",
+		},
+		{
+			name:  "size exceeds header size",
+			data:  append(append(header(compression.Header{Flags: compression.FlagSize, Size: 5}), banana...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: unpacked data exceeds header size 5",
+		},
+		{
+			name:  "too long block",
+			data:  append(append(header(compression.Header{}), block(blockSize+1, 4, encoded)...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: block of 921601 bytes is too long",
+		},
+		{
+			name:  "primary index out of range",
+			data:  append(append(header(compression.Header{}), block(6, 7, encoded)...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: primary index 7 is out of range",
+		},
+		{
+			name:  "invalid transform",
+			data:  append(append(header(compression.Header{}), block(6, 1, encoded)...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: invalid transform",
+		},
+		{
+			name:  "too long encoded block",
+			data:  append(append(header(compression.Header{}), block(6, 4, bytes.Repeat(encoded, 2))...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: encoded block of 14 bytes is too long",
+		},
+		{
+			name:  "block size mismatch",
+			data:  append(append(header(compression.Header{}), block(7, 4, encoded)...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: block of 7 bytes decoded to 6 bytes",
+		},
+		{
+			name:  "truncated literal",
+			data:  append(append(header(compression.Header{}), block(6, 4, encoded[:6])...), endMark...),
+			err:   ErrCorrupted,
+			error: "bwt: packed data is corrupted: rle: packed data is corrupted: unexpected EOF",
+		},
+		{
+			name:  "truncated block",
+			data:  append(header(compression.Header{}), banana[:len(banana)-1]...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "missing end mark",
+			data:  append(header(compression.Header{}), banana...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	packed, _ := New().Pack([]byte("banana"))
+	r := NewReader(bytes.NewReader(packed))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	first, _ := New().Pack([]byte("banana"))
+	second, _ := New().Pack([]byte("Ted"))
+
+	r := NewReader(bytes.NewReader(first))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(second))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
@@ -0,0 +1,151 @@
+package bwt
+
+// suffixArray returns the starting positions of the suffixes of s in lexicographical order, a suffix that is
+// a prefix of another one goes first. The values of s must be in [0, upper].
+//
+// It implements the SA-IS algorithm by Nong, Zhang and Chan, which sorts the suffixes in linear time: it sorts
+// the LMS substrings by induced sorting, names them by rank, recursively sorts the suffixes of the string of
+// names and induces the order of all suffixes from the sorted LMS suffixes.
+func suffixArray(s []int32, upper int32) []int32 {
+	n := len(s)
+	switch n {
+	case 0:
+		return []int32{}
+	case 1:
+		return []int32{0}
+	case 2:
+		if s[0] < s[1] {
+			return []int32{0, 1}
+		}
+		return []int32{1, 0}
+	}
+
+	sa := make([]int32, n)
+
+	// ls[i] is true if the suffix i is an S-type suffix, that is less than the suffix i+1.
+	ls := make([]bool, n)
+	for i := n - 2; i >= 0; i-- {
+		if s[i] == s[i+1] {
+			ls[i] = ls[i+1]
+		} else {
+			ls[i] = s[i] < s[i+1]
+		}
+	}
+
+	// sumL[c] is the start of the bucket of c, sumS[c] is the start of the S-type suffixes in the bucket.
+	sumL := make([]int32, upper+2)
+	sumS := make([]int32, upper+2)
+	for i := 0; i < n; i++ {
+		if !ls[i] {
+			sumS[s[i]]++
+		} else {
+			sumL[s[i]+1]++
+		}
+	}
+	for c := int32(0); c <= upper; c++ {
+		sumS[c] += sumL[c]
+		if c < upper {
+			sumL[c+1] += sumS[c]
+		}
+	}
+
+	buf := make([]int32, upper+1)
+	induce := func(lms []int32) {
+		for i := range sa {
+			sa[i] = -1
+		}
+
+		copy(buf, sumS)
+		for _, d := range lms {
+			if int(d) == n {
+				continue
+			}
+			sa[buf[s[d]]] = d
+			buf[s[d]]++
+		}
+
+		copy(buf, sumL)
+		sa[buf[s[n-1]]] = int32(n - 1)
+		buf[s[n-1]]++
+		for i := 0; i < n; i++ {
+			v := sa[i]
+			if v >= 1 && !ls[v-1] {
+				sa[buf[s[v-1]]] = v - 1
+				buf[s[v-1]]++
+			}
+		}
+
+		copy(buf, sumL)
+		for i := n - 1; i >= 0; i-- {
+			v := sa[i]
+			if v >= 1 && ls[v-1] {
+				buf[s[v-1]+1]--
+				sa[buf[s[v-1]+1]] = v - 1
+			}
+		}
+	}
+
+	// lmsMap maps the position of a leftmost S-type suffix to its index in lms.
+	lmsMap := make([]int32, n+1)
+	for i := range lmsMap {
+		lmsMap[i] = -1
+	}
+	var lms []int32
+	for i := 1; i < n; i++ {
+		if !ls[i-1] && ls[i] {
+			lmsMap[i] = int32(len(lms))
+			lms = append(lms, int32(i))
+		}
+	}
+	m := len(lms)
+
+	induce(lms)
+
+	if m == 0 {
+		return sa
+	}
+
+	sortedLMS := make([]int32, 0, m)
+	for _, v := range sa {
+		if lmsMap[v] != -1 {
+			sortedLMS = append(sortedLMS, v)
+		}
+	}
+
+	recS := make([]int32, m)
+	var recUpper int32
+	recS[lmsMap[sortedLMS[0]]] = 0
+	for i := 1; i < m; i++ {
+		l, r := sortedLMS[i-1], sortedLMS[i]
+		endL, endR := int32(n), int32(n)
+		if int(lmsMap[l])+1 < m {
+			endL = lms[lmsMap[l]+1]
+		}
+		if int(lmsMap[r])+1 < m {
+			endR = lms[lmsMap[r]+1]
+		}
+
+		same := endL-l == endR-r
+		if same {
+			for l < endL && s[l] == s[r] {
+				l++
+				r++
+			}
+			if int(l) == n || s[l] != s[r] {
+				same = false
+			}
+		}
+		if !same {
+			recUpper++
+		}
+		recS[lmsMap[sortedLMS[i]]] = recUpper
+	}
+
+	recSA := suffixArray(recS, recUpper)
+	for i, v := range recSA {
+		sortedLMS[i] = lms[v]
+	}
+	induce(sortedLMS)
+
+	return sa
+}
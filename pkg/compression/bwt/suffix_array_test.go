@@ -0,0 +1,78 @@
+package bwt
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// naiveSuffixArray sorts the suffixes of s by comparing them.
+func naiveSuffixArray(s []int32) []int32 {
+	sa := make([]int32, len(s))
+	for i := range sa {
+		sa[i] = int32(i)
+	}
+	sort.Slice(sa, func(i, j int) bool {
+		a, b := s[sa[i]:], s[sa[j]:]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return sa
+}
+
+func toSymbols(str string) []int32 {
+	s := make([]int32, len(str))
+	for i := 0; i < len(str); i++ {
+		s[i] = int32(str[i])
+	}
+	return s
+}
+
+func TestSuffixArray(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      []int32
+	}{
+		{str: "", want: []int32{}},
+		{str: "a", want: []int32{0}},
+		{str: "ba", want: []int32{1, 0}},
+		{str: "aaa", want: []int32{2, 1, 0}},
+		{str: "banana", want: []int32{5, 3, 1, 0, 4, 2}},
+		{str: "abracadabra", want: []int32{10, 7, 0, 3, 5, 8, 1, 4, 6, 9, 2}},
+		{str: "mmiissiissiippii", want: naiveSuffixArray(toSymbols("mmiissiissiippii"))},
+		{str: strings.Repeat("ab", 50), want: naiveSuffixArray(toSymbols(strings.Repeat("ab", 50)))},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("suffix array of %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, test.want, suffixArray(toSymbols(test.str), 255), "suffixArray(%v)", test.str)
+		})
+	}
+}
+
+func TestSuffixArrayRandom(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, upper := range []int32{0, 1, 3, 255} {
+		for i := 0; i < 200; i++ {
+			s := make([]int32, rnd.Intn(300))
+			for j := range s {
+				s[j] = rnd.Int31n(upper + 1)
+			}
+			assert.Equalf(t, naiveSuffixArray(s), suffixArray(s, upper), "suffixArray(%v)", s)
+		}
+	}
+}
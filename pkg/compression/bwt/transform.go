@@ -0,0 +1,89 @@
+package bwt
+
+import "fmt"
+
+// Transform appends the Burrows–Wheeler transform of src to dst and returns the extended slice and the primary
+// index needed to reverse it.
+//
+// The transform is the last column of the sorted rotations of src followed by a sentinel byte less than any
+// other. The sentinel itself is not appended, the primary index is its row, that is the row of src itself.
+// Rotations are sorted as suffixes of src, so the transform takes linear time.
+func Transform(dst, src []byte) ([]byte, int) {
+	if len(src) == 0 {
+		return dst, 0
+	}
+
+	s := make([]int32, len(src))
+	for i, b := range src {
+		s[i] = int32(b)
+	}
+
+	// the row 0 is the rotation starting with the sentinel, its last byte is the last byte of src
+	dst = append(dst, src[len(src)-1])
+	primary := 0
+	for i, p := range suffixArray(s, 255) {
+		if p == 0 {
+			primary = i + 1
+			continue
+		}
+		dst = append(dst, src[p-1])
+	}
+
+	return dst, primary
+}
+
+// Inverse appends the data transformed by Transform to dst and returns the extended slice.
+//
+// It fails with ErrCorrupted if the primary index is out of range or src is not a valid transform with it.
+func Inverse(dst, src []byte, primary int) ([]byte, error) {
+	n := len(src)
+	if n == 0 && primary == 0 {
+		return dst, nil
+	}
+	if primary < 1 || primary > n {
+		return dst, fmt.Errorf("%w: primary index %d is out of range", ErrCorrupted, primary)
+	}
+
+	// next[i] is the row starting with the i-th byte of src: rows are ordered by their first byte,
+	// the row 0 starts with the sentinel and equal bytes keep their order
+	var first [256]int32
+	for _, b := range src {
+		first[b]++
+	}
+	row := int32(1)
+	for b, count := range first {
+		first[b] = row
+		row += count
+	}
+	next := make([]int32, n)
+	for i, b := range src {
+		next[i] = first[b]
+		first[b]++
+	}
+
+	start := len(dst)
+	for i := 0; i < n; i++ {
+		dst = append(dst, 0)
+	}
+	out := dst[start:]
+
+	// walk from the row of the sentinel backwards through the data, the row with the sentinel at the end
+	// is the last one to reach
+	row = 0
+	for k := n - 1; k >= 0; k-- {
+		i := row
+		switch {
+		case int(row) == primary:
+			return dst[:start], fmt.Errorf("%w: invalid transform", ErrCorrupted)
+		case int(row) > primary:
+			i--
+		}
+		out[k] = src[i]
+		row = next[i]
+	}
+	if int(row) != primary {
+		return dst[:start], fmt.Errorf("%w: invalid transform", ErrCorrupted)
+	}
+
+	return dst, nil
+}
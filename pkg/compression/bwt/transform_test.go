@@ -0,0 +1,81 @@
+package bwt
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, want string
+		primary         int
+	}{
+		{str: "", want: "", primary: 0},
+		{str: "a", want: "a", primary: 1},
+		{str: "aaa", want: "aaa", primary: 3},
+		{str: "banana", want: "annbaa", primary: 4},
+		{str: "abracadabra", want: "ardrcaaaabb", primary: 3},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("transform %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, primary := Transform([]byte{}, []byte(test.str))
+			assert.Equalf(t, test.want, string(got), "Transform(%v)", test.str)
+			assert.Equalf(t, test.primary, primary, "Transform(%v)", test.str)
+
+			inverse, err := Inverse(nil, got, primary)
+			assert.Nilf(t, err, "Inverse(%v, %d)", got, primary)
+			assert.Equalf(t, test.str, string(inverse), "Inverse(%v, %d)", got, primary)
+		})
+	}
+}
+
+func TestInverseReversesTransform(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, alphabet := range []int{1, 2, 4, 256} {
+		data := make([]byte, 5000)
+		for i := range data {
+			data[i] = byte(rnd.Intn(alphabet))
+		}
+
+		transformed, primary := Transform([]byte("x"), data)
+		got, err := Inverse([]byte("y"), transformed[1:], primary)
+		assert.Nil(t, err)
+		assert.Equal(t, append([]byte("y"), data...), got, "Inverse() must reverse Transform() and append to dst")
+	}
+}
+
+func TestInverseInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		primary   int
+	}{
+		{str: "", primary: 1},
+		{str: "annbaa", primary: 0},
+		{str: "annbaa", primary: 7},
+		// the walk from the sentinel row returns to it after 2 bytes
+		{str: "ab", primary: 1},
+		{str: "annbaa", primary: 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("inverse of invalid transform %q with primary index %d", test.str, test.primary)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Inverse(nil, []byte(test.str), test.primary)
+			assert.ErrorIsf(t, err, ErrCorrupted, "Inverse(%v, %d)", test.str, test.primary)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package bwt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/psssix/archiver/pkg/compression/mtf"
+	"github.com/psssix/archiver/pkg/compression/rle"
+	"io"
+)
+
+// blockSize is the number of bytes transformed together, larger blocks give better ratio and use more memory.
+const blockSize = 900 * 1024
+
+var (
+	ErrClosed    = errors.New("bwt: stream is closed")
+	ErrCorrupted = errors.New("bwt: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it the way bzip2 does.
+//
+// The data is packed by blocks of up to blockSize bytes. Every block goes through the Burrows–Wheeler
+// transform, the move-to-front transform, the run-length encoding and Huffman coding, and is written as
+// the number of bytes in the block, the primary index of the transform and the number of run-length encoded
+// bytes as uvarints, followed by the output of huffman.Encode padded to whole bytes. An empty block marks
+// the end of the data.
+//
+// With FlagChecksum set in the Header, the packed data is followed by the checksum of the original data.
+//
+// The Header is written before the first block, so its Size and Flags may be set until the first call
+// to Write, Flush or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           io.Writer
+	wroteHeader bool
+	bw          *bitio.BitWriter
+	block       []byte
+	transformed []byte
+	encoded     []byte
+	written     uint64
+	crc         uint32
+	err         error
+}
+
+// NewWriter returns a new Writer packing the data to w.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:     w,
+		bw:    bitio.NewBitWriter(w),
+		block: make([]byte, 0, blockSize),
+	}
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	for written := 0; written < len(p); {
+		n := copy(z.block[len(z.block):cap(z.block)], p[written:])
+		z.block = z.block[:len(z.block)+n]
+
+		if len(z.block) == cap(z.block) {
+			if z.err = z.writeBlock(); z.err != nil {
+				return written, z.err
+			}
+		}
+
+		z.crc = compression.UpdateChecksum(z.crc, p[written:written+n])
+		written += n
+		z.written += uint64(n)
+	}
+
+	return len(p), nil
+}
+
+// Flush packs all the data written so far as a block and writes it to the underlying writer.
+//
+// Blocks are transformed independently, so flushing often makes the ratio worse.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.err = z.writeBlock()
+	return z.err
+}
+
+// Close writes the rest of the packed data and the end of the data mark to the underlying writer.
+// It does not close the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeUvarint(0); z.err != nil {
+		return z.err
+	}
+	if z.err = z.bw.Flush(); z.err != nil {
+		return z.err
+	}
+	if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+		return z.err
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("bwt: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriter(w).
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.bw.Reset(w)
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.block = z.block[:0]
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.BWT
+	z.wroteHeader = true
+
+	return compression.WriteHeader(z.w, z.Header)
+}
+
+// writeBlock packs the data collected since the previous block as a block, if any.
+func (z *Writer) writeBlock() error {
+	if len(z.block) == 0 {
+		return nil
+	}
+
+	var primary int
+	z.transformed, primary = Transform(z.transformed[:0], z.block)
+	z.encoded = mtf.Encode(z.encoded[:0], z.transformed)
+	z.transformed = rle.Encode(z.transformed[:0], z.encoded)
+
+	for _, v := range []int{len(z.block), primary, len(z.transformed)} {
+		if err := z.writeUvarint(uint64(v)); err != nil {
+			return err
+		}
+	}
+	if err := huffman.Encode(z.bw, z.transformed); err != nil {
+		return err
+	}
+	if err := z.bw.Flush(); err != nil {
+		return err
+	}
+
+	z.block = z.block[:0]
+
+	return nil
+}
+
+func (z *Writer) writeUvarint(v uint64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	for _, b := range buf[:binary.PutUvarint(buf, v)] {
+		if err := z.bw.WriteBits(uint64(b), 8); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
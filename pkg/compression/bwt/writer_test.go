@@ -0,0 +1,109 @@
+package bwt
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+		blocks    [][]byte
+	}{
+		{str: "", partSize: 1},
+		{str: "banana", partSize: 1, blocks: [][]byte{banana}},
+		{str: "banana", partSize: 4, blocks: [][]byte{banana}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewWriter(buf)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := header(compression.Header{})
+			for _, b := range test.blocks {
+				want = append(want, b...)
+			}
+			assert.Equalf(t, append(want, endMark...), buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	_, _ = w.Write([]byte("banana"))
+	assert.Nil(t, w.Flush())
+	want := append(header(compression.Header{}), banana...)
+	assert.Equal(t, want, buf.Bytes(), "Writer.Flush() must write all packed data as a block")
+
+	_, _ = w.Write([]byte("banana"))
+	assert.Nil(t, w.Close())
+	want = append(want, banana...)
+	assert.Equal(t, append(want, endMark...), buf.Bytes())
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("aaa"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "bwt: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("aaa"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("abracadabra"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("banana"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}), first.Bytes())
+	assert.Equal(t, append(append(header(compression.Header{}), banana...), endMark...), second.Bytes())
+}
@@ -0,0 +1,67 @@
+// Package canonical implements canonical prefix codes: codes fully described by their lengths.
+//
+// Codes of the same length are consecutive numbers assigned to symbols in ascending order,
+// shorter codes go first, so a code table is stored and shipped as the lengths only and is rebuilt
+// the same way on every side.
+package canonical
+
+import (
+	"errors"
+	"sort"
+)
+
+// MaxLen is the maximum length of a code.
+const MaxLen = 32
+
+var ErrInvalid = errors.New("canonical: invalid code lengths")
+
+type (
+	// Code is a binary code of Len bits stored as the lowest bits of Bits, the first bit is the most significant one.
+	Code struct {
+		Bits uint64
+		Len  uint
+	}
+	// Lengths are lengths of codes of every byte value, zero for the bytes without a code.
+	Lengths [256]uint8
+	// Codes are codes of every byte value.
+	Codes [256]Code
+)
+
+// Codes assigns canonical codes to the lengths.
+//
+// It returns ErrInvalid if a length exceeds MaxLen or the lengths do not form a prefix code.
+func (l *Lengths) Codes() (Codes, error) {
+	var c Codes
+
+	symbols := make([]int, 0, len(l))
+	for s, n := range l {
+		if n != 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return l[symbols[i]] < l[symbols[j]]
+	})
+
+	var (
+		next uint64
+		prev uint
+	)
+	for _, s := range symbols {
+		n := uint(l[s])
+		if n > MaxLen {
+			return Codes{}, ErrInvalid
+		}
+
+		next <<= n - prev
+		prev = n
+		if next>>n != 0 {
+			return Codes{}, ErrInvalid
+		}
+
+		c[s] = Code{Bits: next, Len: n}
+		next++
+	}
+
+	return c, nil
+}
@@ -0,0 +1,93 @@
+package canonical
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLengthsCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		lengths map[byte]uint8
+		want    map[byte]Code
+	}{
+		{name: "no codes", lengths: map[byte]uint8{}, want: map[byte]Code{}},
+		{name: "single code", lengths: map[byte]uint8{'a': 1}, want: map[byte]Code{'a': {Bits: 0, Len: 1}}},
+		{
+			name:    "abracadabra",
+			lengths: map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4},
+			want: map[byte]Code{
+				'a': {Bits: 0b0, Len: 1},
+				'r': {Bits: 0b10, Len: 2},
+				'b': {Bits: 0b110, Len: 3},
+				'c': {Bits: 0b1110, Len: 4},
+				'd': {Bits: 0b1111, Len: 4},
+			},
+		},
+		{
+			name:    "equal lengths",
+			lengths: map[byte]uint8{'d': 2, 'c': 2, 'b': 2, 'a': 2},
+			want: map[byte]Code{
+				'a': {Bits: 0b00, Len: 2},
+				'b': {Bits: 0b01, Len: 2},
+				'c': {Bits: 0b10, Len: 2},
+				'd': {Bits: 0b11, Len: 2},
+			},
+		},
+		{
+			name:    "longest codes",
+			lengths: map[byte]uint8{0: 1, 1: MaxLen, 2: MaxLen},
+			want: map[byte]Code{
+				0: {Bits: 0, Len: 1},
+				1: {Bits: 1 << (MaxLen - 1), Len: MaxLen},
+				2: {Bits: 1<<(MaxLen-1) + 1, Len: MaxLen},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("assign canonical codes for %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var want Codes
+			for b, c := range test.want {
+				want[b] = c
+			}
+			lengths := toLengths(test.lengths)
+
+			got, err := lengths.Codes()
+			assert.Nil(t, err)
+			assert.Equalf(t, want, got, "Lengths(%v).Codes()", test.lengths)
+		})
+	}
+}
+
+func TestLengthsCodesInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		lengths map[byte]uint8
+	}{
+		{name: "too many short codes", lengths: map[byte]uint8{'a': 1, 'b': 1, 'c': 1}},
+		{name: "oversubscribed code", lengths: map[byte]uint8{'a': 1, 'b': 2, 'c': 2, 'd': 2}},
+		{name: "too long code", lengths: map[byte]uint8{'a': 1, 'b': MaxLen + 1}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("reject lengths with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			lengths := toLengths(test.lengths)
+
+			got, err := lengths.Codes()
+			assert.ErrorIs(t, err, ErrInvalid)
+			assert.Equalf(t, Codes{}, got, "Lengths(%v).Codes() not empty result when invalid", test.lengths)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package canonical
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"math/bits"
+)
+
+const (
+	// widthBits is the number of bits storing the width of every length.
+	widthBits = 3
+	// runBits is the number of bits storing the length of a run of symbols without codes.
+	runBits = 8
+)
+
+// Encode writes the lengths to w compactly, so that a table of a few used symbols takes a few bytes.
+//
+// Layout: the width of a length in bits (3 bits), zero for no codes at all. Then, until every byte value
+// is covered, either bit 1 followed by the length of the next symbol's code, or bit 0 followed by the number
+// of the next symbols without codes minus one (8 bits).
+func Encode(w *bitio.BitWriter, l *Lengths) error {
+	var maxLen uint8
+	for _, n := range l {
+		if n > maxLen {
+			maxLen = n
+		}
+	}
+	if maxLen > MaxLen {
+		return ErrInvalid
+	}
+
+	width := uint(bits.Len8(maxLen))
+	if err := w.WriteBits(uint64(width), widthBits); err != nil {
+		return err
+	}
+	if width == 0 {
+		return nil
+	}
+
+	for s := 0; s < len(l); {
+		if l[s] != 0 {
+			if err := w.WriteBits(1<<width|uint64(l[s]), 1+width); err != nil {
+				return err
+			}
+			s++
+			continue
+		}
+
+		run := 1
+		for s+run < len(l) && l[s+run] == 0 && run < 1<<runBits {
+			run++
+		}
+		if err := w.WriteBits(uint64(run-1), 1+runBits); err != nil {
+			return err
+		}
+		s += run
+	}
+
+	return nil
+}
+
+// Decode reads the lengths written by Encode from r.
+//
+// It returns an error wrapping ErrInvalid if the lengths are malformed, but does not check they form
+// a prefix code: Lengths.Codes does.
+func Decode(r *bitio.BitReader) (Lengths, error) {
+	var l Lengths
+
+	width, err := r.ReadBits(widthBits)
+	if err != nil {
+		return Lengths{}, err
+	}
+	if width == 0 {
+		return l, nil
+	}
+	if width > uint64(bits.Len(MaxLen)) {
+		return Lengths{}, fmt.Errorf("%w: length width %d", ErrInvalid, width)
+	}
+
+	for s := 0; s < len(l); {
+		used, err := r.ReadBit()
+		if err != nil {
+			return Lengths{}, bitio.NoEOF(err)
+		}
+
+		if !used {
+			run, err := r.ReadBits(runBits)
+			if err != nil {
+				return Lengths{}, bitio.NoEOF(err)
+			}
+			s += int(run) + 1
+			if s > len(l) {
+				return Lengths{}, fmt.Errorf("%w: run of symbols without codes exceeds byte values", ErrInvalid)
+			}
+			continue
+		}
+
+		n, err := r.ReadBits(uint(width))
+		if err != nil {
+			return Lengths{}, bitio.NoEOF(err)
+		}
+		if n == 0 || n > MaxLen {
+			return Lengths{}, fmt.Errorf("%w: code length %d", ErrInvalid, n)
+		}
+		l[s] = uint8(n)
+		s++
+	}
+
+	return l, nil
+}
@@ -0,0 +1,121 @@
+package canonical
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		lengths map[byte]uint8
+		want    string
+	}{
+		{name: "no codes", lengths: map[byte]uint8{}, want: "000"},
+		{
+			name:    "single code",
+			lengths: map[byte]uint8{'a': 1},
+			want:    "001" + "001100000" + "11" + "010011101",
+		},
+		{
+			name:    "abracadabra",
+			lengths: map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4},
+			want:    "011" + "001100000" + "1001" + "1011" + "1100" + "1100" + "000001100" + "1010" + "010001100",
+		},
+		{
+			name:    "last byte value",
+			lengths: map[byte]uint8{0: 1, 255: 32},
+			want:    "110" + "1000001" + "011111101" + "1100000",
+		},
+		{name: "every byte value", lengths: allLengths(8), want: "100" + strings.Repeat("11000", 256)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encoding lengths of %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			lengths := toLengths(test.lengths)
+
+			var buf bytes.Buffer
+			w := bitio.NewBitWriter(&buf)
+			assert.Nil(t, Encode(w, &lengths))
+			assert.Equalf(t, uint64(len(test.want)), w.Count(), "Encode(%v) written bits", test.lengths)
+			assert.Nil(t, w.Flush())
+			assert.Equalf(t, fromBitString(test.want), buf.Bytes(), "Encode(%v)", test.lengths)
+
+			decoded, err := Decode(bitio.NewBitReader(&buf))
+			assert.Nilf(t, err, "Decode(Encode(%v)) unexpected error", test.lengths)
+			assert.Equalf(t, lengths, decoded, "Decode(Encode(%v))", test.lengths)
+		})
+	}
+}
+
+func TestEncodeTooLongCode(t *testing.T) {
+	t.Parallel()
+
+	lengths := toLengths(map[byte]uint8{'a': MaxLen + 1})
+	assert.ErrorIs(t, Encode(bitio.NewBitWriter(io.Discard), &lengths), ErrInvalid)
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, bString string
+		err           error
+	}{
+		{name: "no data", bString: "", err: io.EOF},
+		{name: "truncated lengths", bString: "011" + "001100000" + "1001", err: io.ErrUnexpectedEOF},
+		{name: "truncated run", bString: "011" + "0011", err: io.ErrUnexpectedEOF},
+		{name: "zero length", bString: "011" + "1000", err: ErrInvalid},
+		{name: "too long code", bString: "110" + "1100001", err: ErrInvalid},
+		{name: "too wide lengths", bString: "111" + "10000001", err: ErrInvalid},
+		{name: "too long run", bString: "011" + "1001" + "011111111", err: ErrInvalid},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding lengths with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			lengths, err := Decode(bitio.NewBitReader(bytes.NewReader(fromBitString(test.bString))))
+			assert.ErrorIsf(t, err, test.err, "Decode(%v) unexpected error", test.bString)
+			assert.Equalf(t, Lengths{}, lengths, "Decode(%v) not empty result when error", test.bString)
+		})
+	}
+}
+
+func toLengths(m map[byte]uint8) Lengths {
+	var l Lengths
+	for b, n := range m {
+		l[b] = n
+	}
+	return l
+}
+
+func allLengths(n uint8) map[byte]uint8 {
+	m := make(map[byte]uint8, 256)
+	for b := 0; b < 256; b++ {
+		m[byte(b)] = n
+	}
+	return m
+}
+
+// fromBitString packs a string of '0' and '1' to bytes, the last byte is padded with zeros.
+func fromBitString(bString string) []byte {
+	data := make([]byte, (len(bString)+7)/8)
+	for i, c := range bString {
+		if c == '1' {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return data
+}
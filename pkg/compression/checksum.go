@@ -0,0 +1,80 @@
+package compression
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ChecksumSize is the size of the checksum following the packed data.
+const ChecksumSize = 4
+
+var ErrChecksum = errors.New("compression: checksum mismatch")
+
+// ChecksumError reports the checksum of the unpacked data differing from the one recorded when it was packed,
+// it is both ErrChecksum and the corruption error of the codec for errors.Is.
+type ChecksumError struct {
+	corrupted        error
+	unpacked, packed uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf(
+		"%v: %v: unpacked data has %08x, but packed data has %08x", e.corrupted, ErrChecksum, e.unpacked, e.packed,
+	)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return e.corrupted
+}
+
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrChecksum
+}
+
+// UpdateChecksum returns the checksum of the original data extended with p, the checksum of no data is 0.
+//
+// The checksum is CRC-32 with the IEEE polynomial, the same as the checksum of gzip.
+func UpdateChecksum(crc uint32, p []byte) uint32 {
+	return crc32.Update(crc, crc32.IEEETable, p)
+}
+
+// WriteChecksum writes the checksum of the original data to w if the header has FlagChecksum set.
+//
+// Layout: the checksum (4 bytes, big endian) right after the end of the packed data.
+func WriteChecksum(w io.Writer, h Header, crc uint32) error {
+	if h.Flags&FlagChecksum == 0 {
+		return nil
+	}
+
+	buf := make([]byte, ChecksumSize)
+	binary.BigEndian.PutUint32(buf, crc)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadChecksum reads the checksum written by WriteChecksum from r if the header has FlagChecksum set,
+// it returns a *ChecksumError wrapping corrupted, the corruption error of the codec, if it differs
+// from the checksum of the unpacked data.
+//
+// It returns io.ErrUnexpectedEOF if the checksum is truncated.
+func ReadChecksum(r io.Reader, h Header, crc uint32, corrupted error) error {
+	if h.Flags&FlagChecksum == 0 {
+		return nil
+	}
+
+	buf := make([]byte, ChecksumSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if want := binary.BigEndian.Uint32(buf); want != crc {
+		return &ChecksumError{corrupted: corrupted, unpacked: crc, packed: want}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+var errCorrupted = errors.New("test: corrupted")
+
+func TestUpdateChecksum(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("the quick brown fox jumps over the lazy dog")
+
+	crc := UpdateChecksum(0, data[:10])
+	crc = UpdateChecksum(crc, data[10:])
+	assert.Equal(t, crc32.ChecksumIEEE(data), crc, "the checksum must be updated by parts")
+	assert.Zero(t, UpdateChecksum(0, nil))
+}
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header Header
+		want   []byte
+	}{
+		{name: "without checksum", header: Header{Flags: FlagSize}},
+		{name: "with checksum", header: Header{Flags: FlagChecksum}, want: []byte{0x01, 0x02, 0x03, 0x04}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("write and read checksum %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			assert.Nil(t, WriteChecksum(&buf, test.header, 0x01020304))
+			assert.Equal(t, test.want, buf.Bytes())
+
+			r := bytes.NewReader(append(buf.Bytes(), 0xff))
+			assert.Nil(t, ReadChecksum(r, test.header, 0x01020304, errCorrupted))
+			assert.Equal(t, 1, r.Len(), "ReadChecksum() must read the checksum only")
+		})
+	}
+}
+
+func TestReadChecksumError(t *testing.T) {
+	t.Parallel()
+
+	h := Header{Flags: FlagChecksum}
+
+	err := ReadChecksum(bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04}), h, 0x01020305, errCorrupted)
+	assert.ErrorIs(t, err, ErrChecksum)
+	assert.ErrorIs(t, err, errCorrupted)
+	assert.EqualError(
+		t, err, "test: corrupted: compression: checksum mismatch: unpacked data has 01020305, but packed data has 01020304",
+	)
+
+	assert.ErrorIs(t, ReadChecksum(bytes.NewReader(nil), h, 0, errCorrupted), io.ErrUnexpectedEOF)
+	assert.ErrorIs(t, ReadChecksum(bytes.NewReader([]byte{0x01, 0x02}), h, 0, errCorrupted), io.ErrUnexpectedEOF)
+
+	errRead := errors.New("read error")
+	assert.ErrorIs(t, ReadChecksum(iotest.ErrReader(errRead), h, 0, errCorrupted), errRead)
+}
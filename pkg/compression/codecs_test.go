@@ -0,0 +1,126 @@
+package compression_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	_ "github.com/psssix/archiver/pkg/compression/ans"
+	_ "github.com/psssix/archiver/pkg/compression/arith"
+	_ "github.com/psssix/archiver/pkg/compression/bwt"
+	_ "github.com/psssix/archiver/pkg/compression/gzip"
+	_ "github.com/psssix/archiver/pkg/compression/huffman"
+	_ "github.com/psssix/archiver/pkg/compression/lzss"
+	_ "github.com/psssix/archiver/pkg/compression/lzw"
+	_ "github.com/psssix/archiver/pkg/compression/rle"
+	_ "github.com/psssix/archiver/pkg/compression/std"
+	_ "github.com/psssix/archiver/pkg/compression/vlc"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+// pack packs the data with the registered codec and its default options, recording the size and the checksum.
+func pack(t *testing.T, f compression.Factory, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := f.Pack(flag.NewFlagSet("pack", flag.ContinueOnError))(
+		&buf, compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(data))},
+	)
+	assert.Nil(t, err)
+	_, err = w.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	return buf.Bytes()
+}
+
+// unpack unpacks the data with the registered codec and its default options.
+func unpack(f compression.Factory, data []byte) ([]byte, error) {
+	r := f.Unpack(flag.NewFlagSet("unpack", flag.ContinueOnError))(bytes.NewReader(data))
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func TestRegistered(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, ext  string
+		magic      []byte
+		unpackOnly bool
+	}{
+		{name: "ans", ext: "ans", magic: compression.HeaderMagic(compression.ANS)},
+		{name: "arith", ext: "arith", magic: compression.HeaderMagic(compression.Arith)},
+		{name: "bwt", ext: "bwt", magic: compression.HeaderMagic(compression.BWT)},
+		{name: "bzip2", ext: "bz2", magic: []byte("BZh"), unpackOnly: true},
+		{name: "compress", ext: "Z", magic: []byte{0x1f, 0x9d}, unpackOnly: true},
+		{name: "gzip", ext: "gz", magic: []byte{0x1f, 0x8b}},
+		{name: "huffman", ext: "huff", magic: compression.HeaderMagic(compression.Huffman)},
+		{name: "lzss", ext: "lzss", magic: compression.HeaderMagic(compression.LZSS)},
+		{name: "lzw", ext: "lzw", magic: compression.HeaderMagic(compression.LZW)},
+		{name: "rle", ext: "rle", magic: compression.HeaderMagic(compression.RLE)},
+		{name: "vlc", ext: "vlc", magic: compression.HeaderMagic(compression.VLC)},
+		// the zlib header has no fixed signature, it is matched by its check bits
+		{name: "zlib", ext: "zlib", unpackOnly: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("registered %s", test.name), func(t *testing.T) {
+			t.Parallel()
+
+			f, ok := compression.Lookup(test.name)
+			assert.True(t, ok, "the codec must be registered")
+			assert.Equal(t, test.ext, f.Extension)
+			assert.Equal(t, test.magic, f.Magic)
+			assert.NotNil(t, f.Unpack, "the codec must unpack")
+			if test.unpackOnly {
+				assert.Nil(t, f.Pack, "the codec must be only unpacked")
+				return
+			}
+
+			data := pack(t, f, []byte("abcabcabc"))
+			detected, err := compression.Detect(data)
+			assert.Nil(t, err)
+			assert.Equal(t, test.name, detected, "the packed data must be detected as packed with the codec")
+
+			unpacked, err := unpack(f, data)
+			assert.Nil(t, err)
+			assert.Equal(t, []byte("abcabcabc"), unpacked)
+		})
+	}
+}
+
+func TestCodecsChecksum(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range compression.List() {
+		f, _ := compression.Lookup(name)
+		// only the codecs writing the header of this module write the checksum of compression.WriteChecksum
+		if f.Pack == nil || !bytes.HasPrefix(f.Magic, []byte(compression.Magic)) {
+			continue
+		}
+
+		name, f := name, f
+		t.Run(fmt.Sprintf("checksum of %s", name), func(t *testing.T) {
+			t.Parallel()
+
+			data := pack(t, f, []byte("abracadabra"))
+			end := len(data) - compression.ChecksumSize
+
+			damaged := append(data[:end:end], data[end]^0xff, data[end+1], data[end+2], data[end+3])
+			_, err := unpack(f, damaged)
+			assert.ErrorIs(t, err, compression.ErrChecksum)
+			var checksumErr *compression.ChecksumError
+			assert.True(t, errors.As(err, &checksumErr), "Reader must fail with *compression.ChecksumError")
+			assert.NotNil(t, errors.Unwrap(err), "Reader must wrap the corruption error of the codec")
+
+			_, err = unpack(f, data[:end+2])
+			assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "Reader must fail on truncated checksum")
+		})
+	}
+}
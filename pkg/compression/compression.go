@@ -1,14 +1,93 @@
 package compression
 
+import (
+	"bytes"
+	"io"
+)
+
 type Codec interface {
 	Packer
 	Unpacker
 }
 
 type Packer interface {
-	Pack(string) ([]byte, error)
+	Pack([]byte) ([]byte, error)
 }
 
 type Unpacker interface {
-	Unpack([]byte) (string, error)
+	Unpack([]byte) ([]byte, error)
+}
+
+// PackString packs text with the given packer.
+func PackString(p Packer, str string) ([]byte, error) {
+	return p.Pack([]byte(str))
+}
+
+// UnpackString unpacks data with the given unpacker and returns the result as text.
+func UnpackString(u Unpacker, data []byte) (string, error) {
+	unpacked, err := u.Unpack(data)
+	if err != nil {
+		return "", err
+	}
+	return string(unpacked), nil
+}
+
+// PackWith packs data with the writer returned by newWriter, the Header records the size and the checksum
+// of the data, so they are verified when it is unpacked. It is the Pack method of the codecs of this module.
+func PackWith(newWriter WriterFunc, data []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+
+	w, err := newWriter(buf, Header{Flags: FlagSize | FlagChecksum, Size: uint64(len(data))})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnpackWith unpacks data with the reader returned by newReader. It is the Unpack method of the codecs
+// of this module.
+func UnpackWith(newReader ReaderFunc, data []byte) ([]byte, error) {
+	r := newReader(bytes.NewReader(data))
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// Chain returns the Codec packing the data with every codec in turn, so the first codecs pre-transform the data
+// for the next ones. It unpacks the data with the codecs in reverse order.
+func Chain(codecs ...Codec) Codec {
+	return chain(codecs)
+}
+
+type chain []Codec
+
+func (c chain) Pack(data []byte) ([]byte, error) {
+	var err error
+
+	for _, codec := range c {
+		if data, err = codec.Pack(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+func (c chain) Unpack(data []byte) ([]byte, error) {
+	var err error
+
+	for i := len(c) - 1; i >= 0; i-- {
+		if data, err = c[i].Unpack(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
 }
@@ -0,0 +1,104 @@
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+// affixCodec packs the data wrapping it with its affix and unpacks the data unwrapping it.
+type affixCodec string
+
+func (c affixCodec) Pack(data []byte) ([]byte, error) {
+	return []byte(string(c) + string(data) + string(c)), nil
+}
+
+func (c affixCodec) Unpack(data []byte) ([]byte, error) {
+	str := string(data)
+	if !strings.HasPrefix(str, string(c)) || !strings.HasSuffix(str, string(c)) {
+		return nil, fmt.Errorf("%q is not wrapped with %q", str, string(c))
+	}
+	return []byte(str[len(c) : len(str)-len(c)]), nil
+}
+
+type failingCodec struct{}
+
+var errFailing = errors.New("failing codec")
+
+func (failingCodec) Pack([]byte) ([]byte, error) { return nil, errFailing }
+
+func (failingCodec) Unpack([]byte) ([]byte, error) { return nil, errFailing }
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, want string
+		codecs          []Codec
+	}{
+		{name: "no codecs", str: "Ted", want: "Ted"},
+		{name: "single codec", str: "Ted", want: "(Ted(", codecs: []Codec{affixCodec("(")}},
+		{name: "several codecs", str: "Ted", want: "[(Ted([", codecs: []Codec{affixCodec("("), affixCodec("[")}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing and unpacking %q with %s", test.str, test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c := Chain(test.codecs...)
+
+			packed, err := PackString(c, test.str)
+			assert.Nil(t, err)
+			assert.Equal(t, []byte(test.want), packed)
+
+			unpacked, err := UnpackString(c, packed)
+			assert.Nil(t, err)
+			assert.Equal(t, test.str, unpacked, "Chain(...).Unpack() must apply codecs in reverse order")
+		})
+	}
+}
+
+func TestChainError(t *testing.T) {
+	t.Parallel()
+
+	c := Chain(affixCodec("("), failingCodec{})
+
+	packed, err := c.Pack([]byte("Ted"))
+	assert.Nil(t, packed)
+	assert.ErrorIs(t, err, errFailing)
+
+	unpacked, err := c.Unpack([]byte("(Ted("))
+	assert.Nil(t, unpacked)
+	assert.ErrorIs(t, err, errFailing)
+}
+
+func TestPackWith(t *testing.T) {
+	t.Parallel()
+
+	var header Header
+	packed, err := PackWith(func(w io.Writer, h Header) (io.WriteCloser, error) {
+		header = h
+		return nopWriteCloser{w}, nil
+	}, []byte("Ted"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), packed)
+	assert.Equal(t, Header{Flags: FlagSize | FlagChecksum, Size: 3}, header)
+
+	packed, err = PackWith(func(io.Writer, Header) (io.WriteCloser, error) {
+		return nil, errFailing
+	}, []byte("Ted"))
+	assert.Nil(t, packed)
+	assert.ErrorIs(t, err, errFailing)
+}
+
+func TestUnpackWith(t *testing.T) {
+	t.Parallel()
+
+	unpacked, err := UnpackWith(io.NopCloser, []byte("Ted"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), unpacked)
+}
@@ -0,0 +1,46 @@
+package deflate
+
+import "io"
+
+// bitWriter packs bits into bytes starting with the least significant bit, as RFC 1951 requires.
+// It collects the bytes in memory, so writing never fails.
+type bitWriter struct {
+	out  []byte
+	bits uint64
+	n    uint
+}
+
+// writeBits writes the n lowest bits of v, n must not exceed 32.
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.bits |= uint64(v) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.out = append(w.out, byte(w.bits))
+		w.bits >>= 8
+		w.n -= 8
+	}
+}
+
+func (w *bitWriter) writeCode(c code) {
+	w.writeBits(uint32(c.bits), uint(c.len))
+}
+
+// align pads the written bits with zeros to a whole byte.
+func (w *bitWriter) align() {
+	if w.n > 0 {
+		w.writeBits(0, 8-w.n)
+	}
+}
+
+// writeTo writes the whole bytes written so far to dst and forgets them, the bits of an incomplete byte are kept.
+func (w *bitWriter) writeTo(dst io.Writer) error {
+	_, err := dst.Write(w.out)
+	w.out = w.out[:0]
+	return err
+}
+
+func (w *bitWriter) reset() {
+	w.out = w.out[:0]
+	w.bits = 0
+	w.n = 0
+}
@@ -0,0 +1,31 @@
+package deflate
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBitWriter(t *testing.T) {
+	t.Parallel()
+
+	var bw bitWriter
+	bw.writeBits(1, 1)
+	bw.writeBits(0b10, 2)
+	bw.writeBits(0x1F, 5)
+	bw.writeBits(0xABCD, 16)
+	bw.writeBits(0b101, 3)
+	assert.Equal(t, []byte{0b11111101, 0xCD, 0xAB}, bw.out, "bits must be packed starting with the least significant one")
+
+	bw.align()
+	assert.Equal(t, []byte{0b11111101, 0xCD, 0xAB, 0b101}, bw.out)
+
+	var buf bytes.Buffer
+	bw.writeBits(0b11, 2)
+	assert.Nil(t, bw.writeTo(&buf))
+	assert.Equal(t, []byte{0b11111101, 0xCD, 0xAB, 0b101}, buf.Bytes())
+	assert.Empty(t, bw.out)
+
+	bw.writeBits(0, 6)
+	assert.Equal(t, []byte{0b11}, bw.out, "bitWriter.writeTo() must keep the bits of an incomplete byte")
+}
@@ -0,0 +1,121 @@
+package deflate
+
+// Block types as written in the BTYPE field of the block header.
+const (
+	stored  = 0
+	fixed   = 1
+	dynamic = 2
+)
+
+// maxStored is the maximum number of bytes in a stored block.
+const maxStored = 1<<16 - 1
+
+// writeBlock writes the tokens of raw as the cheapest of the stored, fixed and dynamic Huffman blocks.
+// A final block has its BFINAL bit set.
+func writeBlock(bw *bitWriter, tokens []token, raw []byte, final bool) {
+	var (
+		litFreq  = make([]uint32, numLitLen)
+		distFreq = make([]uint32, numDist)
+		extra    int
+	)
+	for _, t := range tokens {
+		if t.dist == 0 {
+			litFreq[t.length]++
+			continue
+		}
+		lc, dc := lengthCodes[t.length], distCode(t.dist)
+		litFreq[257+int(lc)]++
+		distFreq[dc]++
+		extra += int(lengthExtra[lc]) + int(distExtra[dc])
+	}
+	litFreq[endOfBlock] = 1
+
+	litLen := buildLengths(litFreq, maxCodeLen)
+	dist := buildLengths(distFreq, maxCodeLen)
+	h := newDynamicHeader(litLen, dist)
+
+	var (
+		storedBits  = storedSize(len(raw))
+		fixedBits   = 3 + extra + codedSize(litFreq, fixedLitLen) + codedSize(distFreq, fixedDist)
+		dynamicBits = 3 + h.size() + extra + codedSize(litFreq, litLen) + codedSize(distFreq, dist)
+	)
+
+	switch {
+	case storedBits <= fixedBits && storedBits <= dynamicBits:
+		writeStored(bw, raw, final)
+	case fixedBits <= dynamicBits:
+		writeHeader(bw, fixed, final)
+		writeTokens(bw, tokens, fixedLitLenCodes, fixedDistCodes)
+	default:
+		writeHeader(bw, dynamic, final)
+		h.write(bw)
+		writeTokens(bw, tokens, canonicalCodes(litLen), canonicalCodes(dist))
+	}
+}
+
+func writeHeader(bw *bitWriter, blockType uint32, final bool) {
+	if final {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(blockType, 2)
+}
+
+// writeStored writes raw as stored blocks, as many as needed for raw to fit.
+func writeStored(bw *bitWriter, raw []byte, final bool) {
+	for {
+		n := len(raw)
+		if n > maxStored {
+			n = maxStored
+		}
+
+		writeHeader(bw, stored, final && n == len(raw))
+		bw.align()
+		bw.writeBits(uint32(n), 16)
+		bw.writeBits(uint32(^uint16(n)), 16)
+		bw.out = append(bw.out, raw[:n]...)
+
+		raw = raw[n:]
+		if len(raw) == 0 {
+			return
+		}
+	}
+}
+
+// writeTokens writes the codes of the tokens followed by the end of block code.
+func writeTokens(bw *bitWriter, tokens []token, litLen, dist []code) {
+	for _, t := range tokens {
+		if t.dist == 0 {
+			bw.writeCode(litLen[t.length])
+			continue
+		}
+
+		lc := lengthCodes[t.length]
+		bw.writeCode(litLen[257+int(lc)])
+		bw.writeBits(uint32(t.length-lengthBase[lc]), uint(lengthExtra[lc]))
+
+		dc := distCode(t.dist)
+		bw.writeCode(dist[dc])
+		bw.writeBits(uint32(t.dist-distBase[dc]), uint(distExtra[dc]))
+	}
+	bw.writeCode(litLen[endOfBlock])
+}
+
+// storedSize returns the number of bits of n bytes written as stored blocks, assuming the worst padding.
+func storedSize(n int) int {
+	blocks := (n + maxStored - 1) / maxStored
+	if blocks == 0 {
+		blocks = 1
+	}
+	return blocks*(3+7+32) + 8*n
+}
+
+// codedSize returns the number of bits of the codes of the symbols with the frequencies.
+func codedSize(freq []uint32, lengths []uint8) int {
+	size := 0
+	for s, f := range freq {
+		size += int(f) * int(lengths[s])
+	}
+	return size
+}
@@ -0,0 +1,99 @@
+package deflate
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestWriteBlock(t *testing.T) {
+	t.Parallel()
+
+	random := make([]byte, maxStored)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		blockType uint8
+	}{
+		{name: "no data", data: []byte{}, blockType: fixed},
+		{name: "short text", data: []byte("abcabcabc"), blockType: fixed},
+		{name: "log lines", data: logLines(500), blockType: dynamic},
+		{name: "random data", data: random, blockType: stored},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("writing %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var bw bitWriter
+			writeBlock(&bw, newMatcher().tokens(nil, test.data, 0), test.data, true)
+			bw.align()
+
+			assert.Equal(t, byte(1), bw.out[0]&1, "the final block must have BFINAL set")
+			assert.Equal(t, test.blockType, bw.out[0]>>1&3, "the cheapest block type must be chosen")
+
+			unpacked, err := io.ReadAll(flate.NewReader(bytes.NewReader(bw.out)))
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, unpacked)
+		})
+	}
+}
+
+func TestWriteBlockNoData(t *testing.T) {
+	t.Parallel()
+
+	var bw bitWriter
+	writeBlock(&bw, nil, nil, true)
+	bw.align()
+
+	// BFINAL, the fixed block type and the 7 bits of the end of block code
+	assert.Equal(t, []byte{0x03, 0x00}, bw.out)
+}
+
+func TestWriteStored(t *testing.T) {
+	t.Parallel()
+
+	var bw bitWriter
+	bw.writeBits(1, 1)
+	writeStored(&bw, []byte("abc"), false)
+
+	assert.Equal(t, []byte{0x01, 0x03, 0x00, 0xFC, 0xFF, 'a', 'b', 'c'}, bw.out)
+}
+
+func TestWriteStoredSplits(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 2*maxStored+1)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	var bw bitWriter
+	writeStored(&bw, data, true)
+
+	assert.Equal(t, len(data)+3*5, len(bw.out), "every stored block must have a header of 5 bytes")
+	unpacked, err := io.ReadAll(flate.NewReader(bytes.NewReader(bw.out)))
+	assert.Nil(t, err)
+	assert.Equal(t, data, unpacked)
+}
+
+// logLines returns n lines looking like a real log.
+func logLines(n int) []byte {
+	levels := []string{"INFO", "WARN", "ERROR", "DEBUG"}
+	rnd := rand.New(rand.NewSource(1))
+
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(
+			&buf, "2022-05-%02d 12:%02d:%02d [%s] request %d handled by worker %d in %dms\n",
+			1+i/1000, i/60%60, i%60, levels[rnd.Intn(len(levels))], i, rnd.Intn(8), rnd.Intn(500),
+		)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,99 @@
+package deflate
+
+import "math/bits"
+
+const (
+	windowSize = 1 << 15
+	minMatch   = 3
+	maxMatch   = 258
+
+	endOfBlock = 256
+	// numLitLen is the number of literal/length codes, the codes 286 and 287 are never used.
+	numLitLen = 286
+	numDist   = 30
+	// numCodeLen is the number of code length codes describing the trees of a dynamic block.
+	numCodeLen = 19
+
+	maxCodeLen     = 15
+	maxCodeLenCode = 7
+)
+
+// token is a literal byte, when dist is 0, or a match of length bytes at distance dist back.
+type token struct {
+	length uint16
+	dist   uint16
+}
+
+var (
+	lengthBase = [...]uint16{
+		3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 15, 17, 19, 23, 27, 31,
+		35, 43, 51, 59, 67, 83, 99, 115, 131, 163, 195, 227, 258,
+	}
+	lengthExtra = [...]uint8{
+		0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2,
+		3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 0,
+	}
+	distBase = [...]uint16{
+		1, 2, 3, 4, 5, 7, 9, 13, 17, 25, 33, 49, 65, 97, 129, 193,
+		257, 385, 513, 769, 1025, 1537, 2049, 3073, 4097, 6145, 8193, 12289, 16385, 24577,
+	}
+	distExtra = [...]uint8{
+		0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6,
+		7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13,
+	}
+	// codeLenOrder is the order the lengths of the code length codes are written in.
+	codeLenOrder = [numCodeLen]uint8{16, 17, 18, 0, 8, 7, 9, 6, 10, 5, 11, 4, 12, 3, 13, 2, 14, 1, 15}
+
+	// lengthCodes maps a match length to the index of its length code above 257.
+	lengthCodes [maxMatch + 1]uint8
+
+	fixedLitLen      = fixedLitLenLengths()
+	fixedDist        = fixedDistLengths()
+	fixedLitLenCodes = canonicalCodes(fixedLitLen)
+	fixedDistCodes   = canonicalCodes(fixedDist)
+)
+
+func init() {
+	for code, base := range lengthBase {
+		for l := int(base); l < int(base)+1<<lengthExtra[code] && l <= maxMatch; l++ {
+			lengthCodes[l] = uint8(code)
+		}
+	}
+}
+
+// distCode returns the index of the distance code of dist: past the first four codes every two codes
+// cover the distances with the same number of bits.
+func distCode(dist uint16) uint8 {
+	if dist <= 4 {
+		return uint8(dist - 1)
+	}
+
+	d := dist - 1
+	n := bits.Len16(d) - 1
+	return uint8(2*n) + uint8(d>>(n-1)&1)
+}
+
+func fixedLitLenLengths() []uint8 {
+	lengths := make([]uint8, 288)
+	for s := range lengths {
+		switch {
+		case s < 144:
+			lengths[s] = 8
+		case s < 256:
+			lengths[s] = 9
+		case s < 280:
+			lengths[s] = 7
+		default:
+			lengths[s] = 8
+		}
+	}
+	return lengths
+}
+
+func fixedDistLengths() []uint8 {
+	lengths := make([]uint8, numDist)
+	for s := range lengths {
+		lengths[s] = 5
+	}
+	return lengths
+}
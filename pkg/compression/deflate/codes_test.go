@@ -0,0 +1,80 @@
+package deflate
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLengthCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		length uint16
+		code   uint8
+	}{
+		{length: 3, code: 0},
+		{length: 10, code: 7},
+		{length: 11, code: 8},
+		{length: 12, code: 8},
+		{length: 13, code: 9},
+		{length: 227, code: 27},
+		{length: 257, code: 27},
+		{length: 258, code: 28},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("coding length %d", test.length), func(t *testing.T) {
+			t.Parallel()
+			code := lengthCodes[test.length]
+			assert.Equal(t, test.code, code)
+			assert.LessOrEqual(t, lengthBase[code], test.length)
+			assert.Less(t, int(test.length-lengthBase[code]), 1<<lengthExtra[code]|1)
+		})
+	}
+}
+
+func TestDistCode(t *testing.T) {
+	t.Parallel()
+
+	// every distance is covered by the base and the extra bits of its code
+	for dist := 1; dist <= windowSize; dist++ {
+		code := distCode(uint16(dist))
+		if !assert.Less(t, int(code), numDist) {
+			return
+		}
+		base := int(distBase[code])
+		if !assert.Truef(t, dist >= base && dist < base+1<<distExtra[code], "distCode(%d) = %d", dist, code) {
+			return
+		}
+	}
+}
+
+func TestFixedCodes(t *testing.T) {
+	t.Parallel()
+
+	// the fixed codes as listed by RFC 1951
+	tests := []struct {
+		symbol int
+		bits   uint16
+		len    uint8
+	}{
+		{symbol: 0, bits: 0b00110000, len: 8},
+		{symbol: 143, bits: 0b10111111, len: 8},
+		{symbol: 144, bits: 0b110010000, len: 9},
+		{symbol: 255, bits: 0b111111111, len: 9},
+		{symbol: 256, bits: 0b0000000, len: 7},
+		{symbol: 279, bits: 0b0010111, len: 7},
+		{symbol: 280, bits: 0b11000000, len: 8},
+		{symbol: 287, bits: 0b11000111, len: 8},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("fixed code of symbol %d", test.symbol), func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, code{bits: reverse(test.bits, test.len), len: test.len}, fixedLitLenCodes[test.symbol])
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package deflate
+
+// Code length symbols repeating the lengths.
+const (
+	// repeatPrevious repeats the previous length 3-6 times, 2 extra bits.
+	repeatPrevious = 16
+	// repeatZero repeats the zero length 3-10 times, 3 extra bits.
+	repeatZero = 17
+	// repeatZeroLong repeats the zero length 11-138 times, 7 extra bits.
+	repeatZeroLong = 18
+)
+
+// codeLenSymbol is a code length symbol with the value of its extra bits.
+type codeLenSymbol struct {
+	symbol, extra uint8
+}
+
+// dynamicHeader describes the codes of a dynamic Huffman block: the lengths of the literal/length
+// and distance codes are run-length encoded and coded with the code length code.
+type dynamicHeader struct {
+	numLitLen, numDist, numCodeLen int
+	symbols                        []codeLenSymbol
+	codeLen                        []uint8
+}
+
+func newDynamicHeader(litLen, dist []uint8) *dynamicHeader {
+	h := &dynamicHeader{numLitLen: trimZeros(litLen, 257), numDist: trimZeros(dist, 1)}
+
+	lengths := make([]uint8, 0, h.numLitLen+h.numDist)
+	lengths = append(lengths, litLen[:h.numLitLen]...)
+	lengths = append(lengths, dist[:h.numDist]...)
+	h.symbols = runLengths(lengths)
+
+	freq := make([]uint32, numCodeLen)
+	for _, s := range h.symbols {
+		freq[s.symbol]++
+	}
+	h.codeLen = buildLengths(freq, maxCodeLenCode)
+
+	h.numCodeLen = numCodeLen
+	for h.numCodeLen > 4 && h.codeLen[codeLenOrder[h.numCodeLen-1]] == 0 {
+		h.numCodeLen--
+	}
+
+	return h
+}
+
+// size returns the number of bits of the header.
+func (h *dynamicHeader) size() int {
+	size := 5 + 5 + 4 + 3*h.numCodeLen
+	for _, s := range h.symbols {
+		size += int(h.codeLen[s.symbol]) + int(extraBits(s.symbol))
+	}
+	return size
+}
+
+func (h *dynamicHeader) write(bw *bitWriter) {
+	bw.writeBits(uint32(h.numLitLen-257), 5)
+	bw.writeBits(uint32(h.numDist-1), 5)
+	bw.writeBits(uint32(h.numCodeLen-4), 4)
+	for _, s := range codeLenOrder[:h.numCodeLen] {
+		bw.writeBits(uint32(h.codeLen[s]), 3)
+	}
+
+	codes := canonicalCodes(h.codeLen)
+	for _, s := range h.symbols {
+		bw.writeCode(codes[s.symbol])
+		bw.writeBits(uint32(s.extra), extraBits(s.symbol))
+	}
+}
+
+// trimZeros returns the number of the lengths without the trailing zeros, but not less than min.
+func trimZeros(lengths []uint8, min int) int {
+	n := len(lengths)
+	for n > min && lengths[n-1] == 0 {
+		n--
+	}
+	return n
+}
+
+// runLengths returns the code length symbols of the lengths.
+func runLengths(lengths []uint8) []codeLenSymbol {
+	var symbols []codeLenSymbol
+
+	for i := 0; i < len(lengths); {
+		l, run := lengths[i], 1
+		for i+run < len(lengths) && lengths[i+run] == l {
+			run++
+		}
+		i += run
+
+		if l == 0 {
+			for ; run >= 11; run -= repeatCount(run, 138) {
+				symbols = append(symbols, codeLenSymbol{repeatZeroLong, uint8(repeatCount(run, 138) - 11)})
+			}
+			if run >= 3 {
+				symbols = append(symbols, codeLenSymbol{repeatZero, uint8(run - 3)})
+				run = 0
+			}
+		} else {
+			symbols = append(symbols, codeLenSymbol{symbol: l})
+			run--
+			for ; run >= 3; run -= repeatCount(run, 6) {
+				symbols = append(symbols, codeLenSymbol{repeatPrevious, uint8(repeatCount(run, 6) - 3)})
+			}
+		}
+
+		for ; run > 0; run-- {
+			symbols = append(symbols, codeLenSymbol{symbol: l})
+		}
+	}
+
+	return symbols
+}
+
+// repeatCount returns the run limited to max repeats.
+func repeatCount(run, max int) int {
+	if run > max {
+		return max
+	}
+	return run
+}
+
+func extraBits(symbol uint8) uint {
+	switch symbol {
+	case repeatPrevious:
+		return 2
+	case repeatZero:
+		return 3
+	case repeatZeroLong:
+		return 7
+	}
+	return 0
+}
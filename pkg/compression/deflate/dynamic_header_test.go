@@ -0,0 +1,60 @@
+package deflate
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRunLengths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		lengths []uint8
+		want    []codeLenSymbol
+	}{
+		{name: "short runs", lengths: []uint8{5, 5, 0, 0, 7}, want: []codeLenSymbol{{5, 0}, {5, 0}, {0, 0}, {0, 0}, {7, 0}}},
+		{name: "repeated length", lengths: []uint8{5, 5, 5, 5, 5}, want: []codeLenSymbol{{5, 0}, {repeatPrevious, 1}}},
+		{
+			name:    "long repeated length",
+			lengths: []uint8{4, 4, 4, 4, 4, 4, 4, 4, 4, 4},
+			want:    []codeLenSymbol{{4, 0}, {repeatPrevious, 3}, {repeatPrevious, 0}},
+		},
+		{
+			name:    "repeated length left",
+			lengths: []uint8{4, 4, 4, 4, 4, 4, 4, 4, 4},
+			want:    []codeLenSymbol{{4, 0}, {repeatPrevious, 3}, {4, 0}, {4, 0}},
+		},
+		{name: "zeros", lengths: make([]uint8, 10), want: []codeLenSymbol{{repeatZero, 7}}},
+		{name: "many zeros", lengths: make([]uint8, 11), want: []codeLenSymbol{{repeatZeroLong, 0}}},
+		{name: "too many zeros", lengths: make([]uint8, 150), want: []codeLenSymbol{{repeatZeroLong, 127}, {repeatZeroLong, 1}}},
+		{name: "zeros left", lengths: make([]uint8, 140), want: []codeLenSymbol{{repeatZeroLong, 127}, {0, 0}, {0, 0}}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("run-length encoding %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, runLengths(test.lengths))
+		})
+	}
+}
+
+func TestDynamicHeader(t *testing.T) {
+	t.Parallel()
+
+	litLen := make([]uint8, numLitLen)
+	litLen['a'], litLen['b'], litLen[endOfBlock] = 1, 2, 2
+	dist := make([]uint8, numDist)
+
+	h := newDynamicHeader(litLen, dist)
+	assert.Equal(t, 257, h.numLitLen, "trailing unused length codes must be omitted")
+	assert.Equal(t, 1, h.numDist, "a single distance code must be written")
+
+	var bw bitWriter
+	h.write(&bw)
+	bw.align()
+	assert.Equal(t, (h.size()+7)/8, len(bw.out), "dynamicHeader.size() must count the written bits")
+}
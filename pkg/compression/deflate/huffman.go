@@ -0,0 +1,139 @@
+package deflate
+
+import "sort"
+
+// buildLengths builds a prefix code for the frequencies with codes of at most limit bits
+// and returns the lengths of its codes, unused symbols get no code.
+//
+// Every code gets at least two used symbols, so it is complete: when fewer symbols are used, the first unused
+// symbols are taken as well. When the optimal code is too long the frequencies are halved until it fits.
+func buildLengths(freq []uint32, limit uint8) []uint8 {
+	weights := make([]uint32, len(freq))
+	copy(weights, freq)
+
+	used := 0
+	for _, f := range weights {
+		if f != 0 {
+			used++
+		}
+	}
+	for s := 0; used < 2; s++ {
+		if weights[s] == 0 {
+			weights[s] = 1
+			used++
+		}
+	}
+
+	for {
+		lengths := huffmanLengths(weights)
+
+		fits := true
+		for _, l := range lengths {
+			if l > limit {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return lengths
+		}
+
+		for s, f := range weights {
+			if f != 0 {
+				weights[s] = f>>1 | 1
+			}
+		}
+	}
+}
+
+// huffmanLengths returns the lengths of the optimal prefix code for the weights, at least two must be non-zero.
+//
+// The leaves sorted by their weights and the merged nodes, that are created in the order of their weights,
+// form two queues, so the two lightest nodes are always at their heads.
+func huffmanLengths(weights []uint32) []uint8 {
+	var leaves []int
+	for s, w := range weights {
+		if w != 0 {
+			leaves = append(leaves, s)
+		}
+	}
+	sort.SliceStable(leaves, func(i, j int) bool { return weights[leaves[i]] < weights[leaves[j]] })
+
+	// the nodes are the leaves followed by the merged nodes, the last of them is the root
+	n := len(leaves)
+	weight := make([]uint64, n, 2*n-1)
+	parent := make([]int, 2*n-1)
+	for i, s := range leaves {
+		weight[i] = uint64(weights[s])
+	}
+
+	nextLeaf, nextMerged := 0, n
+	lightest := func() int {
+		if nextLeaf < n && (nextMerged == len(weight) || weight[nextLeaf] <= weight[nextMerged]) {
+			nextLeaf++
+			return nextLeaf - 1
+		}
+		nextMerged++
+		return nextMerged - 1
+	}
+	for len(weight) < 2*n-1 {
+		a, b := lightest(), lightest()
+		parent[a], parent[b] = len(weight), len(weight)
+		weight = append(weight, weight[a]+weight[b])
+	}
+
+	depth := make([]uint8, 2*n-1)
+	for i := 2*n - 3; i >= 0; i-- {
+		depth[i] = depth[parent[i]] + 1
+	}
+
+	lengths := make([]uint8, len(weights))
+	for i, s := range leaves {
+		lengths[s] = depth[i]
+	}
+
+	return lengths
+}
+
+// code is a Huffman code with its bits reversed, so it can be written starting with the least significant bit.
+type code struct {
+	bits uint16
+	len  uint8
+}
+
+// canonicalCodes returns the canonical codes with the lengths as defined by RFC 1951: shorter codes
+// lexicographically precede longer ones and codes of the same length follow the order of their symbols.
+func canonicalCodes(lengths []uint8) []code {
+	var count [maxCodeLen + 1]uint16
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+
+	var next [maxCodeLen + 1]uint16
+	for l, c := 1, uint16(0); l <= maxCodeLen; l++ {
+		c = (c + count[l-1]) << 1
+		next[l] = c
+	}
+
+	codes := make([]code, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = code{bits: reverse(next[l], l), len: l}
+		next[l]++
+	}
+
+	return codes
+}
+
+// reverse returns the n lowest bits of v in the reverse order.
+func reverse(v uint16, n uint8) uint16 {
+	var r uint16
+	for i := uint8(0); i < n; i++ {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
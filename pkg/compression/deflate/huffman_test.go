@@ -0,0 +1,82 @@
+package deflate
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBuildLengths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		freq  []uint32
+		limit uint8
+		want  []uint8
+	}{
+		{name: "no symbols", freq: []uint32{0, 0, 0}, limit: 15, want: []uint8{1, 1, 0}},
+		{name: "a single symbol", freq: []uint32{0, 0, 5}, limit: 15, want: []uint8{1, 0, 1}},
+		{name: "two symbols", freq: []uint32{3, 0, 5}, limit: 15, want: []uint8{1, 0, 1}},
+		{name: "skewed symbols", freq: []uint32{1, 1, 2, 4, 8}, limit: 15, want: []uint8{4, 4, 3, 2, 1}},
+		{name: "skewed symbols limited", freq: []uint32{1, 1, 2, 4, 8}, limit: 3, want: []uint8{3, 3, 3, 3, 1}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("building lengths of %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, buildLengths(test.freq, test.limit))
+		})
+	}
+}
+
+func TestBuildLengthsLimit(t *testing.T) {
+	t.Parallel()
+
+	// the Fibonacci frequencies make the optimal code as deep as the number of symbols
+	freq := make([]uint32, 30)
+	freq[0], freq[1] = 1, 1
+	for i := 2; i < len(freq); i++ {
+		freq[i] = freq[i-1] + freq[i-2]
+	}
+
+	for _, limit := range []uint8{maxCodeLenCode, maxCodeLen} {
+		lengths := buildLengths(freq, limit)
+
+		kraft := 0
+		for _, l := range lengths {
+			assert.LessOrEqual(t, l, limit)
+			kraft += 1 << (limit - l)
+		}
+		assert.Equalf(t, 1<<limit, kraft, "code limited to %d bits must be complete", limit)
+	}
+}
+
+func TestCanonicalCodes(t *testing.T) {
+	t.Parallel()
+
+	// the example of RFC 1951 for the symbols ABCDEFGH
+	lengths := []uint8{3, 3, 3, 3, 3, 2, 4, 4}
+	want := []code{
+		{bits: reverse(0b010, 3), len: 3},
+		{bits: reverse(0b011, 3), len: 3},
+		{bits: reverse(0b100, 3), len: 3},
+		{bits: reverse(0b101, 3), len: 3},
+		{bits: reverse(0b110, 3), len: 3},
+		{bits: reverse(0b00, 2), len: 2},
+		{bits: reverse(0b1110, 4), len: 4},
+		{bits: reverse(0b1111, 4), len: 4},
+	}
+
+	assert.Equal(t, want, canonicalCodes(lengths))
+}
+
+func TestReverse(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, uint16(0b001), reverse(0b100, 3))
+	assert.Equal(t, uint16(0b1101), reverse(0b1011, 4))
+	assert.Equal(t, uint16(0b100000000000000), reverse(1, 15))
+}
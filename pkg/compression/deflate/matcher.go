@@ -0,0 +1,28 @@
+package deflate
+
+import "github.com/psssix/archiver/pkg/compression/internal/match"
+
+// matcher finds the matches of the lengths and at the distances of deflate.
+type matcher struct {
+	*match.Matcher
+}
+
+func newMatcher() *matcher {
+	return &matcher{Matcher: match.New(minMatch, maxMatch, windowSize)}
+}
+
+// tokens appends the tokens of hist[start:] to dst and returns the extended slice,
+// matches refer to hist[:start] as well.
+func (m *matcher) tokens(dst []token, hist []byte, start int) []token {
+	// the function never fails, so neither does Parse
+	_ = m.Parse(hist, start, func(i, dist, length int) error {
+		if dist == 0 {
+			dst = append(dst, token{length: uint16(hist[i])})
+		} else {
+			dst = append(dst, token{length: uint16(length), dist: uint16(dist)})
+		}
+		return nil
+	})
+
+	return dst
+}
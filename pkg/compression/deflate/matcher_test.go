@@ -0,0 +1,50 @@
+package deflate
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// literals returns the literal tokens of str.
+func literals(str string) []token {
+	tokens := make([]token, len(str))
+	for i := range str {
+		tokens[i] = token{length: uint16(str[i])}
+	}
+	return tokens
+}
+
+func TestMatcherTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		start     int
+		want      []token
+	}{
+		{str: "", want: nil},
+		{str: "abcd", want: literals("abcd")},
+		{str: "abcabcabc", want: append(literals("abc"), token{length: 6, dist: 3})},
+		{str: "aaaaaaaaaa", want: append(literals("a"), token{length: 9, dist: 1})},
+		{str: "abcdabcd", start: 4, want: []token{{length: 4, dist: 4}}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("tokens of %q from %d", test.str, test.start)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, newMatcher().tokens(nil, []byte(test.str), test.start))
+		})
+	}
+}
+
+func TestMatcherLongestMatch(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 2*maxMatch+10)
+	tokens := newMatcher().tokens(nil, data, 0)
+
+	assert.Equal(t, []token{{length: 0}, {length: maxMatch, dist: 1}, {length: maxMatch, dist: 1}, {length: 9, dist: 1}}, tokens)
+}
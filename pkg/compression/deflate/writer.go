@@ -0,0 +1,132 @@
+// Package deflate implements a compressor writing the DEFLATE format defined by RFC 1951.
+//
+// The packed data has no header, it is meant to be wrapped by a container such as gzip and can be read
+// with the standard compress/flate package.
+package deflate
+
+import (
+	"errors"
+	"io"
+)
+
+// blockSize is the number of bytes the Writer collects before packing them as a block.
+const blockSize = 64 * 1024
+
+var ErrClosed = errors.New("deflate: stream is closed")
+
+// Writer is an io.WriteCloser that packs the data written to it to the DEFLATE format.
+//
+// Repeated substrings are replaced with references to their previous occurrences within the window of 32 KiB.
+// Every block is written as the smallest of a stored block, a block coded with the fixed Huffman codes
+// and a block coded with the Huffman codes built for its symbols.
+type Writer struct {
+	w       io.Writer
+	matcher *matcher
+	bw      bitWriter
+	tokens  []token
+	// hist holds the window of the already packed data followed by the data to pack from start.
+	hist  []byte
+	start int
+	err   error
+}
+
+// NewWriter returns a new Writer packing the data to w.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:       w,
+		matcher: newMatcher(),
+		hist:    make([]byte, 0, windowSize+blockSize),
+	}
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+
+	for written := 0; written < len(p); {
+		n := copy(z.hist[len(z.hist):z.start+blockSize], p[written:])
+		z.hist = z.hist[:len(z.hist)+n]
+
+		if len(z.hist) == z.start+blockSize {
+			if z.err = z.writeBlock(false); z.err != nil {
+				return written, z.err
+			}
+		}
+
+		written += n
+	}
+
+	return len(p), nil
+}
+
+// Flush packs all the data written so far and writes it to the underlying writer followed by an empty
+// stored block, so a reader gets all the data written so far.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(false); z.err != nil {
+		return z.err
+	}
+
+	writeStored(&z.bw, nil, false)
+	z.err = z.bw.writeTo(z.w)
+	return z.err
+}
+
+// Close writes the rest of the packed data as the final block to the underlying writer.
+// It does not close the underlying writer.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(true); z.err != nil {
+		return z.err
+	}
+	z.bw.align()
+	if z.err = z.bw.writeTo(z.w); z.err != nil {
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriter(w).
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.bw.reset()
+	z.hist = z.hist[:0]
+	z.start = 0
+	z.err = nil
+}
+
+// writeBlock packs the data collected since the previous block as a block and slides the window to its end.
+// An empty block is written only when it is the final one.
+func (z *Writer) writeBlock(final bool) error {
+	if len(z.hist) == z.start && !final {
+		return nil
+	}
+
+	z.tokens = z.matcher.tokens(z.tokens[:0], z.hist, z.start)
+	writeBlock(&z.bw, z.tokens, z.hist[z.start:], final)
+	if err := z.bw.writeTo(z.w); err != nil {
+		return err
+	}
+
+	keep := len(z.hist)
+	if keep > windowSize {
+		keep = windowSize
+	}
+	z.hist = z.hist[:copy(z.hist, z.hist[len(z.hist)-keep:])]
+	z.start = keep
+
+	return nil
+}
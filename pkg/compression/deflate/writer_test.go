@@ -0,0 +1,127 @@
+package deflate
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+	random := make([]byte, 3*blockSize)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		partSize int
+	}{
+		{name: "no data", data: []byte{}, partSize: 1},
+		{name: "text", data: []byte("Hello, World! Hello, World! 1 + 2 = 3.\n"), partSize: 5},
+		{name: "every byte value", data: allBytes, partSize: 256},
+		{name: "random data", data: random, partSize: 10000},
+		{name: "log lines", data: logLines(5000), partSize: 4096},
+		{name: "long runs", data: bytes.Repeat([]byte{0}, 3*blockSize+7), partSize: blockSize},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %s by parts of %d bytes", test.name, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+			for data := test.data; len(data) > 0; {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				written, err := w.Write(data[:n])
+				assert.Nil(t, err)
+				assert.Equal(t, n, written)
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			unpacked, err := io.ReadAll(flate.NewReader(&buf))
+			assert.Nilf(t, err, "flate.Reader must read the data written by Writer")
+			assert.Equal(t, test.data, unpacked)
+		})
+	}
+}
+
+func TestWriterCompressesRepeats(t *testing.T) {
+	t.Parallel()
+
+	data := logLines(5000)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	_, _ = w.Write(data)
+	assert.Nil(t, w.Close())
+	assert.Less(t, buf.Len(), len(data)/4, "Writer must replace repeated substrings with matches")
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	_, _ = w.Write([]byte("abcabcabc"))
+	assert.Nil(t, w.Flush())
+
+	assert.Equal(t, []byte{0x00, 0x00, 0xFF, 0xFF}, buf.Bytes()[buf.Len()-4:], "Writer.Flush() must end with an empty stored block")
+
+	// the reader gets the flushed data before the end of the stream
+	r := flate.NewReader(bytes.NewReader(buf.Bytes()))
+	flushed := make([]byte, 9)
+	_, err := io.ReadFull(r, flushed)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabc"), flushed)
+
+	_, _ = w.Write([]byte("abc"))
+	assert.Nil(t, w.Close())
+	unpacked, err := io.ReadAll(flate.NewReader(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabcabc"), unpacked)
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("abc"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("abcabcabc"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("abcabcabc"))
+	assert.Nil(t, w.Close())
+
+	assert.Empty(t, first.Bytes())
+	unpacked, err := io.ReadAll(flate.NewReader(&second))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabc"), unpacked, "Writer.Reset() must discard the written data")
+}
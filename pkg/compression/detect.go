@@ -0,0 +1,129 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// DetectLen is the number of the first bytes of the data enough for Detect.
+const DetectLen = 16
+
+var (
+	ErrClosed       = errors.New("compression: stream is closed")
+	ErrUnrecognized = errors.New("compression: unrecognized format")
+)
+
+// Detect returns the name of the registered codec the data starting with header is packed with.
+//
+// The codec with the longest Magic the header starts with is chosen, the codecs without Magic are checked
+// with their Match functions. Detect fails with ErrUnrecognized when no codec is found, and with
+// a VersionError when the data is packed by a later version of the archiver.
+func Detect(header []byte) (string, error) {
+	var (
+		detected string
+		magicLen int
+	)
+
+	for _, name := range List() {
+		f, _ := Lookup(name)
+		if len(f.Magic) > magicLen && bytes.HasPrefix(header, f.Magic) {
+			detected, magicLen = name, len(f.Magic)
+		}
+	}
+	if detected != "" {
+		return detected, nil
+	}
+
+	for _, name := range List() {
+		if f, _ := Lookup(name); len(f.Magic) == 0 && f.Match != nil && f.Match(header) {
+			return name, nil
+		}
+	}
+
+	if len(header) > len(Magic) && string(header[:len(Magic)]) == Magic && header[len(Magic)] > Version {
+		return "", NewVersionError(header[len(Magic)])
+	}
+	if len(header) > len(Magic)+1 && string(header[:len(Magic)]) == Magic {
+		return "", fmt.Errorf("%w: unknown %s", ErrUnrecognized, CodecID(header[len(Magic)+1]))
+	}
+
+	return "", ErrUnrecognized
+}
+
+// DetectingReader is an io.ReadCloser that unpacks the data of any registered codec with the default options,
+// the codec is detected by the first bytes of the data.
+//
+// The codec is detected on the first call to Read.
+type DetectingReader struct {
+	// Codec is the name of the detected codec, it is set by the first call to Read.
+	Codec string
+	r     *bufio.Reader
+	rc    io.ReadCloser
+	err   error
+}
+
+// NewDetectingReader returns a new DetectingReader unpacking the data read from r.
+//
+// The DetectingReader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the DetectingReader when done.
+func NewDetectingReader(r io.Reader) *DetectingReader {
+	return &DetectingReader{r: bufio.NewReader(r)}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *DetectingReader) Read(p []byte) (int, error) {
+	if z.rc == nil && z.err == nil {
+		z.rc, z.err = z.detect()
+	}
+	if z.err != nil {
+		return 0, z.err
+	}
+
+	n, err := z.rc.Read(p)
+	if err != nil {
+		z.err = err
+	}
+
+	return n, err
+}
+
+// Close closes the reader of the detected codec. It does not close the underlying reader.
+func (z *DetectingReader) Close() error {
+	var err error
+	if z.rc != nil {
+		err = z.rc.Close()
+	}
+	z.err = ErrClosed
+
+	return err
+}
+
+func (z *DetectingReader) detect() (io.ReadCloser, error) {
+	header, err := z.r.Peek(DetectLen)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if z.Codec, err = Detect(header); err != nil {
+		return nil, err
+	}
+	f, _ := Lookup(z.Codec)
+
+	return f.Unpack(flag.NewFlagSet(z.Codec, flag.ContinueOnError))(z.r), nil
+}
+
+// Auto is the Unpacker of the data of any registered codec, the codec is detected by the first bytes of the data.
+var Auto Unpacker = auto{}
+
+type auto struct{}
+
+func (auto) Unpack(data []byte) ([]byte, error) {
+	r := NewDetectingReader(bytes.NewReader(data))
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
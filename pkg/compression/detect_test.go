@@ -0,0 +1,126 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// prefixFactory returns the factory of the codec prefixing the data with the prefix.
+func prefixFactory(prefix string) Factory {
+	return Factory{
+		Unpack: func(_ *flag.FlagSet) ReaderFunc {
+			return func(r io.Reader) io.ReadCloser {
+				return io.NopCloser(io.MultiReader(strings.NewReader(prefix), r))
+			}
+		},
+	}
+}
+
+func init() {
+	short := prefixFactory("short:")
+	short.Magic = []byte("TST")
+	Register("test-short-magic", short)
+
+	long := prefixFactory("long:")
+	long.Magic = []byte("TSTLONG")
+	Register("test-long-magic", long)
+
+	matched := prefixFactory("matched:")
+	matched.Match = func(header []byte) bool { return bytes.HasPrefix(header, []byte("~~")) }
+	Register("test-match", matched)
+
+	own := prefixFactory("own:")
+	own.Magic = HeaderMagic(CodecID(200))
+	Register("test-own", own)
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header, want string
+	}{
+		{header: "TST", want: "test-short-magic"},
+		{header: "TSTL", want: "test-short-magic"},
+		{header: "TSTLONG and the data", want: "test-long-magic"},
+		{header: "~~ and the data", want: "test-match"},
+		{header: string(HeaderMagic(CodecID(200))), want: "test-own"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("detecting %q", test.header), func(t *testing.T) {
+			t.Parallel()
+			name, err := Detect([]byte(test.header))
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, name)
+		})
+	}
+}
+
+func TestDetectError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		error  string
+	}{
+		{header: "", error: "compression: unrecognized format"},
+		{header: "TS", error: "compression: unrecognized format"},
+		{header: "plain text", error: "compression: unrecognized format"},
+		{header: "ARCV\x01\xC9", error: "compression: unrecognized format: unknown codec(201)"},
+		{header: "ARCV\x07\x01", error: "compression: unsupported format version 7, latest supported is 1"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("detecting %q", test.header), func(t *testing.T) {
+			t.Parallel()
+			_, err := Detect([]byte(test.header))
+			assert.EqualError(t, err, test.error)
+		})
+	}
+}
+
+func TestDetectingReader(t *testing.T) {
+	t.Parallel()
+
+	r := NewDetectingReader(strings.NewReader("TSTLONG data"))
+	unpacked, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "long:TSTLONG data", string(unpacked), "the detected codec must read the data from its start")
+	assert.Equal(t, "test-long-magic", r.Codec)
+
+	assert.Nil(t, r.Close())
+	_, err = r.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestDetectingReaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := io.ReadAll(NewDetectingReader(strings.NewReader("plain text")))
+	assert.ErrorIs(t, err, ErrUnrecognized)
+
+	errSource := errors.New("source error")
+	_, err = io.ReadAll(NewDetectingReader(iotest.ErrReader(errSource)))
+	assert.ErrorIs(t, err, errSource)
+}
+
+func TestAuto(t *testing.T) {
+	t.Parallel()
+
+	unpacked, err := Auto.Unpack([]byte("~~data"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("matched:~~data"), unpacked)
+
+	_, err = Auto.Unpack([]byte("data"))
+	assert.ErrorIs(t, err, ErrUnrecognized)
+}
@@ -0,0 +1,52 @@
+package compression
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// integer is the type of the integer options of the codecs.
+type integer interface {
+	~int | ~uint
+}
+
+// rangeValue is the flag.Value of an integer option in the range [min, max].
+type rangeValue[T integer] struct {
+	p        *T
+	min, max T
+}
+
+// RangeVar defines in fs the flag of an integer option with the default value, the values out of the range
+// [min, max] are rejected when the flags are parsed. The codecs define their options with it, so the writers
+// returned by Factory.Pack are never created with invalid options.
+func RangeVar[T integer](fs *flag.FlagSet, p *T, name string, value, min, max T, usage string) {
+	*p = value
+	fs.Var(&rangeValue[T]{p: p, min: min, max: max}, name, usage)
+}
+
+func (v *rangeValue[T]) String() string {
+	if v.p == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(*v.p), 10)
+}
+
+// Type returns the name of the type of the option shown in the usage of the commands.
+func (v *rangeValue[T]) Type() string {
+	var zero T
+	return fmt.Sprintf("%T", zero)
+}
+
+func (v *rangeValue[T]) Set(s string) error {
+	n, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return errors.New("parse error")
+	}
+	if n < int64(v.min) || n > int64(v.max) {
+		return fmt.Errorf("%d out of range [%d, %d]", n, v.min, v.max)
+	}
+	*v.p = T(n)
+	return nil
+}
@@ -0,0 +1,55 @@
+package compression
+
+import (
+	"flag"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestRangeVar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		want  uint
+		error string
+	}{
+		{name: "default value", args: []string{}, want: 12},
+		{name: "minimum value", args: []string{"-width", "9"}, want: 9},
+		{name: "maximum value", args: []string{"-width", "16"}, want: 16},
+		{name: "too small value", args: []string{"-width", "8"}, error: `invalid value "8" for flag -width: 8 out of range [9, 16]`},
+		{name: "too large value", args: []string{"-width", "17"}, error: `invalid value "17" for flag -width: 17 out of range [9, 16]`},
+		{name: "negative value", args: []string{"-width", "-1"}, error: `invalid value "-1" for flag -width: -1 out of range [9, 16]`},
+		{name: "not a number", args: []string{"-width", "x"}, error: `invalid value "x" for flag -width: parse error`},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("parse %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			var width uint
+			RangeVar(fs, &width, "width", 12, 9, 16, "width")
+
+			err := fs.Parse(test.args)
+			if test.error != "" {
+				assert.EqualErrorf(t, err, test.error, "FlagSet.Parse(%v) unexpected error", test.args)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equalf(t, test.want, width, "FlagSet.Parse(%v) unexpected value", test.args)
+		})
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var minMatch int
+	RangeVar(fs, &minMatch, "min-match", 3, 2, 16, "minimum match")
+	assert.Equal(t, "3", fs.Lookup("min-match").DefValue, "the default value must be shown in the usage")
+	assert.Equal(t, "int", fs.Lookup("min-match").Value.(interface{ Type() string }).Type())
+}
@@ -0,0 +1,46 @@
+package gzip
+
+import (
+	"flag"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+func init() {
+	compression.Register("gzip", compression.Factory{
+		Description: "DEFLATE in gzip format readable by gunzip",
+		Extension:   "gz",
+		Magic:       []byte{id1, id2},
+		// gzip has its own header, it keeps the size of the data in its trailer
+		Pack: func(_ *flag.FlagSet) compression.WriterFunc {
+			return newWriter
+		},
+		Unpack: func(_ *flag.FlagSet) compression.ReaderFunc {
+			return newReader
+		},
+	})
+}
+
+type Codec struct{}
+
+func New() Codec {
+	return Codec{}
+}
+
+func (_ Codec) Pack(data []byte) ([]byte, error) {
+	return compression.PackWith(newWriter, data)
+}
+
+func (_ Codec) Unpack(data []byte) ([]byte, error) {
+	return compression.UnpackWith(newReader, data)
+}
+
+// newWriter returns the Writer packing the data to w, the Header is not written.
+func newWriter(w io.Writer, _ compression.Header) (io.WriteCloser, error) {
+	return NewWriter(w), nil
+}
+
+// newReader returns the Reader unpacking the data read from r.
+func newReader(r io.Reader) io.ReadCloser {
+	return NewReader(r)
+}
@@ -0,0 +1,60 @@
+package gzip
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	random := make([]byte, 200*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "no data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! Hello, World! 1 + 2 = 3.\n")},
+		{name: "random data", data: random},
+		{name: "long runs", data: bytes.Repeat([]byte("ab"), 300*1024)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing and unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, err := New().Pack(test.data)
+			assert.Nil(t, err)
+			unpacked, err := New().Unpack(packed)
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, unpacked)
+		})
+	}
+}
+
+func TestRegisteredHeader(t *testing.T) {
+	t.Parallel()
+
+	f, _ := compression.Lookup("gzip")
+
+	var buf bytes.Buffer
+	w, err := f.Pack(flag.NewFlagSet("gzip", flag.ContinueOnError))(&buf, compression.Header{Flags: compression.FlagSize, Size: 3})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("abc"))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, packed("abc"), buf.Bytes(), "the registered writer must ignore the Header")
+}
+
+// packed returns str packed by Codec.Pack.
+func packed(str string) []byte {
+	data, _ := New().Pack([]byte(str))
+	return data
+}
@@ -0,0 +1,80 @@
+package gzip
+
+import (
+	"compress/flate"
+	stdgzip "compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reader is an io.ReadCloser that unpacks gzip data read from the underlying reader, written by the Writer
+// or by any other gzip compressor. Concatenated gzip members are unpacked as a single stream.
+//
+// The gzip header is read and validated on the first call to Read, the CRC-32 and the size of every member
+// are verified at its end.
+type Reader struct {
+	r      io.Reader
+	gzip   *stdgzip.Reader
+	opened bool
+	err    error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if !z.opened {
+		z.opened = true
+		if z.gzip, z.err = stdgzip.NewReader(z.r); z.err != nil {
+			// the data ending before the header is as corrupted as a truncated header
+			if z.err == io.EOF {
+				z.err = io.ErrUnexpectedEOF
+			}
+			z.err = corrupted(z.err)
+			return 0, z.err
+		}
+	}
+
+	n, err := z.gzip.Read(p)
+	if err != nil {
+		z.err = corrupted(err)
+	}
+
+	return n, z.err
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = r
+	z.gzip = nil
+	z.opened = false
+	z.err = nil
+}
+
+// corrupted wraps the errors of invalid gzip data with ErrCorrupted, the other errors are returned as they are.
+func corrupted(err error) error {
+	var flateErr flate.CorruptInputError
+
+	if errors.Is(err, stdgzip.ErrHeader) || errors.Is(err, stdgzip.ErrChecksum) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &flateErr) {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	return err
+}
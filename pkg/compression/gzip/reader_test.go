@@ -0,0 +1,100 @@
+package gzip
+
+import (
+	"bytes"
+	stdgzip "compress/gzip"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+// stdPacked returns str packed by the standard gzip package.
+func stdPacked(str string) []byte {
+	var buf bytes.Buffer
+	w := stdgzip.NewWriter(&buf)
+	_, _ = w.Write([]byte(str))
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		data       []byte
+	}{
+		{name: "empty data packed by Writer", want: "", data: packed("")},
+		{name: "text packed by Writer", want: "abcabcabc", data: packed("abcabcabc")},
+		{name: "text packed by compress/gzip", want: "abcabcabc", data: stdPacked("abcabcabc")},
+		{name: "concatenated members", want: "abcdef", data: append(packed("abc"), stdPacked("def")...)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := NewReader(bytes.NewReader(test.data))
+			unpacked, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, []byte(test.want), unpacked)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	valid := packed("abcabcabc")
+	badChecksum := append([]byte{}, valid...)
+	badChecksum[len(badChecksum)-8] ^= 0xFF
+	badData := append([]byte{}, valid...)
+	// the reserved block type 3
+	badData[10] = 0x07
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "data with wrong magic", data: []byte("abcabcabcabc")},
+		{name: "truncated data", data: valid[:len(valid)-5]},
+		{name: "data with wrong checksum", data: badChecksum},
+		{name: "data with invalid block", data: badData},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIs(t, err, ErrCorrupted)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(packed("abc")))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 3))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(packed("abc")))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(packed("def")))
+	unpacked, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("def"), unpacked)
+}
@@ -0,0 +1,159 @@
+// Package gzip implements packing to the gzip format defined by RFC 1952 with the DEFLATE compressor
+// of the deflate package, the packed files can be unpacked by gunzip and the like.
+package gzip
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/psssix/archiver/pkg/compression/deflate"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+const (
+	id1 = 0x1f
+	id2 = 0x8b
+	// methodDeflate is the only compression method of gzip.
+	methodDeflate = 8
+	// flagName is set when the header holds the original file name.
+	flagName = 1 << 3
+	// osUnknown is the value of the OS field not telling the file system the data comes from.
+	osUnknown = 255
+)
+
+var (
+	ErrClosed    = errors.New("gzip: stream is closed")
+	ErrCorrupted = errors.New("gzip: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it as a gzip member: the header, the DEFLATE
+// stream and the trailer holding the CRC-32 and the size of the data.
+//
+// The header is written before the packed data, so Name and ModTime may be set until the first call
+// to Write, Flush or Close.
+type Writer struct {
+	// Name is the original file name, it is written in the header when not empty.
+	Name string
+	// ModTime is the modification time of the original file, zero means it is not known.
+	ModTime time.Time
+
+	w           io.Writer
+	wroteHeader bool
+	deflate     *deflate.Writer
+	crc         hash.Hash32
+	written     uint32
+	err         error
+}
+
+// NewWriter returns a new Writer packing the data to w.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:       w,
+		deflate: deflate.NewWriter(w),
+		crc:     crc32.NewIEEE(),
+	}
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	n, err := z.deflate.Write(p)
+	_, _ = z.crc.Write(p[:n])
+	z.written += uint32(n)
+	if err != nil {
+		z.err = err
+		return n, z.err
+	}
+
+	return n, nil
+}
+
+// Flush packs all the data written so far and writes it to the underlying writer.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.err = z.deflate.Flush()
+	return z.err
+}
+
+// Close writes the rest of the packed data and the trailer to the underlying writer.
+// It does not close the underlying writer.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.deflate.Close(); z.err != nil {
+		return z.err
+	}
+
+	trailer := make([]byte, 8)
+	binary.LittleEndian.PutUint32(trailer, z.crc.Sum32())
+	// the size is stored modulo 2^32
+	binary.LittleEndian.PutUint32(trailer[4:], z.written)
+	if _, z.err = z.w.Write(trailer); z.err != nil {
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriter(w).
+func (z *Writer) Reset(w io.Writer) {
+	z.Name = ""
+	z.ModTime = time.Time{}
+	z.w = w
+	z.wroteHeader = false
+	z.deflate.Reset(w)
+	z.crc.Reset()
+	z.written = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+	z.wroteHeader = true
+
+	header := []byte{id1, id2, methodDeflate, 0, 0, 0, 0, 0, 0, osUnknown}
+	if !z.ModTime.IsZero() && z.ModTime.Unix() > 0 {
+		binary.LittleEndian.PutUint32(header[4:8], uint32(z.ModTime.Unix()))
+	}
+	if z.Name != "" {
+		header[3] |= flagName
+		// the name is zero-terminated, so it is cut at the first zero byte
+		name := z.Name
+		for i := 0; i < len(name); i++ {
+			if name[i] == 0 {
+				name = name[:i]
+				break
+			}
+		}
+		header = append(append(header, name...), 0)
+	}
+
+	_, err := z.w.Write(header)
+	return err
+}
@@ -0,0 +1,105 @@
+package gzip
+
+import (
+	"bytes"
+	stdgzip "compress/gzip"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+	}{
+		{str: "", partSize: 1},
+		{str: "abcabcabc", partSize: 1},
+		{str: "Hello, World! Hello, World! 1 + 2 = 3.\n", partSize: 4},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+			for data := []byte(test.str); len(data) > 0; {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nil(t, err)
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			packed := buf.Bytes()
+			assert.Equal(t, []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 0, 255}, packed[:10])
+			assert.Equal(t, []byte{byte(len(test.str)), 0, 0, 0}, packed[len(packed)-4:], "the trailer must end with the size")
+
+			r, err := stdgzip.NewReader(&buf)
+			assert.Nil(t, err)
+			unpacked, err := io.ReadAll(r)
+			assert.Nilf(t, err, "gzip.Reader must verify the data written by Writer")
+			assert.Equal(t, []byte(test.str), unpacked)
+		})
+	}
+}
+
+func TestWriterHeader(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Name = "notes.txt"
+	w.ModTime = modTime
+	_, _ = w.Write([]byte("abc"))
+	assert.Nil(t, w.Close())
+
+	r, err := stdgzip.NewReader(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "notes.txt", r.Name)
+	assert.True(t, modTime.Equal(r.ModTime))
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("abc"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	w.Name = "first.txt"
+	_, _ = w.Write([]byte("abc"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("abcabc"))
+	assert.Nil(t, w.Close())
+
+	r, err := stdgzip.NewReader(&second)
+	assert.Nil(t, err)
+	assert.Empty(t, r.Name, "Writer.Reset() must discard the name")
+	unpacked, err := io.ReadAll(r)
+	assert.Nil(t, err, "Writer.Reset() must restart the checksum and the size")
+	assert.Equal(t, []byte("abcabc"), unpacked)
+}
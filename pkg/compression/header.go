@@ -0,0 +1,170 @@
+package compression
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Magic is the signature every packed file starts with.
+const Magic = "ARCV"
+
+// Version is the version of the packed file format written by this package.
+const Version = 1
+
+// HeaderSize is the size of the encoded Header in bytes.
+const HeaderSize = len(Magic) + 3 + 8
+
+// CodecID identifies the codec the data is packed with.
+type CodecID uint8
+
+const (
+	VLC CodecID = iota + 1
+	Huffman
+	LZSS
+	LZW
+	RLE
+	BWT
+	Arith
+	ANS
+)
+
+var codecNames = map[CodecID]string{
+	VLC:     "vlc",
+	Huffman: "huffman",
+	LZSS:    "lzss",
+	LZW:     "lzw",
+	RLE:     "rle",
+	BWT:     "bwt",
+	Arith:   "arith",
+	ANS:     "ans",
+}
+
+func (id CodecID) String() string {
+	if name, ok := codecNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("codec(%d)", uint8(id))
+}
+
+// HeaderMagic returns the signature of the data packed with the codec: the magic, the version and the codec.
+func HeaderMagic(id CodecID) []byte {
+	return append([]byte(Magic), Version, byte(id))
+}
+
+// Flags describe optional parts of the packed file.
+type Flags uint8
+
+const (
+	// FlagSize is set when Header.Size holds the length of the original data.
+	FlagSize Flags = 1 << iota
+	// FlagChecksum is set when the packed data is followed by the checksum of the original data,
+	// see WriteChecksum.
+	FlagChecksum
+	// FlagPacked is set when the data following the header is packed once more with another codec,
+	// which writes its own header. Only the rle codec sets it, so that the run-length encoding
+	// of the data packed by another codec is recorded in the outermost header.
+	FlagPacked
+
+	knownFlags = FlagSize | FlagChecksum | FlagPacked
+)
+
+// Header is the header of a packed file.
+type Header struct {
+	Version uint8
+	Codec   CodecID
+	Flags   Flags
+	Size    uint64
+}
+
+var ErrHeader = errors.New("compression: invalid header, not a packed file")
+
+type (
+	VersionError struct {
+		version uint8
+	}
+	CodecMismatchError struct {
+		codec, expected CodecID
+	}
+)
+
+func NewVersionError(v uint8) *VersionError {
+	return &VersionError{version: v}
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("compression: unsupported format version %d, latest supported is %d", e.version, Version)
+}
+
+func NewCodecMismatchError(codec, expected CodecID) *CodecMismatchError {
+	return &CodecMismatchError{codec: codec, expected: expected}
+}
+
+func (e *CodecMismatchError) Error() string {
+	return fmt.Sprintf("compression: data is packed with %s, not %s", e.codec, e.expected)
+}
+
+// WriteHeader writes the header to w.
+//
+// Layout: magic (4 bytes), version, codec, flags (1 byte each), original size (8 bytes, big endian).
+func WriteHeader(w io.Writer, h Header) error {
+	buf := make([]byte, HeaderSize)
+	copy(buf, Magic)
+	buf[4] = h.Version
+	buf[5] = byte(h.Codec)
+	buf[6] = byte(h.Flags)
+	binary.BigEndian.PutUint64(buf[7:], h.Size)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadHeader reads the header from r and validates its magic, version and flags.
+func ReadHeader(r io.Reader) (Header, error) {
+	buf := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return Header{}, ErrHeader
+		}
+		return Header{}, err
+	}
+
+	if string(buf[:len(Magic)]) != Magic {
+		return Header{}, ErrHeader
+	}
+
+	h := Header{
+		Version: buf[4],
+		Codec:   CodecID(buf[5]),
+		Flags:   Flags(buf[6]),
+		Size:    binary.BigEndian.Uint64(buf[7:]),
+	}
+
+	if h.Version == 0 || h.Version > Version {
+		return Header{}, NewVersionError(h.Version)
+	}
+	if h.Flags&^knownFlags != 0 {
+		return Header{}, ErrHeader
+	}
+
+	return h, nil
+}
+
+// ReadCodecHeader reads the header from r like ReadHeader and checks the data is packed with the given codec.
+// FlagPacked is accepted only for the rle codec.
+func ReadCodecHeader(r io.Reader, codec CodecID) (Header, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return Header{}, err
+	}
+
+	if h.Codec != codec {
+		return Header{}, NewCodecMismatchError(h.Codec, codec)
+	}
+	if h.Flags&FlagPacked != 0 && codec != RLE {
+		return Header{}, ErrHeader
+	}
+
+	return h, nil
+}
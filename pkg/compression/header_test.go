@@ -0,0 +1,176 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWriteHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header Header
+		want   []byte
+	}{
+		{
+			name:   "without size",
+			header: Header{Version: Version, Codec: VLC},
+			want:   []byte{'A', 'R', 'C', 'V', 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:   "with size",
+			header: Header{Version: Version, Codec: VLC, Flags: FlagSize, Size: 0x0102030405},
+			want:   []byte{'A', 'R', 'C', 'V', 1, 1, 1, 0, 0, 0, 0x01, 0x02, 0x03, 0x04, 0x05},
+		},
+		{
+			name:   "with size and checksum",
+			header: Header{Version: Version, Codec: VLC, Flags: FlagSize | FlagChecksum, Size: 3},
+			want:   []byte{'A', 'R', 'C', 'V', 1, 1, 3, 0, 0, 0, 0, 0, 0, 0, 3},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("write header %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			assert.Nil(t, WriteHeader(&buf, test.header))
+			assert.Equalf(t, test.want, buf.Bytes(), "WriteHeader(%v)", test.header)
+		})
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want Header
+	}{
+		{
+			name: "without size",
+			data: []byte{'A', 'R', 'C', 'V', 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			want: Header{Version: Version, Codec: VLC},
+		},
+		{
+			name: "with size followed by payload",
+			data: []byte{'A', 'R', 'C', 'V', 1, 1, 1, 0, 0, 0, 0x01, 0x02, 0x03, 0x04, 0x05, 0xff},
+			want: Header{Version: Version, Codec: VLC, Flags: FlagSize, Size: 0x0102030405},
+		},
+		{
+			name: "with checksum",
+			data: []byte{'A', 'R', 'C', 'V', 1, 1, 2, 0, 0, 0, 0, 0, 0, 0, 0},
+			want: Header{Version: Version, Codec: VLC, Flags: FlagChecksum},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("read header %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			h, err := ReadHeader(bytes.NewReader(test.data))
+			assert.Nil(t, err)
+			assert.Equalf(t, test.want, h, "ReadHeader(%v)", test.data)
+		})
+	}
+}
+
+func TestReadHeaderError(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read error")
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "empty data",
+			data:  []byte{},
+			err:   ErrHeader,
+			error: "compression: invalid header, not a packed file",
+		},
+		{
+			name:  "truncated header",
+			data:  []byte{'A', 'R', 'C', 'V', 1, 1, 0, 0},
+			err:   ErrHeader,
+			error: "compression: invalid header, not a packed file",
+		},
+		{
+			name:  "wrong magic",
+			data:  []byte{'P', 'K', 3, 4, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			err:   ErrHeader,
+			error: "compression: invalid header, not a packed file",
+		},
+		{
+			name:  "unknown flags",
+			data:  []byte{'A', 'R', 'C', 'V', 1, 1, 0x80, 0, 0, 0, 0, 0, 0, 0, 0},
+			err:   ErrHeader,
+			error: "compression: invalid header, not a packed file",
+		},
+		{
+			name:  "zero version",
+			data:  []byte{'A', 'R', 'C', 'V', 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			err:   NewVersionError(0),
+			error: "compression: unsupported format version 0, latest supported is 1",
+		},
+		{
+			name:  "future version",
+			data:  []byte{'A', 'R', 'C', 'V', 2, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			err:   NewVersionError(2),
+			error: "compression: unsupported format version 2, latest supported is 1",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("read header error on %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			h, err := ReadHeader(bytes.NewReader(test.data))
+			assert.Emptyf(t, h, "ReadHeader(%v) not empty result when error", test.data)
+			assert.Equalf(t, test.err, err, "ReadHeader(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "ReadHeader(%v) unexpected error message", test.data)
+		})
+	}
+
+	t.Run("read header error on failed reader", func(t *testing.T) {
+		t.Parallel()
+		_, err := ReadHeader(iotest.ErrReader(errRead))
+		assert.ErrorIs(t, err, errRead)
+	})
+}
+
+func TestReadCodecHeader(t *testing.T) {
+	t.Parallel()
+
+	data := []byte{'A', 'R', 'C', 'V', 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	h, err := ReadCodecHeader(bytes.NewReader(data), VLC)
+	assert.Nil(t, err)
+	assert.Equal(t, Header{Version: Version, Codec: VLC}, h)
+
+	h, err = ReadCodecHeader(bytes.NewReader(data), CodecID(42))
+	assert.Empty(t, h)
+	assert.Equal(t, NewCodecMismatchError(VLC, CodecID(42)), err)
+	assert.Equal(t, "compression: data is packed with vlc, not codec(42)", err.Error())
+
+	packed := []byte{'A', 'R', 'C', 'V', 1, byte(RLE), 7, 0, 0, 0, 0, 0, 0, 0, 3}
+	h, err = ReadCodecHeader(bytes.NewReader(packed), RLE)
+	assert.Nil(t, err)
+	assert.Equal(t, Header{Version: Version, Codec: RLE, Flags: FlagSize | FlagChecksum | FlagPacked, Size: 3}, h)
+
+	packed[5] = byte(VLC)
+	h, err = ReadCodecHeader(bytes.NewReader(packed), VLC)
+	assert.Empty(t, h)
+	assert.Equal(t, ErrHeader, err)
+}
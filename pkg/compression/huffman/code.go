@@ -0,0 +1,132 @@
+package huffman
+
+import (
+	"container/heap"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression/canonical"
+)
+
+// node is a node of the Huffman tree being built, only its weight and depth of its leaves are tracked.
+type node struct {
+	weight  uint64
+	symbols []byte
+	// min is the minimal symbol of the subtree, it breaks ties between equal weights deterministically.
+	min byte
+}
+
+type nodeHeap []*node
+
+func (h nodeHeap) Len() int { return len(h) }
+
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].weight != h[j].weight {
+		return h[i].weight < h[j].weight
+	}
+	return h[i].min < h[j].min
+}
+
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *nodeHeap) Push(x any) { *h = append(*h, x.(*node)) }
+
+func (h *nodeHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// countFrequencies returns the number of occurrences of every byte value in data.
+func countFrequencies(data []byte) [256]uint64 {
+	var freq [256]uint64
+
+	for _, b := range data {
+		freq[b]++
+	}
+
+	return freq
+}
+
+// buildLengths builds optimal prefix code for the frequencies and returns lengths of its codes.
+//
+// A single used symbol gets a code of 1 bit, so every used symbol has a code. The codes never exceed
+// canonical.MaxLen: a code longer than 25 bits needs more than blockSize symbols in the block.
+func buildLengths(freq [256]uint64) canonical.Lengths {
+	var (
+		lengths canonical.Lengths
+		h       = make(nodeHeap, 0, len(freq))
+	)
+
+	for s, f := range freq {
+		if f != 0 {
+			h = append(h, &node{weight: f, symbols: []byte{byte(s)}, min: byte(s)})
+		}
+	}
+
+	switch len(h) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[h[0].min] = 1
+		return lengths
+	}
+
+	heap.Init(&h)
+	for h.Len() > 1 {
+		a := heap.Pop(&h).(*node)
+		b := heap.Pop(&h).(*node)
+
+		for _, s := range a.symbols {
+			lengths[s]++
+		}
+		for _, s := range b.symbols {
+			lengths[s]++
+		}
+
+		merged := &node{weight: a.weight + b.weight, symbols: append(a.symbols, b.symbols...), min: a.min}
+		if b.min < merged.min {
+			merged.min = b.min
+		}
+		heap.Push(&h, merged)
+	}
+
+	return lengths
+}
+
+// Encode writes the canonical code table built for the byte frequencies of data followed by the codes of data
+// to w. The number of bytes is not written, it must be passed to Decode separately.
+//
+// It lets other codecs use Huffman coding as a stage of their blocks, data must not exceed the block size.
+func Encode(w *bitio.BitWriter, data []byte) error {
+	lengths := buildLengths(countFrequencies(data))
+	codes, err := lengths.Codes()
+	if err != nil {
+		return err
+	}
+
+	if err := canonical.Encode(w, &lengths); err != nil {
+		return err
+	}
+	for _, b := range data {
+		if err := w.WriteBits(codes[b].Bits, codes[b].Len); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decode reads the code table and n codes written by Encode from r, appends the decoded bytes to dst
+// and returns the extended slice.
+func Decode(r *bitio.BitReader, n int, dst []byte) ([]byte, error) {
+	lengths, err := canonical.Decode(r)
+	if err != nil {
+		return dst, invalidTable(bitio.NoEOF(err))
+	}
+	codes, err := lengths.Codes()
+	if err != nil {
+		return dst, invalidTable(err)
+	}
+
+	return newDecodingTree(&codes).decode(r, n, dst)
+}
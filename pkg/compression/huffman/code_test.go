@@ -0,0 +1,139 @@
+package huffman
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression/canonical"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func TestCountFrequencies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      map[byte]uint64
+	}{
+		{str: "", want: map[byte]uint64{}},
+		{str: "aaa", want: map[byte]uint64{'a': 3}},
+		{str: "abracadabra", want: map[byte]uint64{'a': 5, 'b': 2, 'r': 2, 'c': 1, 'd': 1}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("count frequencies of bytes in %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var want [256]uint64
+			for b, f := range test.want {
+				want[b] = f
+			}
+			assert.Equalf(t, want, countFrequencies([]byte(test.str)), "countFrequencies(%v)", test.str)
+		})
+	}
+}
+
+func TestBuildLengths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      map[byte]uint8
+	}{
+		{str: "", want: map[byte]uint8{}},
+		{str: "aaa", want: map[byte]uint8{'a': 1}},
+		{str: "ab", want: map[byte]uint8{'a': 1, 'b': 1}},
+		{str: "abcd", want: map[byte]uint8{'a': 2, 'b': 2, 'c': 2, 'd': 2}},
+		{str: "abracadabra", want: map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("build code lengths for %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var want canonical.Lengths
+			for b, l := range test.want {
+				want[b] = l
+			}
+			freq := countFrequencies([]byte(test.str))
+			assert.Equalf(t, want, buildLengths(freq), "buildLengths(%v)", freq)
+		})
+	}
+}
+
+func TestBuildLengthsIsOptimal(t *testing.T) {
+	t.Parallel()
+
+	var freq [256]uint64
+	for s := range freq {
+		freq[s] = uint64(s*s%97 + 1)
+	}
+	lengths := buildLengths(freq)
+
+	var cost uint64
+	for s, l := range lengths {
+		cost += freq[s] * uint64(l)
+	}
+
+	// the cost of an optimal code is the sum of weights of all nodes merged while building it
+	weights := append([]uint64{}, freq[:]...)
+	var optimal uint64
+	for len(weights) > 1 {
+		sort.Slice(weights, func(i, j int) bool { return weights[i] < weights[j] })
+		merged := weights[0] + weights[1]
+		optimal += merged
+		weights = append(weights[2:], merged)
+	}
+	assert.Equal(t, optimal, cost)
+
+	_, err := lengths.Codes()
+	assert.Nil(t, err, "built lengths must form a prefix code")
+}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+	}{
+		{str: ""},
+		{str: "aaa"},
+		{str: "abracadabra"},
+		{str: "Съешь же ещё этих мягких французских булок"},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encode and decode %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			buf := new(bytes.Buffer)
+			bw := bitio.NewBitWriter(buf)
+			assert.Nil(t, Encode(bw, []byte(test.str)))
+			assert.Nil(t, bw.Flush())
+
+			got, err := Decode(bitio.NewBitReader(buf), len(test.str), []byte("x"))
+			assert.Nil(t, err)
+			assert.Equalf(t, "x"+test.str, string(got), "Decode() must reverse Encode() and append to dst")
+		})
+	}
+}
+
+func TestDecodeInvalidTable(t *testing.T) {
+	t.Parallel()
+
+	// three codes of 1 bit can't form a prefix code
+	lengths := canonical.Lengths{'a': 1, 'b': 1, 'c': 1}
+	buf := new(bytes.Buffer)
+	bw := bitio.NewBitWriter(buf)
+	assert.Nil(t, canonical.Encode(bw, &lengths))
+	assert.Nil(t, bw.WriteBits(0, 8))
+	assert.Nil(t, bw.Flush())
+
+	_, err := Decode(bitio.NewBitReader(buf), 1, nil)
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
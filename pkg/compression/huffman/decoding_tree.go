@@ -0,0 +1,73 @@
+package huffman
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression/canonical"
+)
+
+type decodingTree struct {
+	leaf   bool
+	symbol byte
+	zero   *decodingTree
+	one    *decodingTree
+}
+
+func newDecodingTree(c *canonical.Codes) *decodingTree {
+	tree := new(decodingTree)
+
+	for s, code := range c {
+		if code.Len != 0 {
+			tree.add(code, byte(s))
+		}
+	}
+
+	return tree
+}
+
+func (dt *decodingTree) add(c canonical.Code, symbol byte) {
+	current := dt
+
+	for i := c.Len; i > 0; i-- {
+		if c.Bits>>(i-1)&1 == 0 {
+			if current.zero == nil {
+				current.zero = &decodingTree{}
+			}
+			current = current.zero
+		} else {
+			if current.one == nil {
+				current.one = &decodingTree{}
+			}
+			current = current.one
+		}
+	}
+
+	current.leaf = true
+	current.symbol = symbol
+}
+
+// decode reads codes of n symbols from r, appends the symbols to dst and returns the extended slice.
+func (dt *decodingTree) decode(r *bitio.BitReader, n int, dst []byte) ([]byte, error) {
+	for i := 0; i < n; i++ {
+		current := dt
+		for !current.leaf {
+			one, err := r.ReadBit()
+			if err != nil {
+				return dst, bitio.NoEOF(err)
+			}
+
+			if one {
+				current = current.one
+			} else {
+				current = current.zero
+			}
+			if current == nil {
+				return dst, fmt.Errorf("%w: unknown code", ErrCorrupted)
+			}
+		}
+
+		dst = append(dst, current.symbol)
+	}
+
+	return dst, nil
+}
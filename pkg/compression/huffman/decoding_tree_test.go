@@ -0,0 +1,90 @@
+package huffman
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression/canonical"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestNewDecodingTree(t *testing.T) {
+	t.Parallel()
+
+	var c canonical.Codes
+	c['a'] = canonical.Code{Bits: 0b0, Len: 1}
+	c['r'] = canonical.Code{Bits: 0b10, Len: 2}
+	c['b'] = canonical.Code{Bits: 0b11, Len: 2}
+
+	want := &decodingTree{
+		zero: &decodingTree{leaf: true, symbol: 'a'},
+		one: &decodingTree{
+			zero: &decodingTree{leaf: true, symbol: 'r'},
+			one:  &decodingTree{leaf: true, symbol: 'b'},
+		},
+	}
+
+	assert.Equal(t, want, newDecodingTree(&c))
+}
+
+func TestDecodingTreeDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		lengths    map[byte]uint8
+		data       []byte
+	}{
+		{
+			want:    "",
+			lengths: map[byte]uint8{},
+			data:    []byte{},
+		},
+		{
+			want:    "aaa",
+			lengths: map[byte]uint8{'a': 1},
+			data:    []byte{0b00000000},
+		},
+		{
+			want:    "abracadabra",
+			lengths: map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4},
+			data:    []byte{0b01101001, 0b11001111, 0b01101000},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding %q", test.want)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var lengths canonical.Lengths
+			for b, l := range test.lengths {
+				lengths[b] = l
+			}
+			c, _ := lengths.Codes()
+
+			decoded, err := newDecodingTree(&c).decode(bitio.NewBitReader(bytes.NewReader(test.data)), len(test.want), []byte{})
+			assert.Nilf(t, err, "decodingTree(...).decode(%v) unexpected error", test.data)
+			assert.Equalf(t, []byte(test.want), decoded, "decodingTree(...).decode(%v)", test.data)
+		})
+	}
+}
+
+func TestDecodingTreeDecodeError(t *testing.T) {
+	t.Parallel()
+
+	var c canonical.Codes
+	c['a'] = canonical.Code{Bits: 0b0, Len: 1}
+	c['b'] = canonical.Code{Bits: 0b10, Len: 2}
+	dt := newDecodingTree(&c)
+
+	decoded, err := dt.decode(bitio.NewBitReader(bytes.NewReader([]byte{0b01011000})), 4, []byte{})
+	assert.Equal(t, []byte("ab"), decoded)
+	assert.ErrorIs(t, err, ErrCorrupted, "decoding code missing from the tree")
+
+	decoded, err = dt.decode(bitio.NewBitReader(bytes.NewReader([]byte{0b00000000})), 9, []byte{})
+	assert.Equal(t, []byte("aaaaaaaa"), decoded)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "decoding more codes than available")
+}
@@ -0,0 +1,177 @@
+package huffman
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/canonical"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		lengths   map[byte]uint8
+		want      string
+	}{
+		{str: "", want: ""},
+		{str: "aaa", lengths: map[byte]uint8{'a': 1}, want: "000"},
+		{
+			str:     "abracadabra",
+			lengths: map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4},
+			want:    "01101001110011110110100",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			bytes, err := New().Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, packed(test.str, test.lengths, test.want), bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		lengths    map[byte]uint8
+		bytes      string
+	}{
+		{want: "", bytes: ""},
+		{want: "aaa", lengths: map[byte]uint8{'a': 1}, bytes: "000"},
+		{
+			want:    "abracadabra",
+			lengths: map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4},
+			bytes:   "01101001110011110110100",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q", test.want)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := packed(test.want, test.lengths, test.bytes)
+			unpacked, err := New().Unpack(data)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", data)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", data)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "unicode text", data: []byte("Привет, мир ∑ π")},
+		{name: "every byte value", data: allBytes},
+		{name: "several blocks", data: bytes.Repeat([]byte("Some pretty SUBsequence, 42!\n"), 2*blockSize/29+1)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing and unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, err := New().Pack(test.data)
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.data)
+			unpacked, err := New().Unpack(packed)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+			assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.data)
+		})
+	}
+}
+
+func TestCodecCompressesNonEnglishText(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("Съешь же ещё этих мягких французских булок, да выпей чаю. ", 1000))
+
+	packed, err := New().Pack(data)
+	assert.Nil(t, err)
+	assert.Less(t, len(packed), len(data)*3/5, "Codec.Pack() must use frequencies of the data")
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte{0b00100010, 0b01101001, 0b01000000})
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.Huffman), err)
+}
+
+// header returns the header of data packed with huffman.
+func header(h compression.Header) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.Huffman
+	_ = compression.WriteHeader(&buf, h)
+
+	return buf.Bytes()
+}
+
+// block returns the block of the given size packed with the code of the given lengths,
+// bString is the string of the packed codes bits.
+func block(size int, lengths map[byte]uint8, bString string) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	buf = buf[:binary.PutUvarint(buf, uint64(size))]
+
+	var cl canonical.Lengths
+	for b, l := range lengths {
+		cl[b] = l
+	}
+
+	data := bytes.NewBuffer(buf)
+	w := bitio.NewBitWriter(data)
+	_ = canonical.Encode(w, &cl)
+	for _, c := range bString {
+		_ = w.WriteBit(c == '1')
+	}
+	_ = w.Flush()
+
+	return data.Bytes()
+}
+
+// endMark is the block marking the end of the packed data.
+var endMark = []byte{0}
+
+// packed returns the data written by Codec.Pack for str: the header, a single block, the end mark and the checksum.
+func packed(str string, lengths map[byte]uint8, bString string) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))})
+	if str != "" {
+		data = append(data, block(len(str), lengths, bString)...)
+	}
+	return append(append(data, endMark...), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
@@ -0,0 +1,123 @@
+package huffman
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/canonical"
+	"io"
+)
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header is read and validated on the first call to Read.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	br         *bitio.BitReader
+	buf        []byte
+	decoded    []byte
+	read       uint64
+	crc        uint32
+	err        error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	br := bitio.NewByteReader(r)
+
+	return &Reader{
+		r:  br,
+		br: bitio.NewBitReader(br),
+	}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.Header, z.err = compression.ReadCodecHeader(z.r, compression.Huffman)
+		z.readHeader = true
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.readBlock()
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.br.Reset(z.r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+// readBlock reads and decodes the next block, it returns io.EOF at the end of the data mark.
+func (z *Reader) readBlock() ([]byte, error) {
+	size, err := binary.ReadUvarint(z.br)
+	if err != nil {
+		return nil, bitio.NoEOF(err)
+	}
+
+	if size == 0 {
+		if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+			return nil, fmt.Errorf("%w: unpacked %d bytes, but header size is %d", ErrCorrupted, z.read, z.Header.Size)
+		}
+		if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if size > blockSize {
+		return nil, fmt.Errorf("%w: block of %d bytes is too long", ErrCorrupted, size)
+	}
+
+	z.buf, err = Decode(z.br, int(size), z.buf[:0])
+	if err != nil {
+		return nil, err
+	}
+	z.br.Align()
+
+	z.read += size
+	if z.Header.Flags&compression.FlagSize != 0 && z.read > z.Header.Size {
+		return nil, fmt.Errorf("%w: unpacked data exceeds header size %d", ErrCorrupted, z.Header.Size)
+	}
+
+	z.crc = compression.UpdateChecksum(z.crc, z.buf)
+
+	return z.buf, nil
+}
+
+// invalidTable reports malformed code table as corrupted data.
+func invalidTable(err error) error {
+	if errors.Is(err, canonical.ErrInvalid) {
+		return fmt.Errorf("%w: invalid code table", ErrCorrupted)
+	}
+	return err
+}
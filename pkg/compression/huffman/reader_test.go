@@ -0,0 +1,132 @@
+package huffman
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "several blocks", data: bytes.Repeat([]byte("abracadabra"), blockSize/11*3)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s byte by byte", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, _ := New().Pack(test.data)
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(packed)))
+			data, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, data)
+			assert.Equal(t, uint64(len(test.data)), r.Header.Size)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	abracadabra := map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4}
+	payload := "01101001110011110110100"
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name: "size is less than header size",
+			data: append(
+				append(header(compression.Header{Flags: compression.FlagSize, Size: 12}), block(11, abracadabra, payload)...),
+				endMark...,
+			),
+			err:   ErrCorrupted,
+			error: "huffman: packed data is corrupted: unpacked 11 bytes, but header size is 12",
+		},
+		{
+			name: "size exceeds header size",
+			data: append(
+				append(header(compression.Header{Flags: compression.FlagSize, Size: 10}), block(11, abracadabra, payload)...),
+				endMark...,
+			),
+			err:   ErrCorrupted,
+			error: "huffman: packed data is corrupted: unpacked data exceeds header size 10",
+		},
+		{
+			name:  "invalid code table",
+			data:  append(append(header(compression.Header{}), block(3, map[byte]uint8{'a': 1, 'b': 1, 'c': 1}, "0")...), endMark...),
+			err:   ErrCorrupted,
+			error: "huffman: packed data is corrupted: invalid code table",
+		},
+		{
+			name:  "too long block",
+			data:  append(append(header(compression.Header{}), block(blockSize+1, abracadabra, payload)...), endMark...),
+			err:   ErrCorrupted,
+			error: "huffman: packed data is corrupted: block of 131073 bytes is too long",
+		},
+		{
+			name:  "truncated block",
+			data:  append(header(compression.Header{}), block(11, abracadabra, payload[:4])...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "missing end mark",
+			data:  append(header(compression.Header{}), block(11, abracadabra, payload)...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	packed, _ := New().Pack([]byte("abracadabra"))
+	r := NewReader(bytes.NewReader(packed))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	first, _ := New().Pack([]byte("abracadabra"))
+	second, _ := New().Pack([]byte("Ted"))
+
+	r := NewReader(bytes.NewReader(first))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(second))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
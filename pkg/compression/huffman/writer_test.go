@@ -0,0 +1,119 @@
+package huffman
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+		lengths   map[byte]uint8
+		want      string
+	}{
+		{str: "", partSize: 1, want: ""},
+		{str: "aaa", partSize: 1, lengths: map[byte]uint8{'a': 1}, want: "000"},
+		{
+			str:      "abracadabra",
+			partSize: 4,
+			lengths:  map[byte]uint8{'a': 1, 'r': 2, 'b': 3, 'c': 4, 'd': 4},
+			want:     "01101001110011110110100",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewWriter(buf)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := header(compression.Header{})
+			if test.str != "" {
+				want = append(want, block(len(test.str), test.lengths, test.want)...)
+			}
+			assert.Equalf(t, append(want, endMark...), buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	_, _ = w.Write([]byte("aaa"))
+	assert.Nil(t, w.Flush())
+	want := append(header(compression.Header{}), block(3, map[byte]uint8{'a': 1}, "000")...)
+	assert.Equal(t, want, buf.Bytes(), "Writer.Flush() must write all packed data as a block")
+
+	_, _ = w.Write([]byte("ab"))
+	assert.Nil(t, w.Close())
+	want = append(want, block(2, map[byte]uint8{'a': 1, 'b': 1}, "01")...)
+	assert.Equal(t, append(want, endMark...), buf.Bytes())
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("aaa"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "huffman: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("aaa"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("abracadabra"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("aaa"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}), first.Bytes())
+	assert.Equal(
+		t,
+		append(append(header(compression.Header{}), block(3, map[byte]uint8{'a': 1}, "000")...), endMark...),
+		second.Bytes(),
+	)
+}
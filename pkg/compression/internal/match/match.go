@@ -0,0 +1,131 @@
+// Package match finds the matches of the LZ77 family codecs, the repeated strings of the data referring
+// to their previous occurrences within a window.
+//
+// The matches are found with hash chains: positions with the same hash of their first bytes are linked
+// from the latest to the earliest one.
+package match
+
+const (
+	hashBits = 15
+	// maxHashLen is the maximum number of the first bytes of a position hashed.
+	maxHashLen = 3
+	// maxChain is the maximum number of previous positions checked for a match, it bounds the time
+	// spent on highly repetitive data.
+	maxChain = 128
+)
+
+// Matcher finds the longest matches of minMatch to maxMatch bytes at distances up to windowSize.
+type Matcher struct {
+	minMatch, maxMatch, windowSize int
+	hashLen                        int
+	head                           []int32
+	prev                           []int32
+}
+
+// New returns a new Matcher of the matches of minMatch to maxMatch bytes at distances up to windowSize.
+func New(minMatch, maxMatch, windowSize int) *Matcher {
+	hashLen := minMatch
+	if hashLen > maxHashLen {
+		hashLen = maxHashLen
+	}
+
+	return &Matcher{
+		minMatch:   minMatch,
+		maxMatch:   maxMatch,
+		windowSize: windowSize,
+		hashLen:    hashLen,
+		head:       make([]int32, 1<<hashBits),
+	}
+}
+
+// Parse calls token for every literal and match of hist[start:] in order, matches refer to hist[:start] as well.
+// A literal is the byte hist[i] passed with zero dist and the length 1, a match is the length bytes
+// starting at hist[i] repeated from dist bytes back. Parse stops at the first error returned by token.
+func (m *Matcher) Parse(hist []byte, start int, token func(i, dist, length int) error) error {
+	m.reset(len(hist))
+	for i := 0; i < start; i++ {
+		m.insert(hist, i)
+	}
+
+	for i := start; i < len(hist); {
+		dist, length := m.find(hist, i)
+
+		if length < m.minMatch {
+			if err := token(i, 0, 1); err != nil {
+				return err
+			}
+			m.insert(hist, i)
+			i++
+			continue
+		}
+
+		if err := token(i, dist, length); err != nil {
+			return err
+		}
+		for end := i + length; i < end; i++ {
+			m.insert(hist, i)
+		}
+	}
+
+	return nil
+}
+
+// find returns the distance and the length of the longest match of hist[i:] within the window.
+func (m *Matcher) find(hist []byte, i int) (dist, length int) {
+	if i+m.hashLen > len(hist) {
+		return 0, 0
+	}
+
+	limit := len(hist) - i
+	if limit > m.maxMatch {
+		limit = m.maxMatch
+	}
+
+	for j, chain := m.head[m.hash(hist, i)], 0; j >= 0 && chain < maxChain; j, chain = m.prev[j], chain+1 {
+		if i-int(j) > m.windowSize {
+			break
+		}
+
+		n := 0
+		for n < limit && hist[int(j)+n] == hist[i+n] {
+			n++
+		}
+		if n > length {
+			dist, length = i-int(j), n
+			if n == limit {
+				break
+			}
+		}
+	}
+
+	return dist, length
+}
+
+func (m *Matcher) insert(hist []byte, i int) {
+	if i+m.hashLen > len(hist) {
+		return
+	}
+
+	h := m.hash(hist, i)
+	m.prev[i] = m.head[h]
+	m.head[h] = int32(i)
+}
+
+func (m *Matcher) hash(hist []byte, i int) uint32 {
+	var v uint32
+	for _, b := range hist[i : i+m.hashLen] {
+		v = v<<8 | uint32(b)
+	}
+	return v * 2654435761 >> (32 - hashBits)
+}
+
+func (m *Matcher) reset(n int) {
+	for i := range m.head {
+		m.head[i] = -1
+	}
+
+	if cap(m.prev) < n {
+		m.prev = make([]int32, n)
+	}
+	m.prev = m.prev[:n]
+}
@@ -0,0 +1,90 @@
+package match
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// parsed is a literal or a match passed to the token function of Parse.
+type parsed struct {
+	i, dist, length int
+}
+
+func TestMatcherParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, hist                     string
+		start                          int
+		minMatch, maxMatch, windowSize int
+		want                           []parsed
+	}{
+		{name: "empty data", minMatch: 3, maxMatch: 10, windowSize: 16},
+		{
+			name: "literals", hist: "abc", minMatch: 3, maxMatch: 10, windowSize: 16,
+			want: []parsed{{0, 0, 1}, {1, 0, 1}, {2, 0, 1}},
+		},
+		{
+			name: "overlapping match", hist: "aaaaaa", minMatch: 3, maxMatch: 10, windowSize: 16,
+			want: []parsed{{0, 0, 1}, {1, 1, 5}},
+		},
+		{
+			name: "match shorter than minimum", hist: "abcab", minMatch: 3, maxMatch: 10, windowSize: 16,
+			want: []parsed{{0, 0, 1}, {1, 0, 1}, {2, 0, 1}, {3, 0, 1}, {4, 0, 1}},
+		},
+		{
+			name: "match of minimum length 2", hist: "abcab", minMatch: 2, maxMatch: 10, windowSize: 16,
+			want: []parsed{{0, 0, 1}, {1, 0, 1}, {2, 0, 1}, {3, 3, 2}},
+		},
+		{
+			name: "longest match", hist: strings.Repeat("a", 12), minMatch: 3, maxMatch: 5, windowSize: 16,
+			want: []parsed{{0, 0, 1}, {1, 1, 5}, {6, 1, 5}, {11, 0, 1}},
+		},
+		{
+			name: "match within history", hist: "abcd" + "xbcd", start: 4, minMatch: 3, maxMatch: 10, windowSize: 16,
+			want: []parsed{{4, 0, 1}, {5, 4, 3}},
+		},
+		{
+			name: "match at window edge", hist: "abc" + strings.Repeat("x", 13) + "abc", start: 16,
+			minMatch: 3, maxMatch: 10, windowSize: 16,
+			want: []parsed{{16, 16, 3}},
+		},
+		{
+			name: "match beyond window", hist: "abc" + strings.Repeat("x", 14) + "abc", start: 17,
+			minMatch: 3, maxMatch: 10, windowSize: 16,
+			want: []parsed{{17, 0, 1}, {18, 0, 1}, {19, 0, 1}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("parsing %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []parsed
+			m := New(test.minMatch, test.maxMatch, test.windowSize)
+			assert.Nil(t, m.Parse([]byte(test.hist), test.start, func(i, dist, length int) error {
+				got = append(got, parsed{i, dist, length})
+				return nil
+			}))
+			assert.Equalf(t, test.want, got, "Matcher.Parse(%q, %d)", test.hist, test.start)
+		})
+	}
+}
+
+func TestMatcherParseError(t *testing.T) {
+	t.Parallel()
+
+	errToken := errors.New("token error")
+	calls := 0
+	err := New(3, 10, 16).Parse([]byte("abcabc"), 0, func(i, dist, length int) error {
+		calls++
+		return errToken
+	})
+	assert.ErrorIs(t, err, errToken)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,76 @@
+package lzss
+
+import (
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+func init() {
+	compression.Register("lzss", compression.Factory{
+		Description: "references to the previous occurrences of repeated substrings",
+		Extension:   "lzss",
+		Magic:       compression.HeaderMagic(compression.LZSS),
+		Pack: func(fs *flag.FlagSet) compression.WriterFunc {
+			opts := DefaultOptions
+			compression.RangeVar(
+				fs, &opts.WindowBits, "window-bits", opts.WindowBits, MinWindowBits, MaxWindowBits,
+				"binary logarithm of the sliding window size, from 8 to 16",
+			)
+			compression.RangeVar(
+				fs, &opts.MinMatch, "min-match", opts.MinMatch, MinMatchLen, MaxMinMatch,
+				"minimum length of a repeated substring replaced with a reference, from 2 to 16",
+			)
+
+			return func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+				return newWriter(w, h, opts)
+			}
+		},
+		Unpack: func(_ *flag.FlagSet) compression.ReaderFunc {
+			return newReader
+		},
+	})
+}
+
+type Codec struct {
+	opts Options
+}
+
+// New returns the Codec packing with DefaultOptions.
+func New() Codec {
+	return Codec{opts: DefaultOptions}
+}
+
+// NewOptions returns the Codec packing with the given options, it returns an error if they are out of range.
+func NewOptions(opts Options) (Codec, error) {
+	if err := opts.validate(); err != nil {
+		return Codec{}, fmt.Errorf("lzss: %w", err)
+	}
+	return Codec{opts: opts}, nil
+}
+
+func (c Codec) Pack(data []byte) ([]byte, error) {
+	return compression.PackWith(func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+		return newWriter(w, h, c.opts)
+	}, data)
+}
+
+func (_ Codec) Unpack(data []byte) ([]byte, error) {
+	return compression.UnpackWith(newReader, data)
+}
+
+// newWriter returns the Writer packing the data to w with the Header and the options.
+func newWriter(w io.Writer, h compression.Header, opts Options) (io.WriteCloser, error) {
+	z, err := NewWriterOptions(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	z.Header = h
+	return z, nil
+}
+
+// newReader returns the Reader unpacking the data read from r.
+func newReader(r io.Reader) io.ReadCloser {
+	return NewReader(r)
+}
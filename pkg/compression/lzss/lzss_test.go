@@ -0,0 +1,243 @@
+package lzss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, bString string
+		opts               Options
+	}{
+		{str: "", opts: DefaultOptions},
+		{
+			str:     "abcabcabc",
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "101100010" + "101100011" + "0" + "00000010" + "00000011",
+		},
+		{
+			str:     "abcabcabc",
+			opts:    DefaultOptions,
+			bString: "101100001" + "101100010" + "101100011" + "0" + "000000000000010" + "00000011",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q with window of %d bits", test.str, test.opts.WindowBits)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewOptions(test.opts)
+			assert.Nil(t, err)
+			bytes, err := c.Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, packed(test.str, test.opts, test.bString), bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want, bString string
+		opts                Options
+	}{
+		{want: "", opts: DefaultOptions},
+		{
+			want:    "abcabcabc",
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "101100010" + "101100011" + "0" + "00000010" + "00000011",
+		},
+		{
+			want:    "abcab",
+			opts:    Options{WindowBits: 8, MinMatch: 2},
+			bString: "101100001" + "101100010" + "101100011" + "0" + "00000010" + "00000000",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q", test.want)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := packed(test.want, test.opts, test.bString)
+			unpacked, err := New().Unpack(data)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", data)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", data)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+	random := make([]byte, 3*blockSize)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+		opts Options
+	}{
+		{name: "text", data: []byte("Hello, World! Hello, World! 1 + 2 = 3.\n"), opts: DefaultOptions},
+		{name: "every byte value", data: allBytes, opts: DefaultOptions},
+		{name: "random data", data: random, opts: DefaultOptions},
+		{name: "log lines", data: logLines(5000), opts: DefaultOptions},
+		{name: "log lines with the smallest window", data: logLines(5000), opts: Options{WindowBits: MinWindowBits, MinMatch: 3}},
+		{name: "log lines with the largest window", data: logLines(5000), opts: Options{WindowBits: MaxWindowBits, MinMatch: 3}},
+		{name: "log lines with the shortest match", data: logLines(5000), opts: Options{WindowBits: 12, MinMatch: MinMatchLen}},
+		{name: "log lines with the longest minimum match", data: logLines(5000), opts: Options{WindowBits: 12, MinMatch: MaxMinMatch}},
+		{name: "long runs", data: bytes.Repeat([]byte{0}, 3*blockSize+7), opts: DefaultOptions},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing and unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewOptions(test.opts)
+			assert.Nil(t, err)
+			packed, err := c.Pack(test.data)
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.name)
+			unpacked, err := c.Unpack(packed)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", test.name)
+			assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.name)
+		})
+	}
+}
+
+func TestCodecCompressesRepeats(t *testing.T) {
+	t.Parallel()
+
+	data := logLines(5000)
+
+	packed, err := New().Pack(data)
+	assert.Nil(t, err)
+	assert.Less(t, len(packed), len(data)/4, "Codec.Pack() must replace repeated substrings with matches")
+}
+
+func TestNewOptionsError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opts  Options
+		error string
+	}{
+		{opts: Options{}, error: "lzss: window bits 0 out of range [8, 16]"},
+		{opts: Options{WindowBits: 17, MinMatch: 3}, error: "lzss: window bits 17 out of range [8, 16]"},
+		{opts: Options{WindowBits: 12, MinMatch: 1}, error: "lzss: minimum match 1 out of range [2, 16]"},
+		{opts: Options{WindowBits: 12, MinMatch: 17}, error: "lzss: minimum match 17 out of range [2, 16]"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("creating codec with %+v", test.opts), func(t *testing.T) {
+			t.Parallel()
+			_, err := NewOptions(test.opts)
+			assert.EqualErrorf(t, err, test.error, "NewOptions(%v) unexpected error", test.opts)
+		})
+	}
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte("abcabcabc"))
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.LZSS), err)
+}
+
+func TestRegisteredFlags(t *testing.T) {
+	t.Parallel()
+
+	f, _ := compression.Lookup("lzss")
+
+	fs := flag.NewFlagSet("lzss", flag.ContinueOnError)
+	newWriter := f.Pack(fs)
+	assert.Nil(t, fs.Parse([]string{"-window-bits", "10", "-min-match", "4"}))
+
+	var buf bytes.Buffer
+	w, err := newWriter(&buf, compression.Header{})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("abcabcabc"))
+	assert.Nil(t, w.Close())
+	want := header(compression.Header{}, Options{WindowBits: 10, MinMatch: 4})
+	assert.Equal(t, want, buf.Bytes()[:len(want)], "the registered writer must pack with the options of the flags")
+
+	unpacked, err := io.ReadAll(f.Unpack(flag.NewFlagSet("lzss", flag.ContinueOnError))(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabc"), unpacked)
+}
+
+// logLines returns n lines looking like a real log.
+func logLines(n int) []byte {
+	levels := []string{"INFO", "WARN", "ERROR", "DEBUG"}
+	rnd := rand.New(rand.NewSource(1))
+
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(
+			&buf, "2022-05-%02d 12:%02d:%02d [%s] request %d handled by worker %d in %dms\n",
+			1+i/1000, i/60%60, i%60, levels[rnd.Intn(len(levels))], i, rnd.Intn(8), rnd.Intn(500),
+		)
+	}
+
+	return buf.Bytes()
+}
+
+// header returns the header of data packed with lzss followed by the options.
+func header(h compression.Header, opts Options) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.LZSS
+	_ = compression.WriteHeader(&buf, h)
+
+	return append(buf.Bytes(), byte(opts.WindowBits), byte(opts.MinMatch))
+}
+
+// block returns the block of the given size, bString is the string of the tokens bits.
+func block(size int, bString string) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	buf = buf[:binary.PutUvarint(buf, uint64(size))]
+
+	return append(buf, fromBitString(bString)...)
+}
+
+// endMark is the block marking the end of the packed data.
+var endMark = []byte{0}
+
+// packed returns the data written by Codec.Pack for str: the header, the options, a single block, the end mark
+// and the checksum.
+func packed(str string, opts Options, bString string) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))}, opts)
+	if str != "" {
+		data = append(data, block(len(str), bString)...)
+	}
+	return append(append(data, endMark...), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
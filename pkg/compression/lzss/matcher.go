@@ -0,0 +1,34 @@
+package lzss
+
+import (
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression/internal/match"
+)
+
+// matcher finds the matches within the window of the options.
+type matcher struct {
+	*match.Matcher
+	opts Options
+}
+
+func newMatcher(opts Options) *matcher {
+	return &matcher{
+		Matcher: match.New(opts.MinMatch, opts.maxMatch(), opts.windowSize()),
+		opts:    opts,
+	}
+}
+
+// encode writes tokens of hist[start:] to w, matches refer to hist[:start] as well.
+//
+// A token is bit 1 followed by a literal byte, or bit 0 followed by the match offset minus one
+// (WindowBits bits) and the match length minus MinMatch (lengthBits bits).
+func (m *matcher) encode(w *bitio.BitWriter, hist []byte, start int) error {
+	return m.Parse(hist, start, func(i, offset, length int) error {
+		if offset == 0 {
+			return w.WriteBits(1<<8|uint64(hist[i]), 9)
+		}
+
+		token := uint64(offset-1)<<lengthBits | uint64(length-m.opts.MinMatch)
+		return w.WriteBits(token, 1+m.opts.WindowBits+lengthBits)
+	})
+}
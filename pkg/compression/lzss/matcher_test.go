@@ -0,0 +1,100 @@
+package lzss
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMatcherEncode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, hist, bString string
+		start               int
+		opts                Options
+	}{
+		{name: "empty data", opts: Options{WindowBits: 8, MinMatch: 3}},
+		{
+			name:    "literals",
+			hist:    "abc",
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "101100010" + "101100011",
+		},
+		{
+			name:    "overlapping match",
+			hist:    "abcabcabc",
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "101100010" + "101100011" + "0" + "00000010" + "00000011",
+		},
+		{
+			name:    "match shorter than minimum",
+			hist:    "abcab",
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "101100010" + "101100011" + "101100001" + "101100010",
+		},
+		{
+			name:    "match of minimum length 2",
+			hist:    "abcab",
+			opts:    Options{WindowBits: 8, MinMatch: 2},
+			bString: "101100001" + "101100010" + "101100011" + "0" + "00000010" + "00000000",
+		},
+		{
+			name:    "match within history",
+			hist:    "abcd" + "xbcd",
+			start:   4,
+			opts:    Options{WindowBits: 9, MinMatch: 3},
+			bString: "101111000" + "0" + "000000011" + "00000000",
+		},
+		{
+			name:    "match beyond window",
+			hist:    "abc" + strings.Repeat("x", 254) + "abc",
+			start:   257,
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "101100010" + "101100011",
+		},
+		{
+			name:    "match at window edge",
+			hist:    "abc" + strings.Repeat("x", 253) + "abc",
+			start:   256,
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "0" + "11111111" + "00000000",
+		},
+		{
+			name:    "longest match",
+			hist:    strings.Repeat("a", 260),
+			opts:    Options{WindowBits: 8, MinMatch: 3},
+			bString: "101100001" + "0" + "00000000" + "11111111" + "101100001",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encoding %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := bitio.NewBitWriter(buf)
+			err := newMatcher(test.opts).encode(w, []byte(test.hist), test.start)
+			assert.Nilf(t, err, "matcher.encode(%v) unexpected error", test.hist)
+			assert.Equalf(t, uint64(len(test.bString)), w.Count(), "matcher.encode(%v) written bits", test.hist)
+			assert.Nil(t, w.Flush())
+			assert.Equalf(t, fromBitString(test.bString), buf.Bytes(), "matcher.encode(%v)", test.hist)
+		})
+	}
+}
+
+// fromBitString packs a string of '0' and '1' to bytes, the last byte is padded with zeros.
+func fromBitString(bString string) []byte {
+	data := make([]byte, (len(bString)+7)/8)
+	for i, c := range bString {
+		if c == '1' {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return data
+}
@@ -0,0 +1,45 @@
+package lzss
+
+import "fmt"
+
+const (
+	MinWindowBits = 8
+	MaxWindowBits = 16
+
+	MinMatchLen = 2
+	MaxMinMatch = 16
+)
+
+// lengthBits is the number of bits storing the length of a match above the minimum one.
+const lengthBits = 8
+
+// Options configure the sliding window and the matches of the Writer.
+// The Reader gets them from the packed data.
+type Options struct {
+	// WindowBits is the binary logarithm of the sliding window size, a match refers to at most that many
+	// previous bytes.
+	WindowBits uint
+	// MinMatch is the minimum length of a match, shorter repeats are written as literals.
+	MinMatch int
+}
+
+// DefaultOptions are the options of the Writer returned by NewWriter.
+var DefaultOptions = Options{WindowBits: 15, MinMatch: 3}
+
+func (o Options) validate() error {
+	if o.WindowBits < MinWindowBits || o.WindowBits > MaxWindowBits {
+		return fmt.Errorf("window bits %d out of range [%d, %d]", o.WindowBits, MinWindowBits, MaxWindowBits)
+	}
+	if o.MinMatch < MinMatchLen || o.MinMatch > MaxMinMatch {
+		return fmt.Errorf("minimum match %d out of range [%d, %d]", o.MinMatch, MinMatchLen, MaxMinMatch)
+	}
+	return nil
+}
+
+func (o Options) windowSize() int {
+	return 1 << o.WindowBits
+}
+
+func (o Options) maxMatch() int {
+	return o.MinMatch + 1<<lengthBits - 1
+}
@@ -0,0 +1,186 @@
+package lzss
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header and the options are read and validated on the first call to Read.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	opts       Options
+	br         *bitio.BitReader
+	// hist holds the window of the previous blocks followed by the last unpacked block.
+	hist    []byte
+	decoded []byte
+	read    uint64
+	crc     uint32
+	err     error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	br := bitio.NewByteReader(r)
+
+	return &Reader{
+		r:  br,
+		br: bitio.NewBitReader(br),
+	}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.err = z.readHeaderOptions()
+		z.readHeader = true
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.readBlock()
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.br.Reset(z.r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.hist = z.hist[:0]
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Reader) readHeaderOptions() error {
+	var err error
+
+	z.Header, err = compression.ReadCodecHeader(z.r, compression.LZSS)
+	if err != nil {
+		return err
+	}
+
+	var buf [2]byte
+	if _, err = io.ReadFull(z.r, buf[:]); err != nil {
+		return bitio.NoEOF(err)
+	}
+	z.opts = Options{WindowBits: uint(buf[0]), MinMatch: int(buf[1])}
+	if err = z.opts.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	if cap(z.hist) < z.opts.windowSize()+blockSize {
+		z.hist = make([]byte, 0, z.opts.windowSize()+blockSize)
+	}
+
+	return nil
+}
+
+// readBlock reads and decodes the next block, it returns io.EOF at the end of the data mark.
+func (z *Reader) readBlock() ([]byte, error) {
+	size, err := binary.ReadUvarint(z.br)
+	if err != nil {
+		return nil, bitio.NoEOF(err)
+	}
+
+	if size == 0 {
+		if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+			return nil, fmt.Errorf("%w: unpacked %d bytes, but header size is %d", ErrCorrupted, z.read, z.Header.Size)
+		}
+		if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if size > blockSize {
+		return nil, fmt.Errorf("%w: block of %d bytes is too long", ErrCorrupted, size)
+	}
+
+	keep := len(z.hist)
+	if keep > z.opts.windowSize() {
+		keep = z.opts.windowSize()
+	}
+	z.hist = z.hist[:copy(z.hist, z.hist[len(z.hist)-keep:])]
+
+	if z.hist, err = z.decode(z.hist, int(size)); err != nil {
+		return nil, err
+	}
+	z.br.Align()
+
+	z.read += size
+	if z.Header.Flags&compression.FlagSize != 0 && z.read > z.Header.Size {
+		return nil, fmt.Errorf("%w: unpacked data exceeds header size %d", ErrCorrupted, z.Header.Size)
+	}
+
+	z.crc = compression.UpdateChecksum(z.crc, z.hist[keep:])
+
+	return z.hist[keep:], nil
+}
+
+// decode reads tokens of n bytes written by matcher.encode, appends the bytes to hist and returns
+// the extended slice.
+func (z *Reader) decode(hist []byte, n int) ([]byte, error) {
+	for end := len(hist) + n; len(hist) < end; {
+		literal, err := z.br.ReadBit()
+		if err != nil {
+			return hist, bitio.NoEOF(err)
+		}
+
+		if literal {
+			b, err := z.br.ReadBits(8)
+			if err != nil {
+				return hist, bitio.NoEOF(err)
+			}
+			hist = append(hist, byte(b))
+			continue
+		}
+
+		token, err := z.br.ReadBits(z.opts.WindowBits + lengthBits)
+		if err != nil {
+			return hist, bitio.NoEOF(err)
+		}
+		offset := int(token>>lengthBits) + 1
+		length := int(token&(1<<lengthBits-1)) + z.opts.MinMatch
+
+		if offset > len(hist) {
+			return hist, fmt.Errorf("%w: match offset %d exceeds unpacked data", ErrCorrupted, offset)
+		}
+		if length > end-len(hist) {
+			return hist, fmt.Errorf("%w: match exceeds block", ErrCorrupted)
+		}
+
+		// the match may overlap the bytes it produces, so it is copied byte by byte
+		for from := len(hist) - offset; length > 0; from, length = from+1, length-1 {
+			hist = append(hist, hist[from])
+		}
+	}
+
+	return hist, nil
+}
@@ -0,0 +1,144 @@
+package lzss
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! Hello, World!\n")},
+		{name: "several blocks", data: logLines(10000)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s byte by byte", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, _ := New().Pack(test.data)
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(packed)))
+			data, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, data)
+			assert.Equal(t, uint64(len(test.data)), r.Header.Size)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{WindowBits: 8, MinMatch: 3}
+	abc := "101100001" + "101100010" + "101100011"
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "invalid options",
+			data:  append(header(compression.Header{}, Options{WindowBits: 20, MinMatch: 3}), endMark...),
+			err:   ErrCorrupted,
+			error: "lzss: packed data is corrupted: window bits 20 out of range [8, 16]",
+		},
+		{
+			name:  "missing options",
+			data:  header(compression.Header{}, opts)[:compression.HeaderSize+1],
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "size is less than header size",
+			data:  append(append(header(compression.Header{Flags: compression.FlagSize, Size: 4}, opts), block(3, abc)...), endMark...),
+			err:   ErrCorrupted,
+			error: "lzss: packed data is corrupted: unpacked 3 bytes, but header size is 4",
+		},
+		{
+			name:  "size exceeds header size",
+			data:  append(append(header(compression.Header{Flags: compression.FlagSize, Size: 2}, opts), block(3, abc)...), endMark...),
+			err:   ErrCorrupted,
+			error: "lzss: packed data is corrupted: unpacked data exceeds header size 2",
+		},
+		{
+			name:  "match offset beyond data",
+			data:  append(append(header(compression.Header{}, opts), block(6, abc+"0"+"00000011"+"00000000")...), endMark...),
+			err:   ErrCorrupted,
+			error: "lzss: packed data is corrupted: match offset 4 exceeds unpacked data",
+		},
+		{
+			name:  "match beyond block",
+			data:  append(append(header(compression.Header{}, opts), block(5, abc+"0"+"00000010"+"00000000")...), endMark...),
+			err:   ErrCorrupted,
+			error: "lzss: packed data is corrupted: match exceeds block",
+		},
+		{
+			name:  "too long block",
+			data:  append(append(header(compression.Header{}, opts), block(blockSize+1, abc)...), endMark...),
+			err:   ErrCorrupted,
+			error: "lzss: packed data is corrupted: block of 65537 bytes is too long",
+		},
+		{
+			name:  "truncated block",
+			data:  append(header(compression.Header{}, opts), block(4, abc)...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "missing end mark",
+			data:  append(header(compression.Header{}, opts), block(3, abc)...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	packed, _ := New().Pack([]byte("abcabcabc"))
+	r := NewReader(bytes.NewReader(packed))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	first, _ := New().Pack([]byte("abcabcabc"))
+	second, _ := New().Pack([]byte("Ted"))
+
+	r := NewReader(bytes.NewReader(first))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(second))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
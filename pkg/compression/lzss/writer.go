@@ -0,0 +1,214 @@
+package lzss
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// blockSize is the number of bytes the Writer collects before packing them as a block.
+const blockSize = 64 * 1024
+
+var (
+	ErrClosed    = errors.New("lzss: stream is closed")
+	ErrCorrupted = errors.New("lzss: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it replacing repeated substrings with references
+// to their previous occurrences within the sliding window.
+//
+// The Header is followed by the options: WindowBits and MinMatch, a byte each. Then the data is packed by blocks:
+// the number of bytes in the block as uvarint and the tokens padded to whole bytes. Matches may refer to
+// the previous blocks. An empty block marks the end of the data.
+//
+// With FlagChecksum set in the Header, the packed data is followed by the checksum of the original data.
+//
+// The Header is written before the first block, so its Size and Flags may be set until the first call
+// to Write, Flush or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           io.Writer
+	wroteHeader bool
+	opts        Options
+	matcher     *matcher
+	bw          *bitio.BitWriter
+	// hist holds the window of the already packed data followed by the data to pack from start.
+	hist    []byte
+	start   int
+	written uint64
+	crc     uint32
+	err     error
+}
+
+// NewWriter returns a new Writer packing the data to w with DefaultOptions.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	z, _ := NewWriterOptions(w, DefaultOptions)
+	return z
+}
+
+// NewWriterOptions is like NewWriter but packs with the given options.
+//
+// It returns an error if the options are out of range.
+func NewWriterOptions(w io.Writer, opts Options) (*Writer, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("lzss: %w", err)
+	}
+
+	return &Writer{
+		w:       w,
+		opts:    opts,
+		matcher: newMatcher(opts),
+		bw:      bitio.NewBitWriter(w),
+		hist:    make([]byte, 0, opts.windowSize()+blockSize),
+	}, nil
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	for written := 0; written < len(p); {
+		n := copy(z.hist[len(z.hist):z.start+blockSize], p[written:])
+		z.hist = z.hist[:len(z.hist)+n]
+
+		if len(z.hist) == z.start+blockSize {
+			if z.err = z.writeBlock(); z.err != nil {
+				return written, z.err
+			}
+		}
+
+		z.crc = compression.UpdateChecksum(z.crc, p[written:written+n])
+		written += n
+		z.written += uint64(n)
+	}
+
+	return len(p), nil
+}
+
+// Flush packs all the data written so far as a block and writes it to the underlying writer.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.err = z.writeBlock()
+	return z.err
+}
+
+// Close writes the rest of the packed data and the end of the data mark to the underlying writer.
+// It does not close the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeUvarint(0); z.err != nil {
+		return z.err
+	}
+	if z.err = z.bw.Flush(); z.err != nil {
+		return z.err
+	}
+	if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+		return z.err
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("lzss: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriterOptions(w, opts)
+// with the options of the Writer.
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.bw.Reset(w)
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.hist = z.hist[:0]
+	z.start = 0
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.LZSS
+	z.wroteHeader = true
+
+	if err := compression.WriteHeader(z.w, z.Header); err != nil {
+		return err
+	}
+	_, err := z.w.Write([]byte{byte(z.opts.WindowBits), byte(z.opts.MinMatch)})
+	return err
+}
+
+// writeBlock packs the data collected since the previous block as a block, if any,
+// and slides the window to its end.
+func (z *Writer) writeBlock() error {
+	if len(z.hist) == z.start {
+		return nil
+	}
+
+	if err := z.writeUvarint(uint64(len(z.hist) - z.start)); err != nil {
+		return err
+	}
+	if err := z.matcher.encode(z.bw, z.hist, z.start); err != nil {
+		return err
+	}
+	if err := z.bw.Flush(); err != nil {
+		return err
+	}
+
+	keep := len(z.hist)
+	if keep > z.opts.windowSize() {
+		keep = z.opts.windowSize()
+	}
+	z.hist = z.hist[:copy(z.hist, z.hist[len(z.hist)-keep:])]
+	z.start = keep
+
+	return nil
+}
+
+func (z *Writer) writeUvarint(v uint64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	for _, b := range buf[:binary.PutUvarint(buf, v)] {
+		if err := z.bw.WriteBits(uint64(b), 8); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package lzss
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, bString string
+		partSize           int
+	}{
+		{str: "", partSize: 1},
+		{
+			str:      "abcabcabc",
+			partSize: 1,
+			bString:  "101100001" + "101100010" + "101100011" + "0" + "000000000000010" + "00000011",
+		},
+		{
+			str:      "abcabcabc",
+			partSize: 4,
+			bString:  "101100001" + "101100010" + "101100011" + "0" + "000000000000010" + "00000011",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewWriter(buf)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := header(compression.Header{}, DefaultOptions)
+			if test.str != "" {
+				want = append(want, block(len(test.str), test.bString)...)
+			}
+			assert.Equalf(t, append(want, endMark...), buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w, _ := NewWriterOptions(&buf, Options{WindowBits: 8, MinMatch: 3})
+
+	_, _ = w.Write([]byte("abc"))
+	assert.Nil(t, w.Flush())
+	want := append(
+		header(compression.Header{}, Options{WindowBits: 8, MinMatch: 3}),
+		block(3, "101100001"+"101100010"+"101100011")...,
+	)
+	assert.Equal(t, want, buf.Bytes(), "Writer.Flush() must write all packed data as a block")
+
+	_, _ = w.Write([]byte("abcabc"))
+	assert.Nil(t, w.Close())
+	want = append(want, block(6, "0"+"00000010"+"00000011")...)
+	assert.Equal(t, append(want, endMark...), buf.Bytes(), "matches must refer to the previous blocks")
+}
+
+func TestWriterSlidesWindow(t *testing.T) {
+	t.Parallel()
+
+	data := logLines(20000)
+	assert.Greater(t, len(data), 4*blockSize)
+
+	for _, opts := range []Options{{WindowBits: 8, MinMatch: 3}, DefaultOptions} {
+		var buf bytes.Buffer
+		w, _ := NewWriterOptions(&buf, opts)
+		for part := data; len(part) > 0; {
+			n := 1000
+			if n > len(part) {
+				n = len(part)
+			}
+			_, _ = w.Write(part[:n])
+			part = part[n:]
+		}
+		assert.Nil(t, w.Close())
+
+		unpacked, err := New().Unpack(buf.Bytes())
+		assert.Nil(t, err)
+		assert.Equalf(t, data, unpacked, "unpacking data packed with %+v", opts)
+	}
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("abc"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "lzss: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("abc"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("abc"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("abc"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}, DefaultOptions), first.Bytes())
+	assert.Equal(
+		t,
+		append(append(header(compression.Header{}, DefaultOptions), block(3, "101100001"+"101100010"+"101100011")...), endMark...),
+		second.Bytes(),
+		"Writer.Reset() must discard the window",
+	)
+}
+
+func TestNewWriterOptionsError(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewWriterOptions(&bytes.Buffer{}, Options{WindowBits: 4, MinMatch: 3})
+	assert.Nil(t, w)
+	assert.EqualError(t, err, "lzss: window bits 4 out of range [8, 16]")
+}
@@ -0,0 +1,94 @@
+package lzw
+
+import (
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/std"
+	"io"
+)
+
+func init() {
+	compression.Register("lzw", compression.Factory{
+		Description: "Lempel-Ziv-Welch codes",
+		Extension:   "lzw",
+		Magic:       compression.HeaderMagic(compression.LZW),
+		Pack: func(fs *flag.FlagSet) compression.WriterFunc {
+			opts := DefaultOptions
+			compression.RangeVar(
+				fs, &opts.MaxWidth, "max-width", opts.MaxWidth, MinCodeWidth, MaxCodeWidth,
+				"maximum width of a code in bits, from 9 to 16",
+			)
+			fs.BoolVar(
+				&opts.Compatible, "compatible", opts.Compatible,
+				"write bare 12-bit codes readable by Go compress/lzw with MSB order, implies --max-width 12",
+			)
+
+			return func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+				opts := opts
+				if opts.Compatible {
+					opts.MaxWidth = CompatibleWidth
+				}
+				return newWriter(w, h, opts)
+			}
+		},
+		Unpack: func(fs *flag.FlagSet) compression.ReaderFunc {
+			var compatible bool
+			fs.BoolVar(
+				&compatible, "compatible", false,
+				"read bare codes with Go compress/lzw with MSB order, as written by pack lzw --compatible",
+			)
+
+			return func(r io.Reader) io.ReadCloser {
+				if compatible {
+					return std.NewReader(r, std.LZW)
+				}
+				return NewReader(r)
+			}
+		},
+	})
+}
+
+type Codec struct {
+	opts Options
+}
+
+// New returns the Codec packing with DefaultOptions.
+func New() Codec {
+	return Codec{opts: DefaultOptions}
+}
+
+// NewOptions returns the Codec packing with the given options, it returns an error if they are invalid.
+//
+// The Codec with Options.Compatible packs the data readable by compress/lzw only, it can't unpack it.
+func NewOptions(opts Options) (Codec, error) {
+	if err := opts.validate(); err != nil {
+		return Codec{}, fmt.Errorf("lzw: %w", err)
+	}
+	return Codec{opts: opts}, nil
+}
+
+func (c Codec) Pack(data []byte) ([]byte, error) {
+	return compression.PackWith(func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+		return newWriter(w, h, c.opts)
+	}, data)
+}
+
+func (_ Codec) Unpack(data []byte) ([]byte, error) {
+	return compression.UnpackWith(newReader, data)
+}
+
+// newWriter returns the Writer packing the data to w with the Header and the options.
+func newWriter(w io.Writer, h compression.Header, opts Options) (io.WriteCloser, error) {
+	z, err := NewWriterOptions(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	z.Header = h
+	return z, nil
+}
+
+// newReader returns the Reader unpacking the data read from r.
+func newReader(r io.Reader) io.ReadCloser {
+	return NewReader(r)
+}
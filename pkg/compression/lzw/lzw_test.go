@@ -0,0 +1,266 @@
+package lzw
+
+import (
+	"bytes"
+	stdlzw "compress/lzw"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		codes     []uint32
+	}{
+		{str: "", codes: []uint32{clearCode, eofCode}},
+		{str: "a", codes: []uint32{clearCode, 'a', eofCode}},
+		{str: "abababab", codes: []uint32{clearCode, 'a', 'b', 258, 260, 'b', eofCode}},
+		{str: "aaaaaa", codes: []uint32{clearCode, 'a', 258, 259, eofCode}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			bytes, err := New().Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, packed(test.str, MaxCodeWidth, test.codes...), bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		codes      []uint32
+	}{
+		{want: "", codes: []uint32{clearCode, eofCode}},
+		{want: "", codes: []uint32{eofCode}},
+		{want: "abababab", codes: []uint32{clearCode, 'a', 'b', 258, 260, 'b', eofCode}},
+		{want: "aaaaaa", codes: []uint32{'a', 258, 259, eofCode}},
+		{want: "abab", codes: []uint32{clearCode, 'a', 'b', clearCode, 'a', 'b', eofCode}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q from codes %v", test.want, test.codes)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := packed(test.want, MaxCodeWidth, test.codes...)
+			unpacked, err := New().Unpack(data)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", data)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", data)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+	random := make([]byte, 200*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+	text := []byte(strings.Repeat("Some pretty SUBsequence of words, repeated many times. ", 10000))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "every byte value", data: allBytes},
+		{name: "random data", data: random},
+		{name: "text", data: text},
+		{name: "long run", data: bytes.Repeat([]byte{'a'}, 1<<20)},
+	}
+
+	for _, test := range tests {
+		test := test
+		for width := uint(MinCodeWidth); width <= MaxCodeWidth; width++ {
+			width := width
+			t.Run(fmt.Sprintf("packing and unpacking %s with codes up to %d bits", test.name, width), func(t *testing.T) {
+				t.Parallel()
+				c, err := NewOptions(Options{MaxWidth: width})
+				assert.Nil(t, err)
+				packed, err := c.Pack(test.data)
+				assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.name)
+				unpacked, err := c.Unpack(packed)
+				assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", test.name)
+				assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.name)
+			})
+		}
+	}
+}
+
+func TestCodecCompatible(t *testing.T) {
+	t.Parallel()
+
+	random := make([]byte, 200*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "single byte", data: []byte{'a'}},
+		{name: "random data", data: random},
+		{name: "text", data: []byte(strings.Repeat("Some pretty SUBsequence of words, repeated many times. ", 10000))},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %s readable by compress/lzw", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewOptions(Options{MaxWidth: CompatibleWidth, Compatible: true})
+			assert.Nil(t, err)
+			packed, err := c.Pack(test.data)
+			assert.Nil(t, err)
+
+			var want bytes.Buffer
+			w := stdlzw.NewWriter(&want, stdlzw.MSB, 8)
+			_, _ = w.Write(test.data)
+			_ = w.Close()
+			assert.Equal(t, want.Bytes(), packed, "Codec.Pack() must write the same codes as compress/lzw")
+
+			unpacked, err := io.ReadAll(stdlzw.NewReader(bytes.NewReader(packed), stdlzw.MSB, 8))
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, unpacked)
+		})
+	}
+}
+
+func TestNewOptionsError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opts  Options
+		error string
+	}{
+		{opts: Options{}, error: "lzw: max code width 0 out of range [9, 16]"},
+		{opts: Options{MaxWidth: 8}, error: "lzw: max code width 8 out of range [9, 16]"},
+		{opts: Options{MaxWidth: 17}, error: "lzw: max code width 17 out of range [9, 16]"},
+		{opts: Options{MaxWidth: 16, Compatible: true}, error: "lzw: compatible stream requires max code width 12, not 16"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("creating codec with %+v", test.opts), func(t *testing.T) {
+			t.Parallel()
+			_, err := NewOptions(test.opts)
+			assert.EqualErrorf(t, err, test.error, "NewOptions(%v) unexpected error", test.opts)
+		})
+	}
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte("abababab"))
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.LZW), err)
+}
+
+func TestRegisteredFlags(t *testing.T) {
+	t.Parallel()
+
+	f, _ := compression.Lookup("lzw")
+
+	fs := flag.NewFlagSet("lzw", flag.ContinueOnError)
+	newWriter := f.Pack(fs)
+	assert.Nil(t, fs.Parse([]string{"-max-width", "12"}))
+
+	var buf bytes.Buffer
+	w, err := newWriter(&buf, compression.Header{})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("abcabcabc"))
+	assert.Nil(t, w.Close())
+	want := header(compression.Header{}, 12)
+	assert.Equal(t, want, buf.Bytes()[:len(want)], "the registered writer must pack with the options of the flags")
+
+	unpacked, err := io.ReadAll(f.Unpack(flag.NewFlagSet("lzw", flag.ContinueOnError))(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcabcabc"), unpacked)
+}
+
+func TestRegisteredCompatible(t *testing.T) {
+	t.Parallel()
+
+	f, _ := compression.Lookup("lzw")
+
+	packFlags := flag.NewFlagSet("lzw", flag.ContinueOnError)
+	newWriter := f.Pack(packFlags)
+	assert.Nil(t, packFlags.Parse([]string{"-compatible"}))
+
+	var buf bytes.Buffer
+	w, err := newWriter(&buf, compression.Header{})
+	assert.Nil(t, err, "the compatible flag must imply the compatible width")
+	_, _ = w.Write([]byte("abababab"))
+	assert.Nil(t, w.Close())
+
+	unpackFlags := flag.NewFlagSet("lzw", flag.ContinueOnError)
+	newReader := f.Unpack(unpackFlags)
+	assert.Nil(t, unpackFlags.Parse([]string{"-compatible"}))
+
+	unpacked, err := io.ReadAll(newReader(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abababab"), unpacked)
+}
+
+// header returns the header of data packed with lzw followed by the maximum code width.
+func header(h compression.Header, maxWidth uint) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.LZW
+	_ = compression.WriteHeader(&buf, h)
+
+	return append(buf.Bytes(), byte(maxWidth))
+}
+
+// codeBits returns the codes packed the way Writer does, the codes must not grow beyond 9 bits.
+func codeBits(codes ...uint32) []byte {
+	var bString strings.Builder
+	for _, c := range codes {
+		_, _ = fmt.Fprintf(&bString, "%09b", c)
+	}
+
+	s := bString.String()
+	data := make([]byte, (len(s)+7)/8)
+	for i, c := range s {
+		if c == '1' {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return data
+}
+
+// packed returns the data written by Codec.Pack for str: the header, the maximum code width, the codes
+// and the checksum.
+func packed(str string, maxWidth uint, codes ...uint32) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))}, maxWidth)
+	return append(append(data, codeBits(codes...)...), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
@@ -0,0 +1,44 @@
+package lzw
+
+import "fmt"
+
+const (
+	MinCodeWidth = 9
+	MaxCodeWidth = 16
+	// CompatibleWidth is the maximum code width of the streams of compress/lzw.
+	CompatibleWidth = 12
+)
+
+const (
+	// litWidth is the width of a literal, codes below clearCode are bytes.
+	litWidth  = 8
+	clearCode = 1 << litWidth
+	eofCode   = clearCode + 1
+)
+
+// Options configure the codes of the Writer. The Reader gets them from the packed data.
+type Options struct {
+	// MaxWidth is the maximum width of a code in bits, the dictionary is reset when all codes are used.
+	MaxWidth uint
+	// Compatible makes the Writer write the bare code stream readable by compress/lzw.NewReader(r, lzw.MSB, 8):
+	// no Header and MaxWidth of CompatibleWidth bits. Such a stream is not readable by the Reader.
+	Compatible bool
+}
+
+// DefaultOptions are the options of the Writer returned by NewWriter.
+var DefaultOptions = Options{MaxWidth: MaxCodeWidth}
+
+func (o Options) validate() error {
+	if o.MaxWidth < MinCodeWidth || o.MaxWidth > MaxCodeWidth {
+		return fmt.Errorf("max code width %d out of range [%d, %d]", o.MaxWidth, MinCodeWidth, MaxCodeWidth)
+	}
+	if o.Compatible && o.MaxWidth != CompatibleWidth {
+		return fmt.Errorf("compatible stream requires max code width %d, not %d", CompatibleWidth, o.MaxWidth)
+	}
+	return nil
+}
+
+// maxCode is the code the dictionary is reset at, it is never assigned.
+func (o Options) maxCode() uint32 {
+	return 1<<o.MaxWidth - 1
+}
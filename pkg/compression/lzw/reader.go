@@ -0,0 +1,207 @@
+package lzw
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// bufferSize is the number of bytes the Reader decodes at once, a single code may exceed it.
+const bufferSize = 4096
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header and the maximum code width are read and validated on the first call to Read.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	maxWidth   uint
+	br         *bitio.BitReader
+	// prefix and suffix hold the dictionary: the string of a code is the string of its prefix code
+	// followed by its suffix byte.
+	prefix   []uint32
+	suffix   []byte
+	last     uint32
+	hi       uint32
+	overflow uint32
+	width    uint
+	buf      []byte
+	decoded  []byte
+	read     uint64
+	crc      uint32
+	err      error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	br := bitio.NewByteReader(r)
+
+	return &Reader{
+		r:  br,
+		br: bitio.NewBitReader(br),
+	}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.err = z.readHeaderWidth()
+		z.readHeader = true
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.decode(z.buf[:0])
+		z.buf = z.decoded
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.br.Reset(z.r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Reader) readHeaderWidth() error {
+	var err error
+
+	z.Header, err = compression.ReadCodecHeader(z.r, compression.LZW)
+	if err != nil {
+		return err
+	}
+
+	width, err := z.r.ReadByte()
+	if err != nil {
+		return bitio.NoEOF(err)
+	}
+	z.maxWidth = uint(width)
+	if err = (Options{MaxWidth: z.maxWidth}).validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	if len(z.prefix) < 1<<z.maxWidth {
+		z.prefix = make([]uint32, 1<<z.maxWidth)
+		z.suffix = make([]byte, 1<<z.maxWidth)
+	}
+	z.clear()
+
+	return nil
+}
+
+// decode reads codes until at least bufferSize bytes are decoded, appends them to dst and returns
+// the extended slice. It returns io.EOF with the rest of the data at the end of the data code.
+func (z *Reader) decode(dst []byte) ([]byte, error) {
+	for len(dst) < bufferSize {
+		v, err := z.br.ReadBits(z.width)
+		if err != nil {
+			return dst, bitio.NoEOF(err)
+		}
+		code := uint32(v)
+
+		switch {
+		case code == clearCode:
+			z.clear()
+			continue
+		case code == eofCode:
+			z.read += uint64(len(dst))
+			z.crc = compression.UpdateChecksum(z.crc, dst)
+			if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+				return dst, fmt.Errorf("%w: unpacked %d bytes, but header size is %d", ErrCorrupted, z.read, z.Header.Size)
+			}
+			if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+				return dst, err
+			}
+			return dst, io.EOF
+		case code > z.hi:
+			return dst, fmt.Errorf("%w: invalid code %d", ErrCorrupted, code)
+		}
+
+		start := len(dst)
+		if code == z.hi && z.last != invalidCode {
+			// the code being assigned expands to the last string followed by its first byte
+			dst = z.expand(dst, z.last)
+			dst = append(dst, dst[start])
+		} else {
+			dst = z.expand(dst, code)
+		}
+
+		if z.last != invalidCode {
+			z.prefix[z.hi] = z.last
+			z.suffix[z.hi] = dst[start]
+		}
+
+		z.last, z.hi = code, z.hi+1
+		if z.hi >= z.overflow {
+			if z.width == z.maxWidth {
+				// the dictionary is full, it stays the same until the clear code
+				z.last = invalidCode
+				z.hi--
+			} else {
+				z.width++
+				z.overflow <<= 1
+			}
+		}
+
+		if z.Header.Flags&compression.FlagSize != 0 && z.read+uint64(len(dst)) > z.Header.Size {
+			return dst, fmt.Errorf("%w: unpacked data exceeds header size %d", ErrCorrupted, z.Header.Size)
+		}
+	}
+
+	z.read += uint64(len(dst))
+	z.crc = compression.UpdateChecksum(z.crc, dst)
+
+	return dst, nil
+}
+
+// expand appends the string of the code to dst.
+func (z *Reader) expand(dst []byte, code uint32) []byte {
+	n := 1
+	for c := code; c >= clearCode; c = z.prefix[c] {
+		n++
+	}
+
+	start := len(dst)
+	for i := 0; i < n; i++ {
+		dst = append(dst, 0)
+	}
+	for i := len(dst) - 1; i > start; i-- {
+		dst[i] = z.suffix[code]
+		code = z.prefix[code]
+	}
+	dst[start] = byte(code)
+
+	return dst
+}
+
+func (z *Reader) clear() {
+	z.last = invalidCode
+	z.hi = eofCode
+	z.width = litWidth + 1
+	z.overflow = 1 << z.width
+}
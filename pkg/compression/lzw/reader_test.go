@@ -0,0 +1,136 @@
+package lzw
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "text", data: []byte("Hello, World! Hello, World!\n")},
+		{name: "long text", data: []byte(strings.Repeat("Some pretty SUBsequence of words. ", 5000))},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s byte by byte", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, _ := New().Pack(test.data)
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(packed)))
+			data, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, test.data, data)
+			assert.Equal(t, uint64(len(test.data)), r.Header.Size)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "invalid code width",
+			data:  append(header(compression.Header{}, 20), codeBits(clearCode, eofCode)...),
+			err:   ErrCorrupted,
+			error: "lzw: packed data is corrupted: max code width 20 out of range [9, 16]",
+		},
+		{
+			name:  "missing code width",
+			data:  header(compression.Header{}, MaxCodeWidth)[:compression.HeaderSize],
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "code missing from dictionary",
+			data:  append(header(compression.Header{}, MaxCodeWidth), codeBits(clearCode, 'a', 300, eofCode)...),
+			err:   ErrCorrupted,
+			error: "lzw: packed data is corrupted: invalid code 300",
+		},
+		{
+			name:  "code being assigned after the clear code",
+			data:  append(header(compression.Header{}, MaxCodeWidth), codeBits(clearCode, 258, eofCode)...),
+			err:   ErrCorrupted,
+			error: "lzw: packed data is corrupted: invalid code 258",
+		},
+		{
+			name: "size is less than header size",
+			data: append(
+				header(compression.Header{Flags: compression.FlagSize, Size: 4}, MaxCodeWidth),
+				codeBits(clearCode, 'a', 'b', 'c', eofCode)...,
+			),
+			err:   ErrCorrupted,
+			error: "lzw: packed data is corrupted: unpacked 3 bytes, but header size is 4",
+		},
+		{
+			name: "size exceeds header size",
+			data: append(
+				header(compression.Header{Flags: compression.FlagSize, Size: 2}, MaxCodeWidth),
+				codeBits(clearCode, 'a', 'b', 'c', eofCode)...,
+			),
+			err:   ErrCorrupted,
+			error: "lzw: packed data is corrupted: unpacked data exceeds header size 2",
+		},
+		{
+			name:  "missing end of data code",
+			data:  append(header(compression.Header{}, MaxCodeWidth), codeBits(clearCode, 'a', 'b')...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	packed, _ := New().Pack([]byte("abababab"))
+	r := NewReader(bytes.NewReader(packed))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	first, _ := New().Pack([]byte("abababab"))
+	second, _ := New().Pack([]byte("Ted"))
+
+	r := NewReader(bytes.NewReader(first))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(second))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
@@ -0,0 +1,266 @@
+package lzw
+
+import (
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+const (
+	// tableBits is the binary logarithm of the size of the encoder dictionary hash table,
+	// it holds twice as many entries as 16-bit codes.
+	tableBits = 17
+	tableMask = 1<<tableBits - 1
+	// invalidCode marks the absence of the code of the current string.
+	invalidCode = 1<<32 - 1
+)
+
+var (
+	ErrClosed    = errors.New("lzw: stream is closed")
+	ErrCorrupted = errors.New("lzw: packed data is corrupted")
+)
+
+// errOutOfCodes reports the dictionary was reset as all its codes were used.
+var errOutOfCodes = errors.New("lzw: out of codes")
+
+// Writer is an io.WriteCloser that packs the data written to it with Lempel-Ziv-Welch codes.
+//
+// The Header is followed by the maximum code width, a byte. Then the codes of the whole data follow
+// the same way compress/lzw writes them with MSB order: the clear code, the codes growing from 9 bits
+// to the maximum width, the clear code whenever the dictionary is full, the end of the data code
+// and zero padding to a whole byte.
+//
+// Codes span the whole data, so the Writer has no Flush: the data is readable only after Close.
+//
+// With FlagChecksum set in the Header, the packed data is followed by the checksum of the original data,
+// unless the stream is compatible.
+//
+// The Header is written before the first code, so its Size and Flags may be set until the first call
+// to Write or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           io.Writer
+	wroteHeader bool
+	opts        Options
+	bw          *bitio.BitWriter
+	// table maps the code of a string followed by a byte to the code of the longer string:
+	// entries are key<<16 | code, zero for no entry.
+	table    []uint64
+	code     uint32
+	hi       uint32
+	overflow uint32
+	width    uint
+	written  uint64
+	crc      uint32
+	err      error
+}
+
+// NewWriter returns a new Writer packing the data to w with DefaultOptions.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	z, _ := NewWriterOptions(w, DefaultOptions)
+	return z
+}
+
+// NewWriterOptions is like NewWriter but packs with the given options.
+//
+// It returns an error if the options are invalid.
+func NewWriterOptions(w io.Writer, opts Options) (*Writer, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("lzw: %w", err)
+	}
+
+	z := &Writer{
+		w:     w,
+		opts:  opts,
+		bw:    bitio.NewBitWriter(w),
+		table: make([]uint64, 1<<tableBits),
+		code:  invalidCode,
+	}
+	z.resetTable()
+
+	return z, nil
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	z.crc = compression.UpdateChecksum(z.crc, p)
+	n, code := len(p), z.code
+	if code == invalidCode {
+		// the first byte starts the first string after the clear code
+		if z.err = z.writeCode(clearCode); z.err != nil {
+			return 0, z.err
+		}
+		code, p = uint32(p[0]), p[1:]
+	}
+
+	for i, b := range p {
+		key := code<<8 | uint32(b)
+		if next, ok := z.lookup(key); ok {
+			code = next
+			continue
+		}
+
+		if z.err = z.writeCode(code); z.err != nil {
+			return n - len(p) + i, z.err
+		}
+		code = uint32(b)
+
+		if err := z.incHi(); err != nil {
+			if err == errOutOfCodes {
+				continue
+			}
+			z.err = err
+			return n - len(p) + i, z.err
+		}
+		z.insert(key, z.hi)
+	}
+	z.code = code
+	z.written += uint64(n)
+
+	return n, nil
+}
+
+// Close writes the rest of the codes and the end of the data code to the underlying writer.
+// It does not close the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	if z.code == invalidCode {
+		z.err = z.writeCode(clearCode)
+	} else if z.err = z.writeCode(z.code); z.err == nil {
+		if err := z.incHi(); err != nil && err != errOutOfCodes {
+			z.err = err
+		}
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeCode(eofCode); z.err != nil {
+		return z.err
+	}
+	if z.err = z.bw.Flush(); z.err != nil {
+		return z.err
+	}
+	if !z.opts.Compatible {
+		// the compatible stream has no Header, so it has no checksum either
+		if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+			return z.err
+		}
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("lzw: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriterOptions(w, opts)
+// with the options of the Writer.
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.bw.Reset(w)
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.code = invalidCode
+	z.resetTable()
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader || z.opts.Compatible {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.LZW
+	z.wroteHeader = true
+
+	if err := compression.WriteHeader(z.w, z.Header); err != nil {
+		return err
+	}
+	_, err := z.w.Write([]byte{byte(z.opts.MaxWidth)})
+	return err
+}
+
+func (z *Writer) writeCode(code uint32) error {
+	return z.bw.WriteBits(uint64(code), z.width)
+}
+
+// incHi moves to the next code to assign, growing the code width when needed. When all codes are used,
+// it writes the clear code, resets the dictionary and returns errOutOfCodes.
+func (z *Writer) incHi() error {
+	z.hi++
+	if z.hi == z.overflow {
+		z.width++
+		z.overflow <<= 1
+	}
+
+	if z.hi == z.opts.maxCode() {
+		if err := z.writeCode(clearCode); err != nil {
+			return err
+		}
+		z.resetTable()
+		return errOutOfCodes
+	}
+
+	return nil
+}
+
+func (z *Writer) resetTable() {
+	for i := range z.table {
+		z.table[i] = 0
+	}
+	z.hi = eofCode
+	z.width = litWidth + 1
+	z.overflow = 1 << z.width
+}
+
+func (z *Writer) lookup(key uint32) (uint32, bool) {
+	for h := hash(key); z.table[h] != 0; h = (h + 1) & tableMask {
+		if uint32(z.table[h]>>16) == key {
+			return uint32(z.table[h] & 0xffff), true
+		}
+	}
+	return 0, false
+}
+
+func (z *Writer) insert(key, code uint32) {
+	h := hash(key)
+	for z.table[h] != 0 {
+		h = (h + 1) & tableMask
+	}
+	z.table[h] = uint64(key)<<16 | uint64(code)
+}
+
+func hash(key uint32) uint32 {
+	return (key ^ key>>tableBits) * 2654435761 >> (32 - tableBits) & tableMask
+}
@@ -0,0 +1,142 @@
+package lzw
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+		codes     []uint32
+	}{
+		{str: "", partSize: 1, codes: []uint32{clearCode, eofCode}},
+		{str: "abababab", partSize: 1, codes: []uint32{clearCode, 'a', 'b', 258, 260, 'b', eofCode}},
+		{str: "abababab", partSize: 3, codes: []uint32{clearCode, 'a', 'b', 258, 260, 'b', eofCode}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewWriter(buf)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				written, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				assert.Equalf(t, n, written, "Writer.Write(%v) written bytes", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := append(header(compression.Header{}, MaxCodeWidth), codeBits(test.codes...)...)
+			assert.Equalf(t, want, buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterResetsDictionary(t *testing.T) {
+	t.Parallel()
+
+	// almost every pair of bytes is a new string, so the dictionary of 9-bit codes gets full
+	data := make([]byte, 0, 1000)
+	for i := 0; len(data) < cap(data); i++ {
+		data = append(data, byte(i), byte(i*7+1))
+	}
+
+	var buf bytes.Buffer
+	w, _ := NewWriterOptions(&buf, Options{MaxWidth: MinCodeWidth})
+	_, _ = w.Write(data)
+	assert.Nil(t, w.Close())
+
+	// with the maximum width of 9 bits every code is 9 bits long
+	var codes []uint32
+	br := bitio.NewBitReader(bytes.NewReader(buf.Bytes()[compression.HeaderSize+1:]))
+	for {
+		code, err := br.ReadBits(MinCodeWidth)
+		if err != nil {
+			break
+		}
+		codes = append(codes, uint32(code))
+	}
+
+	clears := 0
+	for _, code := range codes {
+		if code == clearCode {
+			clears++
+		}
+	}
+	assert.Greater(t, clears, 1, "Writer must reset the full dictionary with the clear code")
+	assert.Equal(t, uint32(eofCode), codes[len(codes)-1])
+
+	unpacked, err := New().Unpack(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, data, unpacked)
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("abc"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "lzw: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("abc"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("abababab"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("abababab"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}, MaxCodeWidth), first.Bytes())
+	assert.Equal(
+		t,
+		append(header(compression.Header{}, MaxCodeWidth), codeBits(clearCode, 'a', 'b', 258, 260, 'b', eofCode)...),
+		second.Bytes(),
+		"Writer.Reset() must discard the dictionary",
+	)
+}
+
+func TestNewWriterOptionsError(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewWriterOptions(&bytes.Buffer{}, Options{MaxWidth: 20})
+	assert.Nil(t, w)
+	assert.EqualError(t, err, "lzw: max code width 20 out of range [9, 16]")
+}
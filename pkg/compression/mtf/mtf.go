@@ -0,0 +1,52 @@
+// Package mtf implements the move-to-front transform.
+//
+// The transform replaces every byte with its position in a list of all byte values and moves the byte to the
+// front of the list, so recently seen bytes become small numbers. It doesn't change the length of the data and
+// is usually applied after the Burrows–Wheeler transform, turning its runs of equal bytes into runs of zeros.
+package mtf
+
+// list is the list of byte values being reordered, initially in ascending order.
+type list [256]byte
+
+func newList() *list {
+	var l list
+	for i := range l {
+		l[i] = byte(i)
+	}
+	return &l
+}
+
+// moveToFront moves the byte at position i to the front of the list and returns the byte.
+func (l *list) moveToFront(i byte) byte {
+	b := l[i]
+	copy(l[1:int(i)+1], l[:i])
+	l[0] = b
+	return b
+}
+
+// Encode appends the positions of the bytes of src to dst and returns the extended slice.
+func Encode(dst, src []byte) []byte {
+	l := newList()
+
+	for _, b := range src {
+		var i byte
+		for l[i] != b {
+			i++
+		}
+		dst = append(dst, i)
+		l.moveToFront(i)
+	}
+
+	return dst
+}
+
+// Decode appends the bytes at positions given by src to dst and returns the extended slice, it reverses Encode.
+func Decode(dst, src []byte) []byte {
+	l := newList()
+
+	for _, i := range src {
+		dst = append(dst, l.moveToFront(i))
+	}
+
+	return dst
+}
@@ -0,0 +1,45 @@
+package mtf
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      []byte
+	}{
+		{str: "", want: []byte{}},
+		{str: "\x00\x00\x00", want: []byte{0, 0, 0}},
+		{str: "aaa", want: []byte{'a', 0, 0}},
+		{str: "abab", want: []byte{'a', 'b', 1, 1}},
+		{str: "bananaaa", want: []byte{'b', 'b', 'n', 1, 1, 1, 0, 0}},
+		{str: "\xff\x00\xff", want: []byte{255, 1, 1}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encoding %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, test.want, Encode([]byte{}, []byte(test.str)), "Encode(%v)", test.str)
+			assert.Equalf(t, test.str, string(Decode(nil, test.want)), "Decode(%v)", test.want)
+		})
+	}
+}
+
+func TestDecodeReversesEncode(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i * i % 251)
+	}
+
+	assert.Equal(t, append([]byte("x"), data...), Decode([]byte("x"), Encode(nil, data)),
+		"Decode() must reverse Encode() and append to dst")
+}
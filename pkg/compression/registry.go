@@ -0,0 +1,86 @@
+package compression
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// WriterFunc returns a writer packing the data to w with the Header, it fails when the options are invalid.
+type WriterFunc func(w io.Writer, h Header) (io.WriteCloser, error)
+
+// ReaderFunc returns a reader unpacking the data read from r.
+type ReaderFunc func(r io.Reader) io.ReadCloser
+
+// Factory describes a codec and creates its streams.
+//
+// The options of the codec are defined as flags: Pack and Unpack define them in the given flag set and
+// return the functions creating the streams with the values the flags have when the functions are called.
+// A flag set without the parsed flags gives the default options.
+type Factory struct {
+	// Description is a short description of the codec.
+	Description string
+	// Extension is the extension of the packed files, without the dot.
+	Extension string
+	// Magic is the signature the packed data starts with, it is empty when the data has none.
+	Magic []byte
+	// Match reports whether the data starting with header is packed with the codec, it recognizes the data
+	// of the codecs without Magic. It may be nil.
+	Match func(header []byte) bool
+	// Pack defines the packing options in fs and returns the function creating the writers.
+	// The values of the options are validated when the flags are parsed, see RangeVar.
+	// It is nil for the formats that are only unpacked.
+	Pack func(fs *flag.FlagSet) WriterFunc
+	// Unpack defines the unpacking options in fs and returns the function creating the readers.
+	Unpack func(fs *flag.FlagSet) ReaderFunc
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes the codec available by the name. Codec packages register themselves in their init functions.
+//
+// It panics if the name is empty or already registered, or the factory can't unpack.
+func Register(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" {
+		panic("compression: Register codec with empty name")
+	}
+	if f.Unpack == nil {
+		panic(fmt.Sprintf("compression: Register codec %q without Unpack", name))
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("compression: Register called twice for codec %q", name))
+	}
+
+	registry[name] = f
+}
+
+// Lookup returns the factory of the codec registered by the name.
+func Lookup(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	f, ok := registry[name]
+	return f, ok
+}
+
+// List returns the sorted names of the registered codecs.
+func List() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
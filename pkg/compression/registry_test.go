@@ -0,0 +1,92 @@
+package compression
+
+import (
+	"bytes"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sort"
+	"testing"
+)
+
+// nopFactory is a factory of the streams passing the data as it is, its writer ignores the Header.
+var nopFactory = Factory{
+	Description: "no packing",
+	Extension:   "nop",
+	Pack: func(fs *flag.FlagSet) WriterFunc {
+		return func(w io.Writer, _ Header) (io.WriteCloser, error) {
+			return nopWriteCloser{w}, nil
+		}
+	},
+	Unpack: func(fs *flag.FlagSet) ReaderFunc {
+		return io.NopCloser
+	},
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	Register("test-register", nopFactory)
+
+	f, ok := Lookup("test-register")
+	assert.True(t, ok)
+	assert.Equal(t, "no packing", f.Description)
+	assert.Equal(t, "nop", f.Extension)
+
+	var buf bytes.Buffer
+	w, err := f.Pack(flag.NewFlagSet("test-register", flag.ContinueOnError))(&buf, Header{})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("abc"))
+	assert.Nil(t, w.Close())
+
+	unpacked, err := io.ReadAll(f.Unpack(flag.NewFlagSet("test-register", flag.ContinueOnError))(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abc"), unpacked)
+}
+
+func TestRegisterPanics(t *testing.T) {
+	t.Parallel()
+
+	Register("test-twice", nopFactory)
+
+	assert.PanicsWithValue(t, `compression: Register called twice for codec "test-twice"`, func() {
+		Register("test-twice", nopFactory)
+	})
+	assert.PanicsWithValue(t, "compression: Register codec with empty name", func() {
+		Register("", nopFactory)
+	})
+	assert.PanicsWithValue(t, `compression: Register codec "test-no-unpack" without Unpack`, func() {
+		Register("test-no-unpack", Factory{Pack: nopFactory.Pack})
+	})
+
+	_, ok := Lookup("test-no-unpack")
+	assert.False(t, ok)
+}
+
+func TestLookupUnknown(t *testing.T) {
+	t.Parallel()
+
+	_, ok := Lookup("test-unknown")
+	assert.False(t, ok)
+}
+
+func TestList(t *testing.T) {
+	Register("test-list-b", nopFactory)
+	Register("test-list-a", nopFactory)
+
+	names := List()
+	assert.Subset(t, names, []string{"test-list-a", "test-list-b"})
+	assert.True(t, sort.StringsAreSorted(names), "List() must return sorted names")
+}
+
+func TestHeaderMagic(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []byte{'A', 'R', 'C', 'V', Version, byte(LZSS)}, HeaderMagic(LZSS))
+}
@@ -0,0 +1,74 @@
+package rle
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// maxRun is the maximum number of bytes of a literal or a run.
+	maxRun = 128
+	// endMark is the header byte marking the end of the data, PackBits treats it as no operation.
+	endMark = 0x80
+)
+
+// Encode appends data framed PackBits-style to dst and returns the extended slice.
+//
+// Every literal or run starts with a header byte n: n in [0, 127] is followed by n+1 literal bytes,
+// n in [129, 255] is followed by a single byte repeated 257-n times. Runs of 2 bytes start only
+// outside of literals, as they don't save anything inside.
+func Encode(dst, data []byte) []byte {
+	for i := 0; i < len(data); {
+		run := 1
+		for i+run < len(data) && data[i+run] == data[i] && run < maxRun {
+			run++
+		}
+		if run > 1 {
+			dst = append(dst, byte(257-run), data[i])
+			i += run
+			continue
+		}
+
+		start := i
+		for i < len(data) && i-start < maxRun {
+			if i+2 < len(data) && data[i] == data[i+1] && data[i] == data[i+2] {
+				break
+			}
+			i++
+		}
+		dst = append(dst, byte(i-start-1))
+		dst = append(dst, data[start:i]...)
+	}
+
+	return dst
+}
+
+// Decode appends data framed by Encode to dst and returns the extended slice.
+//
+// Unlike the Reader it does not expect the end of the data mark, a header byte 128 is skipped as PackBits does.
+func Decode(dst, data []byte) ([]byte, error) {
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+
+		switch {
+		case n == endMark:
+		case n < endMark:
+			if i+n+1 > len(data) {
+				return dst, fmt.Errorf("%w: %v", ErrCorrupted, io.ErrUnexpectedEOF)
+			}
+			dst = append(dst, data[i:i+n+1]...)
+			i += n + 1
+		default:
+			if i == len(data) {
+				return dst, fmt.Errorf("%w: %v", ErrCorrupted, io.ErrUnexpectedEOF)
+			}
+			for run := 257 - n; run > 0; run-- {
+				dst = append(dst, data[i])
+			}
+			i++
+		}
+	}
+
+	return dst, nil
+}
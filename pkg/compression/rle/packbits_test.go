@@ -0,0 +1,111 @@
+package rle
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      []byte
+	}{
+		{str: "", want: []byte{}},
+		{str: "a", want: []byte{0, 'a'}},
+		{str: "abc", want: []byte{2, 'a', 'b', 'c'}},
+		{str: "aa", want: []byte{255, 'a'}},
+		{str: "aaab", want: []byte{254, 'a', 0, 'b'}},
+		{str: "abbbc", want: []byte{0, 'a', 254, 'b', 0, 'c'}},
+		{str: "abbc", want: []byte{3, 'a', 'b', 'b', 'c'}},
+		{str: strings.Repeat("a", 128), want: []byte{129, 'a'}},
+		{str: strings.Repeat("a", 130), want: []byte{129, 'a', 255, 'a'}},
+		{str: strings.Repeat("a", 129), want: []byte{129, 'a', 0, 'a'}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encoding %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, test.want, Encode([]byte{}, []byte(test.str)), "Encode(%v)", test.str)
+		})
+	}
+}
+
+func TestEncodeLongLiteral(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	want := append(append([]byte{127}, data[:128]...), 71)
+	want = append(want, data[128:]...)
+	assert.Equal(t, want, Encode(nil, data), "Encode() must split literals longer than 128 bytes")
+	assert.True(t, bytes.HasPrefix(Encode([]byte("x"), data), []byte("x")), "Encode() must append to dst")
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{data: []byte{}, want: ""},
+		{data: []byte{0, 'a'}, want: "a"},
+		{data: []byte{2, 'a', 'b', 'c'}, want: "abc"},
+		{data: []byte{254, 'a', 0, 'b'}, want: "aaab"},
+		{data: []byte{0, 'a', endMark, 254, 'b'}, want: "abbb"},
+		{data: []byte{129, 'a', 0, 'a'}, want: strings.Repeat("a", 129)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding %v", test.data)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Decode(nil, test.data)
+			assert.Nilf(t, err, "Decode(%v)", test.data)
+			assert.Equalf(t, test.want, string(got), "Decode(%v)", test.data)
+		})
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{data: []byte{2, 'a', 'b'}},
+		{data: []byte{254}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding truncated %v", test.data)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Decode(nil, test.data)
+			assert.ErrorIsf(t, err, ErrCorrupted, "Decode(%v)", test.data)
+		})
+	}
+}
+
+func TestDecodeReversesEncode(t *testing.T) {
+	t.Parallel()
+
+	data := sensorDump(10000)
+	got, err := Decode([]byte("x"), Encode(nil, data))
+	assert.Nil(t, err)
+	assert.Equal(t, append([]byte("x"), data...), got, "Decode() must reverse Encode() and append to dst")
+}
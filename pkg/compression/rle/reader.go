@@ -0,0 +1,175 @@
+package rle
+
+import (
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header is read and validated on the first call to Read. With FlagPacked set in the Header, the data
+// following it is unpacked first, with the codec detected by its header unless the Reader is returned
+// by NewUnpackingReader.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	// buf holds bufferSize decoded bytes and a literal or a run beyond them.
+	buf     []byte
+	decoded []byte
+	read    uint64
+	crc     uint32
+	err     error
+	// unpack returns the reader unpacking the data following the Header with FlagPacked, packed is the returned one.
+	unpack compression.ReaderFunc
+	packed io.ReadCloser
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:   bitio.NewByteReader(r),
+		buf: make([]byte, 0, bufferSize+maxRun),
+	}
+}
+
+// NewUnpackingReader returns a new Reader unpacking the data read from r, the data following the Header
+// with FlagPacked is unpacked with the reader returned by newReader.
+//
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewUnpackingReader(r io.Reader, newReader compression.ReaderFunc) *Reader {
+	z := NewReader(r)
+	z.unpack = newReader
+	return z
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.Header, z.err = compression.ReadCodecHeader(z.r, compression.RLE)
+		z.readHeader = true
+		if z.err == nil && z.Header.Flags&compression.FlagPacked != 0 {
+			z.unpackPacked()
+		}
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.decode(z.buf[:0])
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader and the reader unpacking the data once more, if any. It does not close
+// the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	if z.packed != nil {
+		return z.packed.Close()
+	}
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.unpack, z.packed = nil, nil
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+// decode reads literals and runs until at least bufferSize bytes are decoded, appends them to dst and returns
+// the extended slice. It returns io.EOF with the rest of the data at the end of the data mark.
+func (z *Reader) decode(dst []byte) ([]byte, error) {
+	for len(dst) < bufferSize {
+		n, err := z.r.ReadByte()
+		if err != nil {
+			return dst, bitio.NoEOF(err)
+		}
+
+		switch {
+		case n == endMark:
+			z.read += uint64(len(dst))
+			z.crc = compression.UpdateChecksum(z.crc, dst)
+			if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+				return dst, fmt.Errorf("%w: unpacked %d bytes, but header size is %d", ErrCorrupted, z.read, z.Header.Size)
+			}
+			if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+				return dst, err
+			}
+			if err := z.readPackedEnd(); err != nil {
+				return dst, err
+			}
+			return dst, io.EOF
+		case n < endMark:
+			start := len(dst)
+			dst = dst[:start+int(n)+1]
+			if _, err = io.ReadFull(z.r, dst[start:]); err != nil {
+				return dst[:start], bitio.NoEOF(err)
+			}
+		default:
+			b, err := z.r.ReadByte()
+			if err != nil {
+				return dst, bitio.NoEOF(err)
+			}
+			for i := 257 - int(n); i > 0; i-- {
+				dst = append(dst, b)
+			}
+		}
+
+		if z.Header.Flags&compression.FlagSize != 0 && z.read+uint64(len(dst)) > z.Header.Size {
+			return dst, fmt.Errorf("%w: unpacked data exceeds header size %d", ErrCorrupted, z.Header.Size)
+		}
+	}
+
+	z.read += uint64(len(dst))
+	z.crc = compression.UpdateChecksum(z.crc, dst)
+
+	return dst, nil
+}
+
+// unpackPacked makes the Reader read the data following the Header from the reader unpacking it.
+func (z *Reader) unpackPacked() {
+	unpack := z.unpack
+	if unpack == nil {
+		unpack = func(r io.Reader) io.ReadCloser {
+			return compression.NewDetectingReader(r)
+		}
+	}
+
+	z.packed = unpack(z.r)
+	z.r = bitio.NewByteReader(z.packed)
+}
+
+// readPackedEnd reads the end of the data unpacked once more, if any, so its checksum is verified.
+// It fails if the data continues after the end of the data mark.
+func (z *Reader) readPackedEnd() error {
+	if z.packed == nil {
+		return nil
+	}
+
+	if _, err := z.r.ReadByte(); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("%w: data after the end of the data mark", ErrCorrupted)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package rle
+
+import (
+	"flag"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+func init() {
+	compression.Register("rle", compression.Factory{
+		Description: "run-length encoding of repeated bytes",
+		Extension:   "rle",
+		Magic:       compression.HeaderMagic(compression.RLE),
+		Pack: func(_ *flag.FlagSet) compression.WriterFunc {
+			return newWriter
+		},
+		Unpack: func(_ *flag.FlagSet) compression.ReaderFunc {
+			return newReader
+		},
+	})
+}
+
+type Codec struct{}
+
+func New() Codec {
+	return Codec{}
+}
+
+func (_ Codec) Pack(data []byte) ([]byte, error) {
+	return compression.PackWith(newWriter, data)
+}
+
+func (_ Codec) Unpack(data []byte) ([]byte, error) {
+	return compression.UnpackWith(newReader, data)
+}
+
+// newWriter returns the Writer packing the data to w with the Header.
+func newWriter(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+	z := NewWriter(w)
+	z.Header = h
+	return z, nil
+}
+
+// newReader returns the Reader unpacking the data read from r.
+func newReader(r io.Reader) io.ReadCloser {
+	return NewReader(r)
+}
@@ -0,0 +1,229 @@
+package rle
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/psssix/archiver/pkg/compression/huffman"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCodecPack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      []byte
+	}{
+		{str: "", want: []byte{}},
+		{str: "abbbc", want: []byte{0, 'a', 254, 'b', 0, 'c'}},
+		{str: strings.Repeat("a", 300), want: []byte{129, 'a', 129, 'a', 213, 'a'}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			bytes, err := New().Pack([]byte(test.str))
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.str)
+			assert.Equalf(t, packed(test.str, test.want...), bytes, "Codec.Pack(%v)", test.str)
+		})
+	}
+}
+
+func TestCodecUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		bytes      []byte
+	}{
+		{want: "", bytes: []byte{}},
+		{want: "abbbc", bytes: []byte{0, 'a', 254, 'b', 0, 'c'}},
+		{want: "aaaa", bytes: []byte{255, 'a', 255, 'a'}},
+		{want: strings.Repeat("a", 300), bytes: []byte{129, 'a', 129, 'a', 213, 'a'}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q", test.want)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data := packed(test.want, test.bytes...)
+			unpacked, err := New().Unpack(data)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", data)
+			assert.Equalf(t, []byte(test.want), unpacked, "Codec.Unpack(%v)", data)
+		})
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	random := make([]byte, 3*bufferSize+5)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "text", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "random data", data: random},
+		{name: "sensor dump", data: sensorDump(10000)},
+		{name: "long run", data: bytes.Repeat([]byte{0}, 10*bufferSize+1)},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing and unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			packed, err := New().Pack(test.data)
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.name)
+			unpacked, err := New().Unpack(packed)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", test.name)
+			assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.name)
+		})
+	}
+}
+
+func TestCodecCompressesRuns(t *testing.T) {
+	t.Parallel()
+
+	data := sensorDump(10000)
+
+	packed, err := New().Pack(data)
+	assert.Nil(t, err)
+	assert.Less(t, len(packed), len(data)/10, "Codec.Pack() must replace runs of the same byte")
+}
+
+func TestCodecAsPreTransform(t *testing.T) {
+	t.Parallel()
+
+	data := sensorDump(10000)
+
+	t.Run("chaining codecs", func(t *testing.T) {
+		t.Parallel()
+		c := compression.Chain(New(), huffman.New())
+
+		packed, err := c.Pack(data)
+		assert.Nil(t, err)
+		huffmanOnly, _ := huffman.New().Pack(data)
+		assert.Less(t, len(packed), len(huffmanOnly), "runs must be packed before huffman")
+
+		unpacked, err := c.Unpack(packed)
+		assert.Nil(t, err)
+		assert.Equal(t, data, unpacked)
+	})
+
+	t.Run("stacking streams", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+
+		hw := huffman.NewWriter(&buf)
+		w := NewWriter(hw)
+		_, _ = w.Write(data)
+		assert.Nil(t, w.Close())
+		assert.Nil(t, hw.Close())
+
+		hr := huffman.NewReader(&buf)
+		unpacked, err := io.ReadAll(NewReader(hr))
+		assert.Nil(t, err)
+		assert.Equal(t, data, unpacked)
+	})
+
+	t.Run("packing once more", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+
+		w := NewPackingWriter(&buf, func(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+			z := huffman.NewWriter(w)
+			z.Header = h
+			return z, nil
+		})
+		w.Header = compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(data))}
+		_, _ = w.Write(data)
+		assert.Nil(t, w.Close())
+		packed := buf.Bytes()
+
+		h, err := compression.ReadHeader(bytes.NewReader(packed))
+		assert.Nil(t, err)
+		assert.Equal(t, compression.Header{
+			Version: compression.Version,
+			Codec:   compression.RLE,
+			Flags:   compression.FlagSize | compression.FlagChecksum | compression.FlagPacked,
+			Size:    uint64(len(data)),
+		}, h)
+		huffmanHeader, err := compression.ReadHeader(bytes.NewReader(packed[compression.HeaderSize:]))
+		assert.Nil(t, err)
+		assert.Equal(t, compression.Huffman, huffmanHeader.Codec)
+
+		unpacked, err := compression.Auto.Unpack(packed)
+		assert.Nil(t, err)
+		assert.Equal(t, data, unpacked)
+
+		r := NewUnpackingReader(bytes.NewReader(packed), func(r io.Reader) io.ReadCloser {
+			return huffman.NewReader(r)
+		})
+		unpacked, err = io.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, data, unpacked)
+		assert.Nil(t, r.Close())
+
+		_, err = New().Unpack(packed[:len(packed)-1])
+		assert.NotNil(t, err, "Codec.Unpack() of truncated data")
+	})
+}
+
+func TestCodecUnpackHeaderError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New().Unpack([]byte{0, 'a', 254, 'b'})
+	assert.ErrorIs(t, err, compression.ErrHeader)
+
+	vlcHeader := append([]byte(compression.Magic), compression.Version, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	_, err = New().Unpack(vlcHeader)
+	assert.Equal(t, compression.NewCodecMismatchError(compression.VLC, compression.RLE), err)
+}
+
+// sensorDump returns n readings of a sensor: long runs of the same values with rare changes.
+func sensorDump(n int) []byte {
+	rnd := rand.New(rand.NewSource(1))
+
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		value := byte(rnd.Intn(4))
+		buf.Write(bytes.Repeat([]byte{value}, 50+rnd.Intn(200)))
+	}
+
+	return buf.Bytes()
+}
+
+// header returns the header of data packed with rle.
+func header(h compression.Header) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.RLE
+	_ = compression.WriteHeader(&buf, h)
+
+	return buf.Bytes()
+}
+
+// packed returns the data written by Codec.Pack for str: the header, the runs, the end mark and the checksum.
+func packed(str string, runs ...byte) []byte {
+	data := header(compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))})
+	return append(append(append(data, runs...), endMark), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the packed data.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
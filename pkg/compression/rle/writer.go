@@ -0,0 +1,196 @@
+package rle
+
+import (
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// bufferSize is the number of bytes the Writer collects before encoding them.
+const bufferSize = 4096
+
+var (
+	ErrClosed    = errors.New("rle: stream is closed")
+	ErrCorrupted = errors.New("rle: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it replacing runs of the same byte
+// with the byte and the run length.
+//
+// The Header is followed by literals and runs framed PackBits-style and the end of the data mark,
+// header byte 128. The underlying writer may be the Writer of another codec, so the runs are packed
+// before that codec packs the data.
+//
+// With FlagChecksum set in the Header, the packed data is followed by the checksum of the original data.
+//
+// The Writer returned by NewPackingWriter packs all the data following the Header once more with another codec
+// and sets FlagPacked in the Header, so the run-length encoding is recorded in the outermost header
+// along with the Size and the checksum of the original data.
+//
+// The Header is written before the first run, so its Size and Flags may be set until the first call
+// to Write, Flush or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           io.Writer
+	wroteHeader bool
+	buf         []byte
+	encoded     []byte
+	written     uint64
+	crc         uint32
+	err         error
+	// pack returns the writer packing the data following the Header, packed is the returned one.
+	pack   compression.WriterFunc
+	packed io.WriteCloser
+}
+
+// NewWriter returns a new Writer packing the data to w.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:   w,
+		buf: make([]byte, 0, bufferSize),
+	}
+}
+
+// NewPackingWriter returns a new Writer packing the data to w, the data following the Header is packed once more
+// with the writer returned by newWriter for the header with FlagChecksum set.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewPackingWriter(w io.Writer, newWriter compression.WriterFunc) *Writer {
+	z := NewWriter(w)
+	z.pack = newWriter
+	return z
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	for written := 0; written < len(p); {
+		n := copy(z.buf[len(z.buf):cap(z.buf)], p[written:])
+		z.buf = z.buf[:len(z.buf)+n]
+
+		if len(z.buf) == cap(z.buf) {
+			if z.err = z.writeBuffer(); z.err != nil {
+				return written, z.err
+			}
+		}
+
+		z.crc = compression.UpdateChecksum(z.crc, p[written:written+n])
+		written += n
+		z.written += uint64(n)
+	}
+
+	return len(p), nil
+}
+
+// Flush packs all the data written so far and writes it to the underlying writer.
+// It does not flush the underlying writer.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.err = z.writeBuffer()
+	return z.err
+}
+
+// Close writes the rest of the packed data and the end of the data mark to the underlying writer.
+// It closes the writer packing the data once more, if any, but not the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBuffer(); z.err != nil {
+		return z.err
+	}
+	if _, z.err = z.w.Write([]byte{endMark}); z.err != nil {
+		return z.err
+	}
+	if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+		return z.err
+	}
+	if z.packed != nil {
+		if z.err = z.packed.Close(); z.err != nil {
+			return z.err
+		}
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("rle: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriter(w).
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.pack, z.packed = nil, nil
+	z.buf = z.buf[:0]
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.RLE
+	if z.pack != nil {
+		z.Header.Flags |= compression.FlagPacked
+	}
+	z.wroteHeader = true
+
+	if err := compression.WriteHeader(z.w, z.Header); err != nil || z.pack == nil {
+		return err
+	}
+
+	packed, err := z.pack(z.w, compression.Header{Flags: compression.FlagChecksum})
+	if err != nil {
+		return err
+	}
+	z.w, z.packed = packed, packed
+
+	return nil
+}
+
+// writeBuffer packs the data collected since the previous call, if any.
+func (z *Writer) writeBuffer() error {
+	if len(z.buf) == 0 {
+		return nil
+	}
+
+	z.encoded = Encode(z.encoded[:0], z.buf)
+	if _, err := z.w.Write(z.encoded); err != nil {
+		return err
+	}
+	z.buf = z.buf[:0]
+
+	return nil
+}
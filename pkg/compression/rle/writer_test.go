@@ -0,0 +1,105 @@
+package rle
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		partSize  int
+		want      []byte
+	}{
+		{str: "", partSize: 1, want: []byte{}},
+		{str: "abbbc", partSize: 1, want: []byte{0, 'a', 254, 'b', 0, 'c'}},
+		{str: "abbbc", partSize: 2, want: []byte{0, 'a', 254, 'b', 0, 'c'}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewWriter(buf)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+
+			want := append(header(compression.Header{}), test.want...)
+			assert.Equalf(t, append(want, endMark), buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	_, _ = w.Write([]byte("abb"))
+	assert.Nil(t, w.Flush())
+	want := append(header(compression.Header{}), 2, 'a', 'b', 'b')
+	assert.Equal(t, want, buf.Bytes(), "Writer.Flush() must write all packed data")
+
+	_, _ = w.Write([]byte("bb"))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, append(want, 255, 'b', endMark), buf.Bytes())
+}
+
+func TestWriterSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	w.Header.Flags = compression.FlagSize
+	w.Header.Size = 4
+	_, _ = w.Write([]byte("abc"))
+
+	err := w.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, "rle: written 3 bytes, but header size is 4", err.Error())
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("abc"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("abbbc"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("aa"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, header(compression.Header{}), first.Bytes())
+	assert.Equal(t, append(header(compression.Header{}), 255, 'a', endMark), second.Bytes())
+}
@@ -0,0 +1,246 @@
+package std
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// unixCompressMagic is the signature of the .Z files of compress(1).
+	unixCompressMagic = "\x1f\x9d"
+
+	// compressBitsMask masks the maximum code width in the third byte of the header,
+	// compressBlockMode is set there when the clear code is used.
+	compressBitsMask  = 0x1f
+	compressBlockMode = 0x80
+
+	compressMinWidth = 9
+	compressMaxWidth = 16
+	// compressClearCode resets the dictionary in the block mode, the codes below it are bytes.
+	compressClearCode = 256
+	// compressGroupSize is the number of codes compress(1) reads at once: when the width of the codes changes,
+	// the rest of the group is padding.
+	compressGroupSize = 8
+
+	compressBufferSize = 4096
+	compressNoCode     = 1<<32 - 1
+)
+
+// compressReader is an io.ReadCloser that unpacks the .Z data of compress(1) read from the underlying reader.
+//
+// The data is the LZW codes in LSB order, they are 9 bits wide and grow up to the maximum width of the header.
+// In the block mode the clear code resets the dictionary and the width, otherwise the dictionary stays
+// the same once it is full.
+type compressReader struct {
+	r         io.ByteReader
+	maxWidth  uint
+	blockMode bool
+	// acc holds the nbits bits read but not consumed, the first bit is the least significant one.
+	acc   uint32
+	nbits uint
+	width uint
+	// the width grows when the dictionary size exceeds maxCode.
+	maxCode uint32
+	// codes is the number of codes read with the current width.
+	codes uint
+	// prefix and suffix hold the dictionary: the string of a code is the string of its prefix code
+	// followed by its suffix byte.
+	prefix  []uint32
+	suffix  []byte
+	free    uint32
+	last    uint32
+	buf     []byte
+	decoded []byte
+	err     error
+}
+
+// newCompressReader reads and validates the header of the .Z data read from r, it returns the reader
+// unpacking the data following it.
+func newCompressReader(r io.Reader) (*compressReader, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	var header [len(unixCompressMagic) + 1]byte
+	for i := range header {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		header[i] = b
+	}
+	if string(header[:len(unixCompressMagic)]) != unixCompressMagic {
+		return nil, errors.New("compress: invalid header")
+	}
+
+	maxWidth := uint(header[2] & compressBitsMask)
+	if maxWidth < compressMinWidth || maxWidth > compressMaxWidth {
+		return nil, fmt.Errorf("compress: maximum code width %d is out of range", maxWidth)
+	}
+
+	z := &compressReader{
+		r:         br,
+		maxWidth:  maxWidth,
+		blockMode: header[2]&compressBlockMode != 0,
+		prefix:    make([]uint32, 1<<maxWidth),
+		suffix:    make([]byte, 1<<maxWidth),
+		free:      compressClearCode,
+		last:      compressNoCode,
+	}
+	if z.blockMode {
+		z.free++
+	}
+	z.resetWidth()
+
+	return z, nil
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *compressReader) Read(p []byte) (int, error) {
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.decode(z.buf[:0])
+		z.buf = z.decoded
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the reader. It does not close the underlying reader.
+func (z *compressReader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// decode reads codes until at least compressBufferSize bytes are decoded, appends them to dst and returns
+// the extended slice. It returns io.EOF with the rest of the data when the data ends.
+func (z *compressReader) decode(dst []byte) ([]byte, error) {
+	for len(dst) < compressBufferSize {
+		if z.free > z.maxCode {
+			if err := z.skipGroup(); err != nil {
+				return dst, err
+			}
+			z.width++
+			z.maxCode = 1<<z.width - 1
+			if z.width == z.maxWidth {
+				z.maxCode++
+			}
+		}
+
+		code, err := z.readCode()
+		if err != nil {
+			return dst, err
+		}
+
+		if z.last == compressNoCode {
+			if code >= compressClearCode {
+				return dst, fmt.Errorf("compress: invalid first code %d", code)
+			}
+			z.last = code
+			dst = append(dst, byte(code))
+			continue
+		}
+		if z.blockMode && code == compressClearCode {
+			// the next code assigns the clear code, which is never read as a string
+			z.free = compressClearCode
+			if err := z.skipGroup(); err != nil {
+				return dst, err
+			}
+			z.resetWidth()
+			continue
+		}
+
+		start := len(dst)
+		switch {
+		case code > z.free:
+			return dst, fmt.Errorf("compress: invalid code %d", code)
+		case code == z.free:
+			// the code being assigned expands to the last string followed by its first byte
+			dst = z.expand(dst, z.last)
+			dst = append(dst, dst[start])
+		default:
+			dst = z.expand(dst, code)
+		}
+
+		if z.free < 1<<z.maxWidth {
+			z.prefix[z.free] = z.last
+			z.suffix[z.free] = dst[start]
+			z.free++
+		}
+		z.last = code
+	}
+
+	return dst, nil
+}
+
+// resetWidth sets the width of the codes to the minimum one. Like compress(1), it does not check the maximum width,
+// so the codes of the maximum width of 9 bits grow to 10 bits when the dictionary is full.
+func (z *compressReader) resetWidth() {
+	z.width = compressMinWidth
+	z.maxCode = 1<<z.width - 1
+}
+
+// readCode reads the next code of the current width. The bits of an incomplete code at the end of the data
+// are ignored, as compress(1) does.
+func (z *compressReader) readCode() (uint32, error) {
+	for z.nbits < z.width {
+		b, err := z.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		z.acc |= uint32(b) << z.nbits
+		z.nbits += 8
+	}
+
+	code := z.acc & (1<<z.width - 1)
+	z.acc >>= z.width
+	z.nbits -= z.width
+	z.codes++
+
+	return code, nil
+}
+
+// skipGroup skips the rest of the group of the codes read with the current width.
+func (z *compressReader) skipGroup() error {
+	for z.codes%compressGroupSize != 0 {
+		if _, err := z.readCode(); err != nil {
+			return err
+		}
+	}
+	z.codes = 0
+
+	return nil
+}
+
+// expand appends the string of the code to dst.
+func (z *compressReader) expand(dst []byte, code uint32) []byte {
+	n := 1
+	for c := code; c >= compressClearCode; c = z.prefix[c] {
+		n++
+	}
+
+	start := len(dst)
+	for i := 0; i < n; i++ {
+		dst = append(dst, 0)
+	}
+	for i := len(dst) - 1; i > start; i-- {
+		dst[i] = z.suffix[code]
+		code = z.prefix[code]
+	}
+	dst[start] = byte(code)
+
+	return dst
+}
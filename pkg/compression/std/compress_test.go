@@ -0,0 +1,220 @@
+package std
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// compressPacked returns data packed the way compress(1) does with the maximum code width. In the block mode
+// the clear code is written after every clearEvery codes, unless it is 0.
+func compressPacked(data []byte, maxWidth uint, blockMode bool, clearEvery int) []byte {
+	var (
+		out     []byte
+		acc     uint32
+		nbits   uint
+		width   uint   = compressMinWidth
+		maxCode uint32 = 1<<compressMinWidth - 1
+		codes   int
+	)
+	first := uint32(compressClearCode)
+	if blockMode {
+		first++
+	}
+
+	write := func(code uint32) {
+		acc |= code << nbits
+		nbits += width
+		codes++
+		for ; nbits >= 8; nbits -= 8 {
+			out = append(out, byte(acc))
+			acc >>= 8
+		}
+	}
+	pad := func() {
+		for codes%compressGroupSize != 0 {
+			write(0)
+		}
+		codes = 0
+	}
+
+	// free is the size of the dictionary of the reader, it grows with every code but the first one
+	free, started := first, false
+	put := func(code uint32) {
+		if free > maxCode {
+			pad()
+			width++
+			maxCode = 1<<width - 1
+			if width == maxWidth {
+				maxCode++
+			}
+		}
+		write(code)
+		if started && free < 1<<maxWidth {
+			free++
+		}
+		started = true
+	}
+	clear := func() {
+		put(compressClearCode)
+		free = compressClearCode
+		pad()
+		width, maxCode = compressMinWidth, 1<<compressMinWidth-1
+	}
+
+	dict, next := map[uint32]uint32{}, first
+	code, n := uint32(compressNoCode), 0
+	for _, c := range data {
+		if code == compressNoCode {
+			code = uint32(c)
+			continue
+		}
+		if v, ok := dict[code<<8|uint32(c)]; ok {
+			code = v
+			continue
+		}
+
+		put(code)
+		if next < 1<<maxWidth {
+			dict[code<<8|uint32(c)] = next
+			next++
+		}
+		code = uint32(c)
+
+		if n++; blockMode && clearEvery > 0 && n%clearEvery == 0 {
+			clear()
+			dict, next = map[uint32]uint32{}, first
+		}
+	}
+	if code != compressNoCode {
+		put(code)
+	}
+	if nbits > 0 {
+		out = append(out, byte(acc))
+	}
+
+	flags := byte(maxWidth)
+	if blockMode {
+		flags |= compressBlockMode
+	}
+	return append([]byte{unixCompressMagic[0], unixCompressMagic[1], flags}, out...)
+}
+
+// compressSample returns the data growing the dictionary to the maximum width and filling it.
+func compressSample() []byte {
+	rnd := rand.New(rand.NewSource(1))
+	words := []string{"the ", "quick ", "brown ", "fox ", "jumps ", "over ", "lazy ", "dog ", "\n"}
+
+	var buf bytes.Buffer
+	for buf.Len() < 1<<20 {
+		buf.WriteString(words[rnd.Intn(len(words))])
+		buf.WriteByte(byte(rnd.Intn(256)))
+	}
+	return buf.Bytes()
+}
+
+func TestCompressReader(t *testing.T) {
+	t.Parallel()
+
+	sample := compressSample()
+	tests := []struct {
+		name       string
+		data       []byte
+		maxWidth   uint
+		blockMode  bool
+		clearEvery int
+	}{
+		{name: "empty data", data: []byte{}, maxWidth: 16, blockMode: true},
+		{name: "single byte", data: []byte("a"), maxWidth: 16, blockMode: true},
+		{name: "repeated string", data: bytes.Repeat([]byte("ab"), 1000), maxWidth: 16, blockMode: true},
+		{name: "sample", data: sample, maxWidth: 16, blockMode: true},
+		{name: "sample", data: sample, maxWidth: 12, blockMode: true},
+		{name: "sample", data: sample, maxWidth: 9, blockMode: true},
+		{name: "sample", data: sample, maxWidth: 16},
+		{name: "sample", data: sample, maxWidth: 10},
+		{name: "sample", data: sample, maxWidth: 9},
+		{name: "sample with clear codes", data: sample, maxWidth: 16, blockMode: true, clearEvery: 1000},
+		{name: "sample with clear codes", data: sample, maxWidth: 13, blockMode: true, clearEvery: 5003},
+		{name: "sample with clear codes", data: sample, maxWidth: 9, blockMode: true, clearEvery: 3},
+		{name: "sample with clear codes", data: sample[:1000], maxWidth: 16, blockMode: true, clearEvery: 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf(
+			"unpacking %s with %d bits, block mode %t, clear every %d codes",
+			test.name, test.maxWidth, test.blockMode, test.clearEvery,
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			packed := compressPacked(test.data, test.maxWidth, test.blockMode, test.clearEvery)
+			for _, format := range []Format{Compress, LZW} {
+				unpacked, err := format.Unpack(packed)
+				assert.Nilf(t, err, "%v.Unpack() unexpected error", format)
+				assert.Equalf(t, test.data, unpacked, "%v.Unpack() unexpected data", format)
+			}
+		})
+	}
+}
+
+func TestCompressReaderHeader(t *testing.T) {
+	t.Parallel()
+
+	// "abc" packed the way compress -b 9 packs it
+	unpacked, err := Compress.Unpack([]byte{0x1f, 0x9d, 0x89, 0x61, 0xc4, 0x8c, 0x01})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abc"), unpacked)
+
+	r := NewReader(bytes.NewReader(compressPacked([]byte("abc"), 16, true, 0)), Compress)
+	assert.Nil(t, r.Close())
+	_, err = r.Read(make([]byte, 3))
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestCompressReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, error string
+		data        []byte
+	}{
+		{name: "empty data", data: []byte{}, error: "std: packed data is corrupted: unexpected EOF"},
+		{name: "truncated header", data: []byte{0x1f, 0x9d}, error: "std: packed data is corrupted: unexpected EOF"},
+		{name: "wrong magic", data: []byte{0x1f, 0x8b, 0x90}, error: "std: packed data is corrupted: compress: invalid header"},
+		{
+			name:  "too narrow codes",
+			data:  []byte{0x1f, 0x9d, 0x88},
+			error: "std: packed data is corrupted: compress: maximum code width 8 is out of range",
+		},
+		{
+			name:  "too wide codes",
+			data:  []byte{0x1f, 0x9d, 0x91},
+			error: "std: packed data is corrupted: compress: maximum code width 17 is out of range",
+		},
+		{
+			name:  "clear code first",
+			data:  []byte{0x1f, 0x9d, 0x90, 0x00, 0x01},
+			error: "std: packed data is corrupted: compress: invalid first code 256",
+		},
+		{
+			name:  "code beyond dictionary",
+			data:  []byte{0x1f, 0x9d, 0x90, 0x61, 0x04, 0x02},
+			error: "std: packed data is corrupted: compress: invalid code 258",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data), Compress))
+			assert.ErrorIsf(t, err, ErrCorrupted, "Reader.Read(%v) unexpected error", test.data)
+			assert.EqualErrorf(t, err, test.error, "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
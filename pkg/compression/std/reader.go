@@ -0,0 +1,132 @@
+package std
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/lzw"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrClosed      = errors.New("std: stream is closed")
+	ErrCorrupted   = errors.New("std: packed data is corrupted")
+	ErrUnsupported = errors.New("std: unsupported format")
+)
+
+// Reader is an io.ReadCloser that unpacks the data of a standard format read from the underlying reader.
+//
+// The header of the format, if any, is read and validated on the first call to Read.
+type Reader struct {
+	format Format
+	r      *sourceReader
+	rc     io.ReadCloser
+	opened bool
+	err    error
+}
+
+// NewReader returns a new Reader unpacking the data of the format read from r.
+//
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader, format Format) *Reader {
+	return &Reader{format: format, r: &sourceReader{r: r}}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+//
+// Errors of the decoder are wrapped with ErrCorrupted, errors of the underlying reader are returned as they are.
+func (z *Reader) Read(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if !z.opened {
+		z.opened = true
+		if z.rc, z.err = z.open(); z.err != nil {
+			return 0, z.err
+		}
+	}
+
+	n, err := z.rc.Read(p)
+	if err != nil {
+		z.err = z.decoderError(err)
+	}
+
+	return n, z.err
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	if z.rc != nil {
+		_ = z.rc.Close()
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r, format)
+// with the format of the Reader.
+func (z *Reader) Reset(r io.Reader) {
+	z.r = &sourceReader{r: r}
+	z.rc = nil
+	z.opened = false
+	z.err = nil
+}
+
+func (z *Reader) open() (io.ReadCloser, error) {
+	switch z.format {
+	case Bzip2:
+		return io.NopCloser(bzip2.NewReader(z.r)), nil
+	case Zlib:
+		rc, err := zlib.NewReader(z.r)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, z.decoderError(err)
+		}
+		return rc, nil
+	case LZW:
+		br := bufio.NewReader(z.r)
+		if magic, _ := br.Peek(len(unixCompressMagic)); string(magic) == unixCompressMagic {
+			return z.openCompress(br)
+		}
+		return lzw.NewReader(br, lzw.MSB, 8), nil
+	case Compress:
+		return z.openCompress(bufio.NewReader(z.r))
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrUnsupported, z.format)
+}
+
+func (z *Reader) openCompress(r io.Reader) (io.ReadCloser, error) {
+	rc, err := newCompressReader(r)
+	if err != nil {
+		return nil, z.decoderError(err)
+	}
+	return rc, nil
+}
+
+// decoderError wraps err with ErrCorrupted unless it is io.EOF or an error of the underlying reader.
+func (z *Reader) decoderError(err error) error {
+	if err == io.EOF || err == z.r.err {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrCorrupted, err)
+}
+
+// sourceReader remembers the error of the underlying reader, so it can be told from the errors of the decoders.
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *sourceReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,85 @@
+package std
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	zlibData := zlibPacked("abcabcabc")
+	badChecksum := append([]byte{}, zlibData...)
+	badChecksum[len(badChecksum)-1] ^= 0xFF
+
+	tests := []struct {
+		name   string
+		format Format
+		data   []byte
+	}{
+		{name: "empty data", format: Bzip2, data: []byte{}},
+		{name: "data with wrong magic", format: Bzip2, data: []byte("abcabcabc")},
+		{name: "truncated data", format: Bzip2, data: bzip2Hello[:len(bzip2Hello)-5]},
+		{name: "empty data", format: Zlib, data: []byte{}},
+		{name: "data with wrong header", format: Zlib, data: []byte("abcabcabc")},
+		{name: "truncated data", format: Zlib, data: zlibData[:len(zlibData)-5]},
+		{name: "data with wrong checksum", format: Zlib, data: badChecksum},
+		{name: "empty data", format: LZW, data: []byte{}},
+		{name: "data with invalid code", format: LZW, data: []byte{0xFF, 0xFF, 0xFF}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s with %v", test.name, test.format)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data), test.format))
+			assert.ErrorIs(t, err, ErrCorrupted)
+		})
+	}
+}
+
+func TestReaderUnsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := Format(9).Unpack(zlibPacked("abc"))
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestReaderSourceError(t *testing.T) {
+	t.Parallel()
+
+	errSource := errors.New("source error")
+	r := NewReader(io.MultiReader(bytes.NewReader(zlibPacked("abc")[:4]), iotest.ErrReader(errSource)), Zlib)
+
+	_, err := io.ReadAll(r)
+	assert.Equal(t, errSource, err, "errors of the underlying reader must be returned as they are")
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(zlibPacked("abc")), Zlib)
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 3))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(zlibPacked("abc")), Zlib)
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(zlibPacked("def")))
+	unpacked, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("def"), unpacked)
+}
@@ -0,0 +1,90 @@
+// Package std unpacks the standard compressed formats with the decoders of the Go standard library,
+// so the files packed by other tools can be unpacked along with the archiver's own ones.
+//
+// The gzip format is unpacked by the gzip package, which packs it as well.
+package std
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// Format is a standard compressed format, it unpacks the data packed to it.
+type Format uint8
+
+const (
+	// Bzip2 is the format of bzip2, read with compress/bzip2.
+	Bzip2 Format = iota + 1
+	// Zlib is the format of RFC 1950, read with compress/zlib.
+	Zlib
+	// LZW is the bare stream of LZW codes read with compress/lzw with MSB order and 8-bit literals,
+	// as written by the lzw package with Options.Compatible, by TIFF and PDF encoders.
+	//
+	// The .Z files of compress(1), told by their magic, are read as Compress.
+	LZW
+	// Compress is the format of the .Z files of compress(1): the LZW codes in LSB order, up to 16 bits wide,
+	// with the optional clear code of the block mode.
+	Compress
+)
+
+func init() {
+	register(Bzip2, "bz2", []byte("BZh"), nil)
+	// the zlib header has no fixed signature, its first byte depends on the window size
+	register(Zlib, "zlib", nil, isZlibHeader)
+	register(Compress, "Z", []byte(unixCompressMagic), nil)
+}
+
+// register registers the format as a codec that only unpacks.
+func register(f Format, ext string, magic []byte, match func(header []byte) bool) {
+	compression.Register(f.String(), compression.Factory{
+		Description: f.String(),
+		Extension:   ext,
+		Magic:       magic,
+		Match:       match,
+		Unpack: func(_ *flag.FlagSet) compression.ReaderFunc {
+			return func(r io.Reader) io.ReadCloser {
+				return NewReader(r, f)
+			}
+		},
+	})
+}
+
+// isZlibHeader reports whether the header starts with a zlib header of the deflate method without
+// a preset dictionary: the first two bytes as a big endian number are a multiple of 31.
+func isZlibHeader(header []byte) bool {
+	const (
+		methodDeflate = 8
+		maxWindowLog  = 7
+		flagDict      = 0x20
+	)
+
+	return len(header) >= 2 &&
+		header[0]&0x0f == methodDeflate && header[0]>>4 <= maxWindowLog &&
+		header[1]&flagDict == 0 &&
+		(uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+var formatNames = map[Format]string{
+	Bzip2:    "bzip2",
+	Zlib:     "zlib",
+	LZW:      "lzw",
+	Compress: "compress",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("format(%d)", uint8(f))
+}
+
+// Unpack unpacks the data packed to the format.
+func (f Format) Unpack(data []byte) ([]byte, error) {
+	r := NewReader(bytes.NewReader(data), f)
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,131 @@
+package std
+
+import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
+	"flag"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+// bzip2Hello is "hello, world\n" packed by bzip2, the standard library can't pack bzip2.
+var bzip2Hello = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x54, 0xa4, 0x97, 0x84, 0x00, 0x00,
+	0x02, 0xd1, 0x80, 0x00, 0x10, 0x40, 0x04, 0x06, 0x44, 0x90, 0x80, 0x20, 0x00, 0x31, 0x00, 0x30,
+	0x20, 0x68, 0x62, 0x00, 0x49, 0xd4, 0xb2, 0x1f, 0x3f, 0x17, 0x72, 0x45, 0x38, 0x50, 0x90, 0x54,
+	0xa4, 0x97, 0x84,
+}
+
+// zlibPacked returns str packed by compress/zlib.
+func zlibPacked(str string) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, _ = w.Write([]byte(str))
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+// lzwPacked returns str packed by compress/lzw.
+func lzwPacked(str string) []byte {
+	var buf bytes.Buffer
+	w := lzw.NewWriter(&buf, lzw.MSB, 8)
+	_, _ = w.Write([]byte(str))
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func TestFormatUnpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, want string
+		format     Format
+		data       []byte
+	}{
+		{name: "text packed by bzip2", want: "hello, world\n", format: Bzip2, data: bzip2Hello},
+		{name: "empty data packed by compress/zlib", want: "", format: Zlib, data: zlibPacked("")},
+		{name: "text packed by compress/zlib", want: "abcabcabc", format: Zlib, data: zlibPacked("abcabcabc")},
+		{name: "text packed by compress/lzw", want: "abcabcabc", format: LZW, data: lzwPacked("abcabcabc")},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %s with %v", test.name, test.format)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			unpacked, err := test.format.Unpack(test.data)
+			assert.Nil(t, err)
+			assert.Equal(t, []byte(test.want), unpacked)
+		})
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "bzip2", Bzip2.String())
+	assert.Equal(t, "zlib", Zlib.String())
+	assert.Equal(t, "lzw", LZW.String())
+	assert.Equal(t, "compress", Compress.String())
+	assert.Equal(t, "format(9)", Format(9).String())
+}
+
+func TestFormatDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "bzip2", data: bzip2Hello},
+		{name: "zlib", data: zlibPacked("hello, world\n")},
+		{name: "compress", data: compressPacked([]byte("hello, world\n"), 16, true, 0)},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("detect %s", test.name), func(t *testing.T) {
+			t.Parallel()
+			f, _ := compression.Lookup(test.name)
+
+			detected, err := compression.Detect(test.data)
+			assert.Nil(t, err)
+			assert.Equal(t, test.name, detected)
+
+			unpacked, err := io.ReadAll(f.Unpack(flag.NewFlagSet(test.name, flag.ContinueOnError))(bytes.NewReader(test.data)))
+			assert.Nil(t, err)
+			assert.Equal(t, []byte("hello, world\n"), unpacked)
+		})
+	}
+}
+
+func TestIsZlibHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header []byte
+		want   bool
+	}{
+		{header: []byte{0x78, 0x9c}, want: true},
+		{header: []byte{0x78, 0x01}, want: true},
+		{header: []byte{0x08, 0x1d}, want: true},
+		{header: []byte{0x78}, want: false},
+		{header: []byte{0x78, 0x9d}, want: false},
+		{header: []byte{0x79, 0x5b}, want: false},
+		{header: []byte{0x88, 0x98}, want: false},
+		// the preset dictionary is not supported
+		{header: []byte{0x78, 0xbb}, want: false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("checking zlib header % x", test.header), func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, isZlibHeader(test.header))
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package vlc
 
-import "strings"
+import (
+	"github.com/psssix/archiver/pkg/bitio"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const noChar = rune(0)
 
 type decodingTree struct {
 	char rune
@@ -8,11 +15,19 @@ type decodingTree struct {
 	one  *decodingTree
 }
 
+// newDecodingTree builds the tree of the table codes, the chars are added in ascending order,
+// so the tree does not depend on the map iteration order.
 func newDecodingTree(et encodingTable) *decodingTree {
 	tree := new(decodingTree)
 
-	for char, code := range et {
-		tree.add(code, char)
+	chars := make([]rune, 0, len(et))
+	for char := range et {
+		chars = append(chars, char)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	for _, char := range chars {
+		tree.add(et[char], char)
 	}
 
 	return tree
@@ -39,29 +54,177 @@ func (dt *decodingTree) add(code string, char rune) {
 	current.char = char
 }
 
-func (dt *decodingTree) decodeBinary(bString string) string {
-	var buf strings.Builder
+// depth returns the length of the longest code of the tree.
+func (dt *decodingTree) depth() uint {
+	var depth uint
 
-	current := dt
+	for _, child := range []*decodingTree{dt.zero, dt.one} {
+		if child == nil {
+			continue
+		}
+		if d := child.depth() + 1; d > depth {
+			depth = d
+		}
+	}
+
+	return depth
+}
+
+type (
+	// decodingTable is the decodingTree unrolled to decode a code by looking up the next depth bits at once.
+	decodingTable struct {
+		entries []tableEntry
+		depth   uint
+	}
+	// tableEntry is the character of the code the looked up bits start with and the length of the code.
+	tableEntry struct {
+		char rune
+		len  uint8
+	}
+)
+
+func newDecodingTable(dt *decodingTree) *decodingTable {
+	depth := dt.depth()
+	table := &decodingTable{entries: make([]tableEntry, 1<<depth), depth: depth}
+	table.add(dt, 0, 0)
 
-	for _, bit := range bString {
-		if current.char != rune(0) {
-			buf.WriteRune(current.char)
-			current = dt
+	return table
+}
+
+// add adds entries for the leaves of the subtree dt reached by the code of len bits.
+func (t *decodingTable) add(dt *decodingTree, code uint64, len uint) {
+	if dt == nil {
+		return
+	}
+
+	if dt.char != noChar {
+		shift := t.depth - len
+		for i := code << shift; i < (code+1)<<shift; i++ {
+			t.entries[i] = tableEntry{char: dt.char, len: uint8(len)}
 		}
 
-		switch bit {
-		case '0':
-			current = current.zero
-		case '1':
-			current = current.one
+		return
+	}
+
+	t.add(dt.zero, code<<1, len+1)
+	t.add(dt.one, code<<1|1, len+1)
+}
+
+// decode reads exactly the given number of bits written by byteCodes.encode from r,
+// appends the decoded bytes to dst and returns the extended slice.
+//
+// The offset of the returned CorruptInputError is the count of r at the start of the incomplete code.
+func (t *decodingTable) decode(r *bitio.BitReader, bits uint64, dst []byte) ([]byte, error) {
+	var (
+		upper   bool
+		upperAt uint64
+		// caps and title are the modes set by capsMark and titleMark,
+		// wordStart tells the next letter starts a word in title case.
+		caps, title, wordStart bool
+	)
+
+	for n := uint64(0); n < bits; {
+		start := r.Count()
+		peek := t.depth
+		if left := bits - n; left < uint64(peek) {
+			peek = uint(left)
+		}
+
+		v, err := r.PeekBits(peek)
+		if err != nil {
+			return dst, bitio.NoEOF(err)
 		}
+
+		entry := t.entries[v<<(t.depth-peek)]
+		if entry.len == 0 || uint(entry.len) > peek {
+			return dst, newCorruptInputError(start, "block ends with incomplete code")
+		}
+		_ = r.Discard(uint(entry.len))
+		n += uint64(entry.len)
+
+		switch entry.char {
+		case upperMark:
+			upper, upperAt = true, start
+		case capsMark:
+			caps = true
+		case titleMark:
+			title, wordStart = true, true
+		case rawMark:
+			if bits-n < rawBits {
+				return dst, newCorruptInputError(start, "block ends with incomplete code")
+			}
+
+			b, err := r.ReadBits(rawBits)
+			if err != nil {
+				return dst, bitio.NoEOF(err)
+			}
+			dst = append(dst, byte(b))
+			n += rawBits
+			// the letters are never stored as is
+			caps, title = false, false
+		case runeMark:
+			if bits-n < rawBits {
+				return dst, newCorruptInputError(start, "block ends with incomplete code")
+			}
+			lead, err := r.PeekBits(rawBits)
+			if err != nil {
+				return dst, bitio.NoEOF(err)
+			}
+			size := runeLen(byte(lead))
+			if size == 0 {
+				return dst, newCorruptInputError(start, "invalid UTF-8 rune")
+			}
+			if bits-n < uint64(size)*rawBits {
+				return dst, newCorruptInputError(start, "block ends with incomplete code")
+			}
+
+			for i := 0; i < size; i++ {
+				b, err := r.ReadBits(rawBits)
+				if err != nil {
+					return dst, bitio.NoEOF(err)
+				}
+				dst = append(dst, byte(b))
+			}
+			n += uint64(size) * rawBits
+			if _, decoded := utf8.DecodeRune(dst[len(dst)-size:]); decoded != size {
+				return dst[:len(dst)-size], newCorruptInputError(start, "invalid UTF-8 rune")
+			}
+			caps, title = false, false
+		default:
+			char := entry.char
+			if upper || caps || title && wordStart {
+				char = unicode.ToUpper(char)
+				upper = false
+			}
+
+			letter := 'a' <= entry.char && entry.char <= 'z'
+			if !letter {
+				caps = false
+				title = title && char == ' '
+			}
+			wordStart = !letter
+			dst = append(dst, byte(char))
+		}
+	}
+
+	if upper {
+		return dst, newCorruptInputError(upperAt, "block ends with incomplete code")
 	}
 
-	if current.char != rune(0) {
-		buf.WriteRune(current.char)
-		current = dt
+	return dst, nil
+}
+
+// runeLen returns the length of the UTF-8 encoded rune starting with the byte,
+// it is 0 if the byte does not start a multibyte rune.
+func runeLen(lead byte) int {
+	switch {
+	case 0xc2 <= lead && lead < 0xe0:
+		return 2
+	case 0xe0 <= lead && lead < 0xf0:
+		return 3
+	case 0xf0 <= lead && lead < 0xf5:
+		return 4
 	}
 
-	return buf.String()
+	return 0
 }
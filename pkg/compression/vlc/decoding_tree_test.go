@@ -1,8 +1,11 @@
 package vlc
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"testing"
 )
 
@@ -50,29 +53,139 @@ func TestNewDecodingTree(t *testing.T) {
 	}
 }
 
-func TestDecodingTreeDecodeBinary(t *testing.T) {
+func TestNewDecodingTable(t *testing.T) {
+	t.Parallel()
+
+	dt := newDecodingTree(encodingTable{
+		'a': "11",
+		'b': "1001",
+		'c': "0101",
+	})
+	want := &decodingTable{
+		entries: []tableEntry{
+			{}, {}, {}, {}, {}, {char: 'c', len: 4}, {}, {},
+			{}, {char: 'b', len: 4}, {}, {}, {char: 'a', len: 2}, {char: 'a', len: 2}, {char: 'a', len: 2}, {char: 'a', len: 2},
+		},
+		depth: 4,
+	}
+
+	assert.Equal(t, uint(4), dt.depth())
+	assert.Equal(t, want, newDecodingTable(dt))
+}
+
+func TestDecodingTableDecode(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name, bString, want string
 	}{
 		{bString: "", want: ""},
-		{bString: "001000100110100101", want: "!ted"},
-		{bString: "001000000011000000111100000110000111011101001010111001000100110100101", want: "!my name is !ted"},
+		{bString: "001000100110100101", want: "Ted"},
+		{bString: "001000000011000000111100000110000111011101001010111001000100110100101", want: "My name is Ted"},
 		{
 			bString: "0010000101100010000111011100001010100010110011001000000111001000010100100000011001000000001001011010000000000010001110110000000101101",
-			want:    "!some pretty !s!u!bsequence",
+			want:    "Some pretty SUBsequence",
 		},
+		{bString: "0000000000001" + "00100001", want: "!"},
+		{bString: "001000" + "0011" + "01001" + "0000000000001" + "00100001", want: "Hi!"},
+		{bString: "011" + "0000000000001" + "00001010" + "0000010", want: "a\nb"},
+		{bString: "0000000000001" + "11001111" + "0000000000001" + "10000000", want: "π"},
+		{bString: "00000000000001" + "11001111" + "10000000", want: "π"},
+		{bString: "00000000000001" + "11110000" + "10011111" + "10011000" + "10000000" + "00000000000000", want: "😀z"},
+		{bString: "000000011" + "0101" + "000000000001" + "001001" + "11" + "0101", want: "SQL s"},
+		{bString: "000000011" + "0011" + "1001" + "1001" + "0000101" + "0000000000001" + "00101111" + "0011", want: "HTTP/h"},
+		{bString: "000000011" + "0011" + "00000000000001" + "11001111" + "10000000" + "0011", want: "Hπh"},
+		{bString: "0000000011" + "0011" + "01001" + "11" + "11" + "0000010" + "10001" + "0000010", want: "Hi  Bob"},
+		{bString: "0000000011" + "0011" + "01001" + "11" + "0000010" + "10001" + "0000010" + "0000000000001" + "00101110" + "11" + "011", want: "Hi Bob. a"},
 	}
 
-	dt := newDecodingTree(newEncodingTable())
+	dt := newDecodingTable(newDecodingTree(newEncodingTable()))
 
 	for _, test := range tests {
 		test := test
 		test.name = fmt.Sprintf("decoding %q", test.want)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			assert.Equalf(t, test.want, dt.decodeBinary(test.bString), "decodingTree(...).decodeBinary(%v)", test.bString)
+			r := bitio.NewBitReader(bytes.NewReader(fromBitString(test.bString)))
+			decoded, err := dt.decode(r, uint64(len(test.bString)), []byte{})
+			assert.Nilf(t, err, "decodingTable(...).decode(%v) unexpected error", test.bString)
+			assert.Equalf(t, []byte(test.want), decoded, "decodingTable(...).decode(%v)", test.bString)
+			assert.Equalf(t, uint64(len(test.bString)), r.Count(), "decodingTable(...).decode(%v) read bits", test.bString)
 		})
 	}
 }
+
+func TestDecodingTableDecodeIncompleteCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, bString, want string
+		offset              uint64
+	}{
+		{name: "code", bString: "001000100110100101" + "000000", want: "Ted", offset: 18},
+		{name: "raw byte", bString: "001000100110100101" + "0000000000001" + "0010", want: "Ted", offset: 18},
+		{name: "raw byte code", bString: "001000100110100101" + "000000000000", want: "Ted", offset: 18},
+		{name: "upper case letter", bString: "001000100110100101" + "001000" + "10", want: "Ted", offset: 24},
+		{name: "upper case mark", bString: "001000100110100101" + "001000", want: "Ted", offset: 18},
+		{name: "rune code", bString: "001000100110100101" + "0000000000000", want: "Ted", offset: 18},
+		{name: "rune", bString: "001000100110100101" + "00000000000001" + "11001111", want: "Ted", offset: 18},
+		{name: "rune lead byte", bString: "001000100110100101" + "00000000000001" + "1100", want: "Ted", offset: 18},
+	}
+
+	dt := newDecodingTable(newDecodingTree(newEncodingTable()))
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding %q followed by incomplete %s", test.want, test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := bitio.NewBitReader(bytes.NewReader(fromBitString(test.bString)))
+			decoded, err := dt.decode(r, uint64(len(test.bString)), []byte{})
+			assert.Equalf(t, []byte(test.want), decoded, "decodingTable(...).decode(%v)", test.bString)
+			assert.ErrorIsf(t, err, ErrCorrupted, "decodingTable(...).decode(%v) unexpected error", test.bString)
+			var corrupt *CorruptInputError
+			if assert.ErrorAsf(t, err, &corrupt, "decodingTable(...).decode(%v) unexpected error", test.bString) {
+				assert.Equalf(t, test.offset, corrupt.Offset, "decodingTable(...).decode(%v) error offset", test.bString)
+			}
+		})
+	}
+}
+
+func TestDecodingTableDecodeInvalidRune(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, bString string
+	}{
+		{name: "ASCII byte", bString: "00000000000001" + "01100001"},
+		{name: "continuation byte", bString: "00000000000001" + "10000000" + "10000000"},
+		{name: "overlong encoding", bString: "00000000000001" + "11000000" + "10000000"},
+		{name: "missing continuation byte", bString: "00000000000001" + "11001111" + "01100001"},
+		{name: "surrogate half", bString: "00000000000001" + "11101101" + "10100000" + "10000000"},
+	}
+
+	dt := newDecodingTable(newDecodingTree(newEncodingTable()))
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("decoding rune with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := bitio.NewBitReader(bytes.NewReader(fromBitString("011" + test.bString)))
+			decoded, err := dt.decode(r, uint64(3+len(test.bString)), []byte{})
+			assert.Equalf(t, []byte("a"), decoded, "decodingTable(...).decode(%v)", test.bString)
+			assert.ErrorIsf(t, err, ErrCorrupted, "decodingTable(...).decode(%v) unexpected error", test.bString)
+			assert.Containsf(t, err.Error(), "at bit 3: invalid UTF-8 rune", "decodingTable(...).decode(%v) unexpected error", test.bString)
+		})
+	}
+}
+
+func TestDecodingTableDecodeUnexpectedEOF(t *testing.T) {
+	t.Parallel()
+
+	dt := newDecodingTable(newDecodingTree(newEncodingTable()))
+	r := bitio.NewBitReader(bytes.NewReader(fromBitString("001000100110100101")))
+
+	_, err := dt.decode(r, 32, []byte{})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
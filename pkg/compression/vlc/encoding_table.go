@@ -1,81 +1,338 @@
 package vlc
 
 import (
-	"fmt"
-	"strings"
+	"github.com/psssix/archiver/pkg/bitio"
+	"unicode"
+	"unicode/utf8"
 )
 
 type (
 	encodingTable map[rune]string
-	EncodingError struct {
-		msg string
-		r   rune
+	// code is a binary code of len bits stored as the lowest bits of bits, the first bit is the most significant one.
+	code struct {
+		bits uint64
+		len  uint
 	}
+	// byteCodes are codes of every byte value and the codes of the marks preceding the UTF-8 encoded runes
+	// and the words in upper and title case.
+	byteCodes struct {
+		bytes                         [256]code
+		runeMark, capsMark, titleMark code
+	}
+)
+
+// The marks are pseudo-characters, their codes never clash with the codes of the characters of the data.
+const (
+	// upperMark is the pseudo-character which code precedes a lower case letter to make it upper case.
+	upperMark rune = -2
+	// rawMark is the pseudo-character which code precedes a byte stored as is,
+	// so any byte missing from the table can still be encoded.
+	rawMark rune = -1
+	// rawBits is the number of bits of a byte stored as is.
+	rawBits = 8
+	// runeMark is the pseudo-character which code precedes a multibyte rune stored as is in UTF-8,
+	// so the runes missing from the table cost a single code instead of one for every byte.
+	runeMark rune = -3
+	// capsMark is the pseudo-character which code makes the letters upper case up to the next non-letter,
+	// so the words in upper case cost a single code.
+	capsMark rune = -4
+	// titleMark is the pseudo-character which code makes the first letters of the words upper case up to the next
+	// character other than a letter or a space, so the runs of the words in title case cost a single code.
+	titleMark rune = -5
 )
 
-func NewEncodingError(msg string, r rune) *EncodingError {
-	return &EncodingError{msg: msg, r: r}
+// maxLookahead is the number of bytes the encoder looks ahead for the end of the words
+// to choose their case marks, the longer words are encoded without capsMark and titleMark.
+const maxLookahead = bufferSize
+
+// newCode converts a string of '0' and '1' to code.
+func newCode(str string) code {
+	var c code
+
+	for _, bit := range str {
+		c.bits <<= 1
+		if bit == '1' {
+			c.bits |= 1
+		}
+		c.len++
+	}
+
+	return c
+}
+
+// append returns the code followed by the other one.
+func (c code) append(other code) code {
+	return code{bits: c.bits<<other.len | other.bits, len: c.len + other.len}
+}
+
+// newByteCodes builds codes of every byte value from the table.
+//
+// Upper case letters are encoded as upperMark + <lower case letter>,
+// bytes missing from the table as rawMark + <8 bits of the byte>.
+func newByteCodes(et encodingTable) *byteCodes {
+	codes := byteCodes{
+		runeMark:  newCode(et[runeMark]),
+		capsMark:  newCode(et[capsMark]),
+		titleMark: newCode(et[titleMark]),
+	}
+
+	for b := range codes.bytes {
+		r := rune(b)
+
+		var prefix code
+		if 'A' <= r && r <= 'Z' {
+			prefix = newCode(et[upperMark])
+			r = unicode.ToLower(r)
+		}
+
+		str, ok := et[r]
+		if !ok {
+			codes.bytes[b] = newCode(et[rawMark]).append(code{bits: uint64(b), len: rawBits})
+
+			continue
+		}
+
+		codes.bytes[b] = prefix.append(newCode(str))
+	}
+
+	return &codes
+}
+
+// encode writes codes of data to w and returns the number of encoded bytes.
+//
+// Valid multibyte runes are encoded as runeMark + <UTF-8 bytes of the rune>, the words as described
+// in encodeWords and the other bytes with their codes. The incomplete rune or word at the end of data
+// is not encoded unless final is set, so it can be completed by the following data.
+func (bc *byteCodes) encode(w *bitio.BitWriter, data []byte, final bool) (int, error) {
+	for i := 0; i < len(data); {
+		if isLetter(data[i]) {
+			n, err := bc.encodeWords(w, data[i:], final)
+			if err != nil || n == 0 {
+				return i, err
+			}
+			i += n
+
+			continue
+		}
+
+		if data[i] >= utf8.RuneSelf {
+			if !final && !utf8.FullRune(data[i:]) {
+				return i, nil
+			}
+
+			// invalid UTF-8 is decoded as a single byte
+			if _, size := utf8.DecodeRune(data[i:]); size > 1 {
+				if err := bc.encodeRune(w, data[i:i+size]); err != nil {
+					return i, err
+				}
+				i += size
+
+				continue
+			}
+		}
+
+		if err := bc.encodeBytes(w, data[i:i+1]); err != nil {
+			return i, err
+		}
+		i++
+	}
+
+	return len(data), nil
+}
+
+// encodeWords writes the codes of the word at the start of data, or of the run of the words in title case,
+// and returns the number of encoded bytes. It returns 0 if the end of the words is not in data yet
+// and final is not set.
+//
+// The case of the words is encoded with the cheapest marks: the runs of two and more words in title case
+// as titleMark + <lower case words>, the words of two and more upper case letters as capsMark + <lower case word>
+// and the upper case letters of the other words with upperMark.
+func (bc *byteCodes) encodeWords(w *bitio.BitWriter, data []byte, final bool) (int, error) {
+	end := wordLen(data)
+	word := data[:end]
+	if end == len(data) && !final {
+		if len(data) < maxLookahead {
+			return 0, nil
+		}
+		// the rest of the word is unknown, so its case cannot be changed up to the next non-letter
+		return end, bc.encodeBytes(w, word)
+	}
+
+	if isTitle(word) {
+		switch n := titleRun(data, final); {
+		case n < 0:
+			return 0, nil
+		case n > 0:
+			return n, bc.encodeLower(w, bc.titleMark, data[:n])
+		}
+	}
+	if len(word) > 1 && isCaps(word) {
+		return end, bc.encodeLower(w, bc.capsMark, word)
+	}
+
+	return end, bc.encodeBytes(w, word)
+}
+
+// encodeBytes writes the codes of the bytes to w.
+func (bc *byteCodes) encodeBytes(w *bitio.BitWriter, data []byte) error {
+	for _, b := range data {
+		c := bc.bytes[b]
+		if err := w.WriteBits(c.bits, c.len); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeLower writes the code of the case mark followed by the codes of the letters in lower case and the spaces to w.
+func (bc *byteCodes) encodeLower(w *bitio.BitWriter, mark code, data []byte) error {
+	if err := w.WriteBits(mark.bits, mark.len); err != nil {
+		return err
+	}
+	for _, b := range data {
+		if isLetter(b) {
+			b |= 'a' - 'A'
+		}
+		c := bc.bytes[b]
+		if err := w.WriteBits(c.bits, c.len); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (e *EncodingError) Error() string {
-	return fmt.Sprintf("encoding to binary error, %s %q", e.msg, e.r)
+// encodeRune writes the code of runeMark followed by the UTF-8 bytes of the rune to w.
+func (bc *byteCodes) encodeRune(w *bitio.BitWriter, utf8Bytes []byte) error {
+	if err := w.WriteBits(bc.runeMark.bits, bc.runeMark.len); err != nil {
+		return err
+	}
+	for _, b := range utf8Bytes {
+		if err := w.WriteBits(uint64(b), rawBits); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// encodeBinary encode string into binary codes string without spaces.
-func encodeBinary(str string) (string, error) {
-	var buf strings.Builder
+// titleRun returns the length of the run of two and more words in title case at the start of data, the words
+// are separated by spaces and the run is followed by a character other than a letter or a space, or by the end
+// of data if final is set. It returns 0 if there is no such run and -1 if the end of the run is not in data yet.
+func titleRun(data []byte, final bool) int {
+	n, words := 0, 0
+
+	for i := 0; ; {
+		end := i + wordLen(data[i:])
+		if !isTitle(data[i:end]) {
+			return 0
+		}
+		n, words = end, words+1
+
+		for end < len(data) && data[end] == ' ' {
+			end++
+		}
+		if end == len(data) {
+			if final {
+				break
+			}
+			if len(data) >= maxLookahead {
+				return 0
+			}
+			return -1
+		}
+		if !isLetter(data[end]) {
+			break
+		}
+		i = end
+	}
 
-	for _, r := range str {
-		binary, err := toBinary(r)
-		if err != nil {
-			return "", err
+	if words < 2 {
+		return 0
+	}
+	return n
+}
+
+// wordLen returns the number of letters at the start of data.
+func wordLen(data []byte) int {
+	for i, b := range data {
+		if !isLetter(b) {
+			return i
 		}
-		buf.WriteString(binary)
 	}
 
-	return buf.String(), nil
+	return len(data)
+}
+
+// isTitle reports whether the word starts with an upper case letter followed by lower case ones.
+func isTitle(word []byte) bool {
+	return len(word) > 0 && isUpper(word[0]) && !hasUpper(word[1:])
 }
 
-func toBinary(r rune) (string, error) {
-	table := newEncodingTable()
+// isCaps reports whether all the letters of the word are upper case.
+func isCaps(word []byte) bool {
+	for _, b := range word {
+		if !isUpper(b) {
+			return false
+		}
+	}
+
+	return true
+}
 
-	code, ok := table[r]
-	if !ok {
-		return "", NewEncodingError("unknown character", r)
+func hasUpper(word []byte) bool {
+	for _, b := range word {
+		if isUpper(b) {
+			return true
+		}
 	}
 
-	return code, nil
+	return false
+}
+
+// isLetter reports whether b is an ASCII letter, the only letters which case is encoded with the marks.
+func isLetter(b byte) bool {
+	return 'a' <= b && b <= 'z' || isUpper(b)
+}
+
+func isUpper(b byte) bool {
+	return 'A' <= b && b <= 'Z'
 }
 
 func newEncodingTable() encodingTable {
 	return encodingTable{
-		' ': "11",
-		't': "1001",
-		'n': "10000",
-		's': "0101",
-		'r': "01000",
-		'd': "00101",
-		'!': "001000",
-		'c': "000101",
-		'm': "000011",
-		'g': "0000100",
-		'b': "0000010",
-		'v': "00000001",
-		'k': "0000000001",
-		'q': "000000000001",
-		'e': "101",
-		'o': "10001",
-		'a': "011",
-		'i': "01001",
-		'h': "0011",
-		'l': "001001",
-		'u': "00011",
-		'f': "000100",
-		'p': "0000101",
-		'w': "0000011",
-		'y': "0000001",
-		'j': "000000001",
-		'x': "00000000001",
-		'z': "000000000000",
+		' ':       "11",
+		't':       "1001",
+		'n':       "10000",
+		's':       "0101",
+		'r':       "01000",
+		'd':       "00101",
+		upperMark: "001000",
+		'c':       "000101",
+		'm':       "000011",
+		'g':       "0000100",
+		'b':       "0000010",
+		'v':       "000000010",
+		capsMark:  "000000011",
+		'k':       "0000000001",
+		'q':       "000000000001",
+		'e':       "101",
+		'o':       "10001",
+		'a':       "011",
+		'i':       "01001",
+		'h':       "0011",
+		'l':       "001001",
+		'u':       "00011",
+		'f':       "000100",
+		'p':       "0000101",
+		'w':       "0000011",
+		'y':       "0000001",
+		'j':       "0000000010",
+		titleMark: "0000000011",
+		'x':       "00000000001",
+		'z':       "00000000000000",
+		runeMark:  "00000000000001",
+		rawMark:   "0000000000001",
 	}
 }
@@ -1,22 +1,50 @@
 package vlc
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
 	"testing"
 )
 
-func TestEncodeBinary(t *testing.T) {
+func TestNewCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		want      code
+	}{
+		{str: "", want: code{}},
+		{str: "0", want: code{bits: 0, len: 1}},
+		{str: "11", want: code{bits: 0b11, len: 2}},
+		{str: "0000000000001", want: code{bits: 1, len: 13}},
+		{str: "001000", want: code{bits: 0b001000, len: 6}},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("convert %q to code", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, test.want, newCode(test.str), "newCode(%v)", test.str)
+		})
+	}
+}
+
+func TestByteCodesEncode(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name, str, want string
 	}{
 		{str: "", want: ""},
-		{str: "!ted", want: "001000100110100101"},
-		{str: "!my name is !ted", want: "001000000011000000111100000110000111011101001010111001000100110100101"},
+		{str: "Ted", want: "001000100110100101"},
+		{str: "My name is Ted", want: "001000000011000000111100000110000111011101001010111001000100110100101"},
 		{
-			str:  "!some pretty !s!u!bsequence",
+			str:  "Some pretty SUBsequence",
 			want: "0010000101100010000111011100001010100010110011001000000111001000010100100000011001000000001001011010000000000010001110110000000101101",
 		},
 	}
@@ -26,33 +54,134 @@ func TestEncodeBinary(t *testing.T) {
 		test.name = fmt.Sprintf("encoding %q", test.str)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			binary, err := encodeBinary(test.str)
-			assert.Equalf(t, test.want, binary, "encodeBinary(%v)", test.str)
-			assert.Nil(t, err)
+			assert.Equalf(t, test.want, encodeBitString([]byte(test.str)), "byteCodes.encode(%v)", test.str)
+		})
+	}
+}
+
+func TestByteCodesEncodeCase(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str, want string
+	}{
+		{str: "SQL", want: "000000011" + "0101" + "000000000001" + "001001"},
+		{str: "I", want: "001000" + "01001"},
+		{str: "Hi Bob.", want: "0000000011" + "0011" + "01001" + "11" + "0000010" + "10001" + "0000010" + "0000000000001" + "00101110"},
+		{str: "Hi Bob", want: "0000000011" + "0011" + "01001" + "11" + "0000010" + "10001" + "0000010"},
+		{str: "Hi bob", want: "001000" + "0011" + "01001" + "11" + "0000010" + "10001" + "0000010"},
+		{str: "Hi BOB", want: "001000" + "0011" + "01001" + "11" + "000000011" + "0000010" + "10001" + "0000010"},
+		{str: "SUBs", want: "001000" + "0101" + "001000" + "00011" + "001000" + "0000010" + "0101"},
+		{
+			str: "HTTP/1.1",
+			want: "000000011" + "0011" + "1001" + "1001" + "0000101" + "0000000000001" + "00101111" +
+				"0000000000001" + "00110001" + "0000000000001" + "00101110" + "0000000000001" + "00110001",
+		},
+		{str: "v j", want: "000000010" + "11" + "0000000010"},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encoding %q with case marks", test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, test.want, encodeBitString([]byte(test.str)), "byteCodes.encode(%v)", test.str)
 		})
 	}
 }
 
-func TestEncodingUnknownCharacterError(t *testing.T) {
+func TestByteCodesEncodeRaw(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name, str, error string
+		name, str, want string
 	}{
-		{str: "π", error: "encoding to binary error, unknown character 'π'"},
-		{str: "!ted Ω", error: "encoding to binary error, unknown character 'Ω'"},
-		{str: "!my name is ∑", error: "encoding to binary error, unknown character '∑'"},
+		{str: "!", want: "0000000000001" + "00100001"},
+		{str: "42", want: "0000000000001" + "00110100" + "0000000000001" + "00110010"},
+		{str: "Hi!", want: "001000" + "0011" + "01001" + "0000000000001" + "00100001"},
+		{str: "a\nb", want: "011" + "0000000000001" + "00001010" + "0000010"},
+		{str: "\x00\xff", want: "0000000000001" + "00000000" + "0000000000001" + "11111111"},
+		{str: "z", want: "00000000000000"},
+		{str: "π", want: "00000000000001" + "11001111" + "10000000"},
+		{str: "∑!", want: "00000000000001" + "11100010" + "10001000" + "10010001" + "0000000000001" + "00100001"},
+		{str: "😀", want: "00000000000001" + "11110000" + "10011111" + "10011000" + "10000000"},
+		{str: "\xcfa", want: "0000000000001" + "11001111" + "011"},
+		{str: "\xed\xa0\x80", want: "0000000000001" + "11101101" + "0000000000001" + "10100000" + "0000000000001" + "10000000"},
 	}
 
 	for _, test := range tests {
 		test := test
-		test.name = fmt.Sprintf("expect error when encoding an unknown character %q", test.str)
+		test.name = fmt.Sprintf("encoding %q with raw bytes and runes", test.str)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			binary, err := encodeBinary(test.str)
-			assert.Emptyf(t, binary, "encodeBinary(%v) not empty result when error", test.str)
-			assert.IsTypef(t, &EncodingError{}, err, "encodeBinary(%v) unexpected error type", test.str)
-			assert.Equalf(t, test.error, err.Error(), "encodeBinary(%v) unexpected error message", test.str)
+			assert.Equalf(t, test.want, encodeBitString([]byte(test.str)), "byteCodes.encode(%v)", test.str)
 		})
 	}
 }
+
+func TestByteCodesEncodeIncomplete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		final     bool
+		want      int
+	}{
+		{str: "12\xcf", want: 2},
+		{str: "12\xe2\x88", want: 2},
+		{str: "12\xcf", final: true, want: 3},
+		{str: "12\xcf.", want: 4},
+		{str: "1 ab", want: 2},
+		{str: "1 ab", final: true, want: 4},
+		{str: "1 ab ", want: 5},
+		{str: "1 Ab Cd ", want: 2},
+		{str: "1 Ab Cd ", final: true, want: 8},
+		{str: "1 Ab Cd.", want: 8},
+		{str: "1 Ab cd", want: 5},
+		{str: "1 " + strings.Repeat("a", maxLookahead), want: maxLookahead + 2},
+		{str: "1 " + strings.Repeat("Ab ", maxLookahead/3+1), want: 5},
+	}
+
+	bc := newByteCodes(newEncodingTable())
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("encoding %.16q with final %t", test.str, test.final)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			n, err := bc.encode(bitio.NewBitWriter(io.Discard), []byte(test.str), test.final)
+			assert.Nilf(t, err, "byteCodes.encode(%v) unexpected error", test.str)
+			assert.Equalf(t, test.want, n, "byteCodes.encode(%v) encoded bytes", test.str)
+		})
+	}
+}
+
+// encodeBitString encodes data with the default table and returns the codes as a string of '0' and '1'.
+func encodeBitString(data []byte) string {
+	var buf bytes.Buffer
+
+	w := bitio.NewBitWriter(&buf)
+	_, _ = newByteCodes(newEncodingTable()).encode(w, data, true)
+	bits := w.Count()
+	_ = w.Flush()
+
+	var str strings.Builder
+	for _, b := range buf.Bytes() {
+		str.WriteString(fmt.Sprintf("%08b", b))
+	}
+
+	return str.String()[:bits]
+}
+
+// fromBitString converts a string of '0' and '1' to bytes, the last byte is padded with zero bits.
+func fromBitString(str string) []byte {
+	var buf bytes.Buffer
+
+	w := bitio.NewBitWriter(&buf)
+	for _, bit := range str {
+		_ = w.WriteBit(bit == '1')
+	}
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
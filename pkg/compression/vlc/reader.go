@@ -0,0 +1,141 @@
+package vlc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// CorruptInputError reports the packed data that cannot be unpacked, it is ErrCorrupted for errors.Is.
+type CorruptInputError struct {
+	// Offset is the position of the corrupted data in bits, counted from the end of the header.
+	Offset uint64
+	reason string
+}
+
+func newCorruptInputError(offset uint64, format string, a ...any) *CorruptInputError {
+	return &CorruptInputError{Offset: offset, reason: fmt.Sprintf(format, a...)}
+}
+
+func (e *CorruptInputError) Error() string {
+	return fmt.Sprintf("%v at bit %d: %s", ErrCorrupted, e.Offset, e.reason)
+}
+
+func (e *CorruptInputError) Unwrap() error {
+	return ErrCorrupted
+}
+
+// Reader is an io.ReadCloser that unpacks the data read from the underlying reader.
+//
+// The Header is read and validated on the first call to Read.
+type Reader struct {
+	Header     compression.Header
+	r          bitio.ByteReader
+	readHeader bool
+	br         *bitio.BitReader
+	table      *decodingTable
+	buf        []byte
+	decoded    []byte
+	read       uint64
+	crc        uint32
+	err        error
+}
+
+// NewReader returns a new Reader unpacking the data read from r.
+//
+// If r does not also implement io.ByteReader, the Reader may read more data than necessary from r.
+// It is the caller's responsibility to call Close on the Reader when done.
+func NewReader(r io.Reader) *Reader {
+	br := bitio.NewByteReader(r)
+
+	return &Reader{
+		r:     br,
+		br:    bitio.NewBitReader(br),
+		table: newDecodingTable(newDecodingTree(newEncodingTable())),
+	}
+}
+
+// Read reads up to len(p) unpacked bytes into p.
+func (z *Reader) Read(p []byte) (int, error) {
+	if !z.readHeader && z.err == nil {
+		z.Header, z.err = compression.ReadCodecHeader(z.r, compression.VLC)
+		z.readHeader = true
+	}
+
+	for len(z.decoded) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.decoded, z.err = z.readBlock()
+	}
+
+	n := copy(p, z.decoded)
+	z.decoded = z.decoded[n:]
+
+	return n, nil
+}
+
+// Close closes the Reader. It does not close the underlying reader.
+func (z *Reader) Close() error {
+	z.decoded = nil
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Reader's state and makes it equivalent to the result of NewReader(r).
+func (z *Reader) Reset(r io.Reader) {
+	z.r = bitio.NewByteReader(r)
+	z.br.Reset(z.r)
+	z.Header = compression.Header{}
+	z.readHeader = false
+	z.decoded = nil
+	z.read = 0
+	z.crc = 0
+	z.err = nil
+}
+
+// readBlock reads and decodes the next block, it returns io.EOF at the end of the data mark.
+//
+// The blocks are read with the bit reader, so its count is the offset of the errors.
+func (z *Reader) readBlock() ([]byte, error) {
+	start := z.br.Count()
+	bits, err := binary.ReadUvarint(z.br)
+	if err != nil && z.br.Count()-start == binary.MaxVarintLen64*8 {
+		// all the bytes of the longest uvarint are read, so it overflows
+		return nil, newCorruptInputError(start, "invalid block length: %v", err)
+	}
+	if err != nil {
+		return nil, bitio.NoEOF(err)
+	}
+
+	if bits == 0 {
+		if z.Header.Flags&compression.FlagSize != 0 && z.read != z.Header.Size {
+			return nil, newCorruptInputError(start, "unpacked %d bytes, but header size is %d", z.read, z.Header.Size)
+		}
+		if err := compression.ReadChecksum(z.r, z.Header, z.crc, ErrCorrupted); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if bits > maxBlockBits {
+		return nil, newCorruptInputError(start, "block of %d bits is too long", bits)
+	}
+
+	z.buf, err = z.table.decode(z.br, bits, z.buf[:0])
+	if err != nil {
+		return nil, err
+	}
+	z.br.Align()
+	decoded := z.buf
+
+	z.read += uint64(len(decoded))
+	z.crc = compression.UpdateChecksum(z.crc, decoded)
+	if z.Header.Flags&compression.FlagSize != 0 && z.read > z.Header.Size {
+		return nil, newCorruptInputError(start, "unpacked data exceeds header size %d", z.Header.Size)
+	}
+
+	return decoded, nil
+}
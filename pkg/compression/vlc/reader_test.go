@@ -0,0 +1,239 @@
+package vlc
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		bytes []byte
+		want  string
+		bits  int
+	}{
+		{bytes: []byte{}, want: "", bits: 0},
+		{bytes: []byte{0b00100010, 0b01101001, 0b01000000}, want: "Ted", bits: 18},
+		{
+			bytes: []byte{
+				0b00100000, 0b00110000, 0b00111100, 0b00011000, 0b01110111, 0b01001010, 0b11100100, 0b01001101,
+				0b00101000,
+			},
+			want: "My name is Ted",
+			bits: 69,
+		},
+		{
+			bytes: []byte{
+				0b00100001, 0b01100010, 0b00011101, 0b11000010, 0b10100010, 0b11001100, 0b10000001, 0b11001000,
+				0b01010010, 0b00000110, 0b01000000, 0b00100101, 0b10100000, 0b00000010, 0b00111011, 0b00000001,
+				0b01101000,
+			},
+			want: "Some pretty SUBsequence",
+			bits: 133,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q byte by byte", test.want)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(streamed(test.bits, test.bytes...))))
+			data, err := io.ReadAll(r)
+			assert.Nilf(t, err, "Reader.Read(%v) unexpected error", test.bytes)
+			assert.Equalf(t, []byte(test.want), data, "Reader.Read(%v)", test.bytes)
+		})
+	}
+}
+
+func TestReaderLargeInput(t *testing.T) {
+	t.Parallel()
+
+	want := []byte(strings.Repeat("Some pretty SUBsequence, 42!\n", 1000))
+	packed, _ := New().Pack(want)
+
+	data, err := io.ReadAll(NewReader(bytes.NewReader(packed)))
+	assert.Nil(t, err)
+	assert.Equal(t, want, data)
+}
+
+func TestReaderError(t *testing.T) {
+	t.Parallel()
+
+	data := streamed(18, 0b00100010, 0b01101001, 0b01000000)
+	r := NewReader(iotest.TimeoutReader(bytes.NewReader(data[:len(data)-len(endMark)])))
+	_, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, iotest.ErrTimeout)
+}
+
+func TestReaderClosed(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(streamed(18, 0b00100010, 0b01101001, 0b01000000)))
+	assert.Nil(t, r.Close())
+
+	n, err := r.Read(make([]byte, 1))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(streamed(16, 0b00100000, 0b00110000)))
+	_, _ = r.Read(make([]byte, 1))
+
+	r.Reset(bytes.NewReader(streamed(18, 0b00100010, 0b01101001, 0b01000000)))
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ted"), data)
+}
+
+func TestReaderHeader(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(bytes.NewReader(packed("Ted", 18, 0b00100010, 0b01101001, 0b01000000)))
+	_, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		compression.Header{
+			Version: compression.Version,
+			Codec:   compression.VLC,
+			Flags:   compression.FlagSize | compression.FlagChecksum,
+			Size:    3,
+		},
+		r.Header,
+	)
+}
+
+func TestReaderPaddingIsNotDecoded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		padding byte
+	}{
+		{name: "zero", padding: 0b00000000},
+		{name: "space codes", padding: 0b00111111},
+		{name: "'e' and 'a' codes", padding: 0b00101011},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking %q with %s padding", "Ted", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := io.ReadAll(NewReader(bytes.NewReader(streamed(18, 0b00100010, 0b01101001, 0b01000000|test.padding))))
+			assert.Nil(t, err)
+			assert.Equal(t, []byte("Ted"), data)
+		})
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, error string
+		data        []byte
+		err         error
+	}{
+		{
+			name:  "size is less than header size",
+			data:  concat(header(compression.Header{Flags: compression.FlagSize, Size: 4}), block(18, 0b00100010, 0b01101001, 0b01000000)),
+			err:   ErrCorrupted,
+			error: "vlc: packed data is corrupted at bit 32: unpacked 3 bytes, but header size is 4",
+		},
+		{
+			name:  "size exceeds header size",
+			data:  concat(header(compression.Header{Flags: compression.FlagSize, Size: 2}), block(18, 0b00100010, 0b01101001, 0b01000000)),
+			err:   ErrCorrupted,
+			error: "vlc: packed data is corrupted at bit 0: unpacked data exceeds header size 2",
+		},
+		{
+			name:  "block ends with incomplete code",
+			data:  streamed(17, 0b00100010, 0b01101001, 0b01000000),
+			err:   ErrCorrupted,
+			error: "vlc: packed data is corrupted at bit 21: block ends with incomplete code",
+		},
+		{
+			name:  "too long block",
+			data:  streamed(maxBlockBits+1, 0b00100010, 0b01101001, 0b01000000),
+			err:   ErrCorrupted,
+			error: "vlc: packed data is corrupted at bit 0: block of 262145 bits is too long",
+		},
+		{
+			name:  "overflowing block length",
+			data:  append(header(compression.Header{}), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02),
+			err:   ErrCorrupted,
+			error: "vlc: packed data is corrupted at bit 0: invalid block length: binary: varint overflows a 64-bit integer",
+		},
+		{
+			name:  "truncated block",
+			data:  streamed(18, 0b00100010, 0b01101001)[:compression.HeaderSize+3],
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name:  "missing end mark",
+			data:  append(header(compression.Header{}), block(18, 0b00100010, 0b01101001, 0b01000000)...),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+		{
+			name: "checksum mismatch",
+			data: packed("Tex", 18, 0b00100010, 0b01101001, 0b01000000),
+			err:  ErrCorrupted,
+			error: fmt.Sprintf(
+				"vlc: packed data is corrupted: compression: checksum mismatch: unpacked data has %08x, but packed data has %08x",
+				crc32.ChecksumIEEE([]byte("Ted")), crc32.ChecksumIEEE([]byte("Tex")),
+			),
+		},
+		{
+			name:  "truncated checksum",
+			data:  bytes.TrimSuffix(packed("Ted", 18, 0b00100010, 0b01101001, 0b01000000), checksum("Ted")[2:]),
+			err:   io.ErrUnexpectedEOF,
+			error: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("unpacking data with %s", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := io.ReadAll(NewReader(bytes.NewReader(test.data)))
+			assert.ErrorIsf(t, err, test.err, "Reader.Read(%v) unexpected error", test.data)
+			assert.Equalf(t, test.error, err.Error(), "Reader.Read(%v) unexpected error message", test.data)
+		})
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	data := corpus()
+	packed, err := New().Pack(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := NewReader(bytes.NewReader(packed))
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Reset(bytes.NewReader(packed))
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
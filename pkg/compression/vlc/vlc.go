@@ -1,76 +1,47 @@
 package vlc
 
 import (
-	"strings"
-	"unicode"
+	"flag"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
 )
 
+func init() {
+	compression.Register("vlc", compression.Factory{
+		Description: "variable-length code",
+		Extension:   "vlc",
+		Magic:       compression.HeaderMagic(compression.VLC),
+		Pack: func(_ *flag.FlagSet) compression.WriterFunc {
+			return newWriter
+		},
+		Unpack: func(_ *flag.FlagSet) compression.ReaderFunc {
+			return newReader
+		},
+	})
+}
+
 type Codec struct{}
 
 func New() Codec {
 	return Codec{}
 }
 
-func (_ Codec) Pack(str string) ([]byte, error) {
-	bString, err := encodeBinary(escapeUpper(str))
-	if err != nil {
-		return nil, err
-	}
-	return fromBinaryString(bString).Bytes(), nil
+func (_ Codec) Pack(data []byte) ([]byte, error) {
+	return compression.PackWith(newWriter, data)
 }
 
-func (_ Codec) Unpack(bytes []byte) (string, error) {
-	bString := fromBytes(bytes).String()
-	tree := newDecodingTree(newEncodingTable())
-	return unescapeUpper(tree.decodeBinary(bString)), nil
+func (_ Codec) Unpack(data []byte) ([]byte, error) {
+	return compression.UnpackWith(newReader, data)
 }
 
-// escapeUpper escape upper case chars:
-// changes <upper case letter> to ! + <lower case letter>.
-//
-// i.g.: My name is Ted -> !my name is !ted
-func escapeUpper(str string) string {
-	var buf strings.Builder
-
-	for _, ch := range str {
-		if unicode.IsUpper(ch) {
-			buf.WriteRune('!')
-			buf.WriteRune(unicode.ToLower(ch))
-		} else {
-			buf.WriteRune(ch)
-		}
-	}
-
-	return buf.String()
+// newWriter returns the Writer packing the data to w with the Header.
+func newWriter(w io.Writer, h compression.Header) (io.WriteCloser, error) {
+	z := NewWriter(w)
+	z.Header = h
+	return z, nil
 }
 
-// unescapeUpper unescape upper case chars:
-// changes <! + lower case letter> to <upper case letter> .
-//
-// It opposite to escapeUpper.
-//
-// i.g.: !my name is !ted -> My name is Ted
-func unescapeUpper(str string) string {
-	var (
-		buf        strings.Builder
-		capitalize = false
-	)
-	for _, ch := range str {
-		if capitalize {
-			buf.WriteRune(unicode.ToUpper(ch))
-			capitalize = false
-
-			continue
-		}
-
-		if ch == '!' {
-			capitalize = true
-
-			continue
-		} else {
-			buf.WriteRune(ch)
-		}
-	}
-
-	return buf.String()
+// newReader returns the Reader unpacking the data read from r.
+func newReader(r io.Reader) io.ReadCloser {
+	return NewReader(r)
 }
@@ -1,9 +1,20 @@
 package vlc
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
 	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
+	"unicode"
+	"unicode/utf8"
 )
 
 func TestCodecPack(t *testing.T) {
@@ -11,19 +22,22 @@ func TestCodecPack(t *testing.T) {
 
 	tests := []struct {
 		name, str string
+		bits      int
 		want      []byte
 	}{
-		{str: "", want: []byte{}},
-		{str: "Ted", want: []byte{0b00100010, 0b01101001, 0b01000000}},
+		{str: "", bits: 0, want: []byte{}},
+		{str: "Ted", bits: 18, want: []byte{0b00100010, 0b01101001, 0b01000000}},
 		{
-			str: "My name is Ted",
+			str:  "My name is Ted",
+			bits: 69,
 			want: []byte{
 				0b00100000, 0b00110000, 0b00111100, 0b00011000, 0b01110111, 0b01001010, 0b11100100, 0b01001101,
 				0b00101000,
 			},
 		},
 		{
-			str: "Some pretty SUBsequence",
+			str:  "Some pretty SUBsequence",
+			bits: 133,
 			want: []byte{
 				0b00100001, 0b01100010, 0b00011101, 0b11000010, 0b10100010, 0b11001100, 0b10000001, 0b11001000,
 				0b01010010, 0b00000110, 0b01000000, 0b00100101, 0b10100000, 0b00000010, 0b00111011, 0b00000001,
@@ -37,36 +51,84 @@ func TestCodecPack(t *testing.T) {
 		test.name = fmt.Sprintf("packing %q", test.str)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			bytes, _ := New().Pack(test.str)
-			assert.Equalf(t, test.want, bytes, "Codec.Pack(%v)", test.str)
+			bytes, _ := New().Pack([]byte(test.str))
+			assert.Equalf(t, packed(test.str, test.bits, test.want...), bytes, "Codec.Pack(%v)", test.str)
 		})
 	}
 }
 
-func TestCodecPackUnknownCharacterError(t *testing.T) {
+func TestCodecRoundTrip(t *testing.T) {
 	t.Parallel()
 
+	allBytes := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		allBytes = append(allBytes, byte(b))
+	}
+
 	tests := []struct {
-		name, str, error string
+		name string
+		data []byte
 	}{
-		{str: "®", error: "encoding to binary error, unknown character '®'"},
-		{str: "√", error: "encoding to binary error, unknown character '√'"},
-		{str: "∫", error: "encoding to binary error, unknown character '∫'"},
+		{name: "text with digits and punctuation", data: []byte("Hello, World! 1 + 2 = 3.\n")},
+		{name: "text with upper case marker", data: []byte("!!! Wow !")},
+		{name: "unicode text", data: []byte("Привет, мир ∑ π")},
+		{name: "text with exclamation marks", data: []byte("Wow!! Hi!\n!x !A")},
+		{name: "sql", data: []byte("SELECT id, Name FROM users WHERE Name = 'Ted' AND age > 42;\n")},
+		{name: "text in title case", data: []byte("The Quick Brown Fox Jumps Over The Lazy Dog\nA Tale Of Two Cities. I Am")},
+		{name: "log", data: []byte("2024-01-02 ERROR [HTTP] GET /Index.HTML failed: Connection Refused\nWARN  retrying")},
+		{name: "text in mixed case", data: []byte("iPhone McDonald SUBsequence aBC AbC ABc A B C")},
+		{name: "unicode letters with case", data: []byte("ÄÖÜ äöü ΣΑΣ Ǆ ǅ ǆ İı ẞß 😀👍")},
+		{name: "invalid utf-8", data: []byte("a\xcf b\xed\xa0\x80 \xf4\x90\x80\x80 \xc0\xaf \xcf")},
+		{name: "json", data: []byte(`{"name": "Ted", "age": 42, "tags": ["a", "b"]}`)},
+		{name: "every byte value", data: allBytes},
 	}
 
 	for _, test := range tests {
 		test := test
-		test.name = fmt.Sprintf("packing %q with error", test.str)
+		test.name = fmt.Sprintf("packing and unpacking %s", test.name)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			bytes, err := New().Pack(test.str)
-			assert.Nilf(t, bytes, "Codec.Pack(%v) not empty result when error", test.str)
-			assert.IsTypef(t, &EncodingError{}, err, "Codec.Pack(%v) unexpected error type", test.str)
-			assert.Equalf(t, test.error, err.Error(), "Codec.Pack(%v) unexpected error message", test.str)
+			packed, err := New().Pack(test.data)
+			assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", test.data)
+			unpacked, err := New().Unpack(packed)
+			assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+			assert.Equalf(t, test.data, unpacked, "Codec.Unpack(Codec.Pack(%v))", test.data)
 		})
 	}
 }
 
+func TestCodecRoundTripEveryRune(t *testing.T) {
+	t.Parallel()
+
+	var data []byte
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if utf8.ValidRune(r) {
+			data = utf8.AppendRune(data, r)
+		}
+	}
+
+	packed, err := New().Pack(data)
+	assert.Nil(t, err)
+	unpacked, err := New().Unpack(packed)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(data, unpacked), "Codec.Unpack(Codec.Pack(every rune)) differs from the runes")
+}
+
+// FuzzCodecRoundTrip checks that any data is unpacked as it was before packing.
+func FuzzCodecRoundTrip(f *testing.F) {
+	for _, str := range []string{"", "Ted", "Wow!! Hi!", "Привет, мир ∑ π 😀", "a\xcf b\xed\xa0\x80"} {
+		f.Add([]byte(str))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		packed, err := New().Pack(data)
+		assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", data)
+		unpacked, err := New().Unpack(packed)
+		assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+		assert.Equalf(t, data, unpacked, "Codec.Unpack(Codec.Pack(%v))", data)
+	})
+}
+
 func TestCodecUnpacking(t *testing.T) {
 	t.Parallel()
 
@@ -74,15 +136,17 @@ func TestCodecUnpacking(t *testing.T) {
 		name  string
 		bytes []byte
 		want  string
+		bits  int
 	}{
-		{bytes: []byte{}, want: ""},
-		{bytes: []byte{0b00100010, 0b01101001, 0b01000000}, want: "Ted"},
+		{bytes: []byte{}, want: "", bits: 0},
+		{bytes: []byte{0b00100010, 0b01101001, 0b01000000}, want: "Ted", bits: 18},
 		{
 			bytes: []byte{
 				0b00100000, 0b00110000, 0b00111100, 0b00011000, 0b01110111, 0b01001010, 0b11100100, 0b01001101,
 				0b00101000,
 			},
 			want: "My name is Ted",
+			bits: 69,
 		},
 		{
 			bytes: []byte{
@@ -91,6 +155,7 @@ func TestCodecUnpacking(t *testing.T) {
 				0b01101000,
 			},
 			want: "Some pretty SUBsequence",
+			bits: 133,
 		},
 	}
 	for _, test := range tests {
@@ -98,52 +163,211 @@ func TestCodecUnpacking(t *testing.T) {
 		test.name = fmt.Sprintf("unpacking %q", test.want)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			str, _ := New().Unpack(test.bytes)
-			assert.Equalf(t, test.want, str, "Codec.Unpack(%v)", test.bytes)
+			data, _ := New().Unpack(packed(test.want, test.bits, test.bytes...))
+			assert.Equalf(t, []byte(test.want), data, "Codec.Unpack(%v)", test.bytes)
 		})
 	}
 }
 
-func TestEscapeUpper(t *testing.T) {
+func TestCodecUnpackHeaderError(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name, str, want string
+		name  string
+		bytes []byte
+		err   error
 	}{
-		{str: "", want: ""},
-		{str: "Ted", want: "!ted"},
-		{str: "My name is Ted", want: "!my name is !ted"},
-		{str: "Some pretty SUBsequence", want: "!some pretty !s!u!bsequence"},
+		{name: "empty data", bytes: []byte{}, err: compression.ErrHeader},
+		{name: "raw payload", bytes: []byte{0b00100010, 0b01101001, 0b01000000}, err: compression.ErrHeader},
+		{
+			name:  "future version",
+			bytes: append([]byte(compression.Magic), compression.Version+1, byte(compression.VLC), 0, 0, 0, 0, 0, 0, 0, 0, 0),
+			err:   compression.NewVersionError(compression.Version + 1),
+		},
+		{
+			name:  "other codec",
+			bytes: append([]byte(compression.Magic), compression.Version, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0),
+			err:   compression.NewCodecMismatchError(0xff, compression.VLC),
+		},
 	}
 
 	for _, test := range tests {
 		test := test
-		test.name = fmt.Sprintf("escape upper case chars in %q", test.str)
+		test.name = fmt.Sprintf("unpacking %s with error", test.name)
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			assert.Equalf(t, test.want, escapeUpper(test.str), "escapeUpper(%v)", test.str)
+			data, err := New().Unpack(test.bytes)
+			assert.Emptyf(t, data, "Codec.Unpack(%v) not empty result when error", test.bytes)
+			assert.Equalf(t, test.err, err, "Codec.Unpack(%v) unexpected error", test.bytes)
 		})
 	}
 }
 
-func TestUnescapeUpper(t *testing.T) {
-	t.Parallel()
+// FuzzCodecUnpack checks that no input makes Unpack panic or fail with an unknown error, the regression corpus
+// found by fuzzing is in testdata/fuzz/FuzzCodecUnpack.
+func FuzzCodecUnpack(f *testing.F) {
+	for _, str := range []string{"", "Ted", "My name is Ted", "Raw bytes: \x00\xff and ~{}"} {
+		data, err := New().Pack([]byte(str))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add(streamed(17, 0b00100010, 0b01101001, 0b01000000))
+	f.Add(streamed(maxBlockBits+1, 0b00100010, 0b01101001, 0b01000000))
 
-	tests := []struct {
-		name, str, want string
-	}{
-		{str: "", want: ""},
-		{str: "!ted", want: "Ted"},
-		{str: "!my name is !ted", want: "My name is Ted"},
-		{str: "!some pretty !s!u!bsequence", want: "Some pretty SUBsequence"},
+	f.Fuzz(func(t *testing.T, data []byte) {
+		unpacked, err := New().Unpack(data)
+		if err != nil {
+			var (
+				version  *compression.VersionError
+				mismatch *compression.CodecMismatchError
+			)
+			known := errors.Is(err, ErrCorrupted) || errors.Is(err, io.ErrUnexpectedEOF) ||
+				errors.Is(err, compression.ErrHeader) || errors.As(err, &version) || errors.As(err, &mismatch)
+			assert.Truef(t, known, "Codec.Unpack(%v) unexpected error %v", data, err)
+			return
+		}
+
+		// the data unpacked without errors must survive packing again
+		repacked, err := New().Pack(unpacked)
+		assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", unpacked)
+		again, err := New().Unpack(repacked)
+		assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", repacked)
+		assert.Equalf(t, unpacked, again, "Codec.Unpack(Codec.Pack(%v))", unpacked)
+	})
+}
+
+// header returns the header of data packed with vlc.
+func header(h compression.Header) []byte {
+	var buf bytes.Buffer
+
+	h.Version = compression.Version
+	h.Codec = compression.VLC
+	_ = compression.WriteHeader(&buf, h)
+
+	return buf.Bytes()
+}
+
+// block returns the block of packed data with the given number of bits.
+func block(bits int, payload ...byte) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(bits))
+	return append(buf[:n], payload...)
+}
+
+// endMark is the block marking the end of the packed data.
+var endMark = block(0)
+
+// packed returns the data written by Codec.Pack for str: the header, a single block with the payload
+// of the given number of bits, the end mark and the checksum.
+func packed(str string, bits int, payload ...byte) []byte {
+	h := compression.Header{Flags: compression.FlagSize | compression.FlagChecksum, Size: uint64(len(str))}
+	return append(concat(header(h), block(bits, payload...)), checksum(str)...)
+}
+
+// checksum returns the checksum of str following the end mark.
+func checksum(str string) []byte {
+	crc := crc32.ChecksumIEEE([]byte(str))
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
+
+// streamed returns the data written by Writer by default: the header, a single block and the end mark.
+func streamed(bits int, payload ...byte) []byte {
+	return concat(header(compression.Header{}), block(bits, payload...))
+}
+
+// concat joins the header with the block, if it is not empty, and the end mark.
+func concat(header, block []byte) []byte {
+	data := append([]byte{}, header...)
+	if len(block) > 1 {
+		data = append(data, block...)
 	}
+	return append(data, endMark...)
+}
 
-	for _, test := range tests {
-		test := test
-		test.name = fmt.Sprintf("unescape upper case chars for %q", test.want)
-		t.Run(test.name, func(t *testing.T) {
+func TestCodecPadding(t *testing.T) {
+	t.Parallel()
+
+	table := newEncodingTable()
+	for char := range table {
+		// input is encoded with the code of char, bits is the length of all its codes
+		input, bits := string(char), len(table[char])
+		switch char {
+		case rawMark:
+			input, bits = "0", bits+rawBits
+		case upperMark:
+			input, bits = "A", bits+len(table['a'])
+		case runeMark:
+			input, bits = "π", bits+2*rawBits
+		case capsMark:
+			// the spaces keep the word apart from the leading 'e'
+			input, bits = " AB ", bits+2*len(table[' '])+len(table['a'])+len(table['b'])
+		case titleMark:
+			input = " Ab Cd "
+			bits += 3*len(table[' ']) + len(table['a']) + len(table['b']) + len(table['c']) + len(table['d'])
+		}
+
+		t.Run(fmt.Sprintf("packing and unpacking %q with every padding length", input), func(t *testing.T) {
 			t.Parallel()
-			assert.Equalf(t, test.want, unescapeUpper(test.str), "unescapeUpper(%v)", test.str)
+
+			paddings := map[uint64]bool{}
+			// 'e' code is 3 bits long, so 0-7 leading 'e' give every possible length of the last byte.
+			for prefix := 0; prefix < 8; prefix++ {
+				data := []byte(strings.Repeat("e", prefix) + input)
+
+				packed, err := New().Pack(data)
+				assert.Nilf(t, err, "Codec.Pack(%v) unexpected error", data)
+				n, _ := binary.Uvarint(packed[compression.HeaderSize:])
+				assert.Equalf(t, uint64(3*prefix+bits), n, "Codec.Pack(%q) is not encoded with the code of %q", data, input)
+				paddings[(8-n%8)%8] = true
+
+				unpacked, err := New().Unpack(packed)
+				assert.Nilf(t, err, "Codec.Unpack(%v) unexpected error", packed)
+				assert.Equalf(t, data, unpacked, "Codec.Unpack(Codec.Pack(%v))", data)
+			}
+
+			assert.Len(t, paddings, 8, "not every padding length is covered")
 		})
 	}
 }
+
+// benchCorpusSize is the size of the text corpus benchmarks are run on.
+const benchCorpusSize = 100 << 20
+
+var (
+	benchCorpusOnce sync.Once
+	benchCorpus     []byte
+)
+
+// corpus returns generated English-like text of benchCorpusSize bytes, the same on every call.
+func corpus() []byte {
+	benchCorpusOnce.Do(func() {
+		words := strings.Fields(
+			"the quick brown fox jumps over the lazy dog and then it runs away into the deep dark forest " +
+				"where nobody can see it again while some pretty subsequence of words is repeated many times " +
+				"in this text to look like a real English log with errors warnings info debug trace 42 100 7",
+		)
+		rnd := rand.New(rand.NewSource(1))
+
+		var buf bytes.Buffer
+		buf.Grow(benchCorpusSize)
+		for buf.Len() < benchCorpusSize {
+			sentence := 3 + rnd.Intn(12)
+			for i := 0; i < sentence; i++ {
+				word := words[rnd.Intn(len(words))]
+				if i == 0 {
+					word = strings.ToUpper(word[:1]) + word[1:]
+				} else {
+					buf.WriteByte(' ')
+				}
+				buf.WriteString(word)
+			}
+			buf.WriteString(".\n")
+		}
+
+		benchCorpus = buf.Bytes()[:benchCorpusSize]
+	})
+
+	return benchCorpus
+}
@@ -0,0 +1,229 @@
+package vlc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/psssix/archiver/pkg/bitio"
+	"github.com/psssix/archiver/pkg/compression"
+	"io"
+)
+
+// bufferSize is the size of the parts the input is encoded by, it bounds the memory used by Writer and Reader.
+const bufferSize = 4096
+
+const (
+	// blockBits is the number of encoded bits the Writer collects before writing them as a block.
+	blockBits = 64 * 1024
+	// maxBlockBits is the maximum length of a block accepted by the Reader:
+	// the Writer never exceeds blockBits by more than the length of the encoded part of the input.
+	maxBlockBits = 4 * blockBits
+)
+
+var (
+	ErrClosed    = errors.New("vlc: stream is closed")
+	ErrCorrupted = errors.New("vlc: packed data is corrupted")
+)
+
+// Writer is an io.WriteCloser that packs the data written to it using variable-length code.
+//
+// The packed data is written as blocks: the number of bits in the block as uvarint followed by the bits
+// padded to whole bytes, so padding is never decoded. An empty block marks the end of the data, it is followed
+// by the checksum of the data if the Header has FlagChecksum set.
+//
+// The Header is written before the first block, so its Size and Flags may be set until the first call
+// to Write, Flush or Close. Its Version and Codec are set by the Writer.
+type Writer struct {
+	Header      compression.Header
+	w           *bufio.Writer
+	wroteHeader bool
+	codes       *byteCodes
+	block       *bytes.Buffer
+	bw          *bitio.BitWriter
+	// pending is the start of the rune or the words at the end of the written data,
+	// it is encoded with the rest of them, see byteCodes.encode.
+	pending []byte
+	written uint64
+	crc     uint32
+	err     error
+}
+
+// NewWriter returns a new Writer packing the data to w.
+//
+// It is the caller's responsibility to call Close on the Writer when done, writes may be buffered until then.
+func NewWriter(w io.Writer) *Writer {
+	block := new(bytes.Buffer)
+
+	return &Writer{
+		w:     bufio.NewWriterSize(w, bufferSize),
+		codes: newByteCodes(newEncodingTable()),
+		block: block,
+		bw:    bitio.NewBitWriter(block),
+	}
+}
+
+// Write packs p and writes it to the underlying writer.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return 0, z.err
+	}
+
+	for written := 0; written < len(p); {
+		part := p[written:]
+		if len(part) > bufferSize {
+			part = part[:bufferSize]
+		}
+
+		if z.err = z.encode(part, false); z.err != nil {
+			return written, z.err
+		}
+		if z.bw.Count() >= blockBits {
+			if z.err = z.writeBlock(); z.err != nil {
+				return written, z.err
+			}
+		}
+
+		written += len(part)
+		z.written += uint64(len(part))
+		z.crc = compression.UpdateChecksum(z.crc, part)
+	}
+
+	return len(p), nil
+}
+
+// Flush writes all the data packed so far as a block to the underlying writer.
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.encode(nil, true); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(); z.err != nil {
+		return z.err
+	}
+
+	z.err = z.w.Flush()
+	return z.err
+}
+
+// Close writes the rest of the packed data and the end of the data mark to the underlying writer.
+// It does not close the underlying writer.
+//
+// If the Header has FlagSize set, Close fails when the number of written bytes differs from its Size.
+func (z *Writer) Close() error {
+	if z.err == ErrClosed {
+		return nil
+	}
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.encode(nil, true); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlock(); z.err != nil {
+		return z.err
+	}
+	if z.err = z.writeBlockLen(0); z.err != nil {
+		return z.err
+	}
+	if z.err = compression.WriteChecksum(z.w, z.Header, z.crc); z.err != nil {
+		return z.err
+	}
+	if z.err = z.w.Flush(); z.err != nil {
+		return z.err
+	}
+
+	if z.Header.Flags&compression.FlagSize != 0 && z.written != z.Header.Size {
+		z.err = fmt.Errorf("vlc: written %d bytes, but header size is %d", z.written, z.Header.Size)
+		return z.err
+	}
+	z.err = ErrClosed
+
+	return nil
+}
+
+// Reset discards the Writer's state and makes it equivalent to the result of NewWriter(w).
+func (z *Writer) Reset(w io.Writer) {
+	z.w.Reset(w)
+	z.Header = compression.Header{}
+	z.wroteHeader = false
+	z.block.Reset()
+	z.bw.Reset(z.block)
+	z.pending = z.pending[:0]
+	z.written = 0
+	z.crc = 0
+	z.err = nil
+}
+
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+
+	z.Header.Version = compression.Version
+	z.Header.Codec = compression.VLC
+	z.wroteHeader = true
+
+	return compression.WriteHeader(z.w, z.Header)
+}
+
+// encode encodes the pending bytes followed by p, the incomplete rune or words at the end are kept pending
+// unless final is set.
+func (z *Writer) encode(p []byte, final bool) error {
+	data := p
+	if len(z.pending) > 0 {
+		z.pending = append(z.pending, p...)
+		data = z.pending
+	}
+
+	n, err := z.codes.encode(z.bw, data, final)
+	if err != nil {
+		return err
+	}
+	z.pending = append(z.pending[:0], data[n:]...)
+
+	return nil
+}
+
+// writeBlock writes the bits packed since the previous block as a block, if any.
+func (z *Writer) writeBlock() error {
+	bits := z.bw.Count()
+	if bits == 0 {
+		return nil
+	}
+
+	if err := z.bw.Flush(); err != nil {
+		return err
+	}
+	if err := z.writeBlockLen(bits); err != nil {
+		return err
+	}
+	if _, err := z.w.Write(z.block.Bytes()); err != nil {
+		return err
+	}
+
+	z.block.Reset()
+	z.bw.Reset(z.block)
+
+	return nil
+}
+
+func (z *Writer) writeBlockLen(bits uint64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, bits)
+
+	_, err := z.w.Write(buf[:n])
+	return err
+}
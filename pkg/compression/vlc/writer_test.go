@@ -0,0 +1,205 @@
+package vlc
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/psssix/archiver/pkg/compression"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str      string
+		bits, partSize int
+		want           []byte
+	}{
+		{str: "", bits: 0, partSize: 1, want: []byte{}},
+		{str: "Ted", bits: 18, partSize: 1, want: []byte{0b00100010, 0b01101001, 0b01000000}},
+		{
+			str:      "My name is Ted",
+			bits:     69,
+			partSize: 3,
+			want: []byte{
+				0b00100000, 0b00110000, 0b00111100, 0b00011000, 0b01110111, 0b01001010, 0b11100100, 0b01001101,
+				0b00101000,
+			},
+		},
+		{
+			str:      "Some pretty SUBsequence",
+			bits:     133,
+			partSize: 5,
+			want: []byte{
+				0b00100001, 0b01100010, 0b00011101, 0b11000010, 0b10100010, 0b11001100, 0b10000001, 0b11001000,
+				0b01010010, 0b00000110, 0b01000000, 0b00100101, 0b10100000, 0b00000010, 0b00111011, 0b00000001,
+				0b01101000,
+			},
+		},
+		{
+			str:      "π!",
+			bits:     51,
+			partSize: 1,
+			want:     []byte{0b00000000, 0b00000111, 0b00111110, 0b00000000, 0b00000000, 0b00100100, 0b00100000},
+		},
+		{
+			str:      "SELECT Hi Bob.",
+			bits:     98,
+			partSize: 2,
+			want: []byte{
+				0b00000001, 0b10101101, 0b00100110, 0b10001011, 0b00111000, 0b00000110, 0b01101001, 0b11000001,
+				0b01000100, 0b00010000, 0b00000000, 0b01001011, 0b10000000,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("packing %q by parts of %d bytes", test.str, test.partSize)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer([]byte{})
+			w := NewWriter(buf)
+			data := []byte(test.str)
+			for len(data) > 0 {
+				n := test.partSize
+				if n > len(data) {
+					n = len(data)
+				}
+				_, err := w.Write(data[:n])
+				assert.Nilf(t, err, "Writer.Write(%v) unexpected error", data[:n])
+				data = data[n:]
+			}
+			assert.Nil(t, w.Close())
+			assert.Equalf(t, streamed(test.bits, test.want...), buf.Bytes(), "Writer.Write(%v)", test.str)
+		})
+	}
+}
+
+func TestWriterLargeInput(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("Some pretty SUBsequence, 42!\n", 1000))
+	want, _ := New().Pack(data)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Header.Flags = compression.FlagSize | compression.FlagChecksum
+	w.Header.Size = uint64(len(data))
+	n, err := w.Write(data)
+	assert.Nil(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, want, buf.Bytes())
+}
+
+func TestWriterFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	_, _ = w.Write([]byte("Ted"))
+	assert.Nil(t, w.Flush())
+	assert.Equal(
+		t,
+		append(header(compression.Header{}), block(18, 0b00100010, 0b01101001, 0b01000000)...),
+		buf.Bytes(),
+		"Writer.Flush() must write all packed data as a block",
+	)
+
+	_, _ = w.Write([]byte(" "))
+	assert.Nil(t, w.Close())
+	assert.Equal(
+		t,
+		concat(
+			header(compression.Header{}),
+			append(block(18, 0b00100010, 0b01101001, 0b01000000), block(2, 0b11000000)...),
+		),
+		buf.Bytes(),
+	)
+}
+
+func TestWriterFlushIncomplete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, str string
+		split     int
+	}{
+		{name: "rune", str: "π", split: 1},
+		{name: "word in upper case", str: "SELECT a", split: 3},
+		{name: "run of words in title case", str: "Hi Bob.", split: 4},
+	}
+
+	for _, test := range tests {
+		test := test
+		test.name = fmt.Sprintf("flushing incomplete %s of %q", test.name, test.str)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+
+			// the flushed part is packed as complete, but it is unpacked followed by the rest as it was
+			_, _ = w.Write([]byte(test.str)[:test.split])
+			assert.Nil(t, w.Flush())
+			_, _ = w.Write([]byte(test.str)[test.split:])
+			assert.Nil(t, w.Close())
+
+			data, err := io.ReadAll(NewReader(&buf))
+			assert.Nil(t, err)
+			assert.Equal(t, []byte(test.str), data)
+		})
+	}
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close(), "Writer.Close() must be idempotent")
+
+	n, err := w.Write([]byte("Ted"))
+	assert.Empty(t, n)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, w.Flush(), ErrClosed)
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	_, _ = w.Write([]byte("My name is"))
+
+	w.Reset(&second)
+	_, _ = w.Write([]byte("Ted"))
+	assert.Nil(t, w.Close())
+
+	assert.Empty(t, first.Bytes())
+	assert.Equal(t, streamed(18, 0b00100010, 0b01101001, 0b01000000), second.Bytes())
+}
+
+func BenchmarkWriter(b *testing.B) {
+	data := corpus()
+	w := NewWriter(io.Discard)
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w.Reset(io.Discard)
+		if _, err := w.Write(data); err != nil {
+			b.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package ignore implements matching of slash-separated paths with the patterns of .gitignore files.
+//
+// A pattern is a glob of path.Match for every segment of the path, the segment "**" matches any number
+// of segments. A pattern without a slash matches the name at any depth, a pattern with a slash is anchored
+// to the base directory. A pattern ending with a slash matches directories only, a pattern starting with "!"
+// includes again the paths ignored by the previous patterns.
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var errEmptyPattern = errors.New("empty pattern")
+
+// Pattern is a parsed pattern of a .gitignore file.
+type Pattern struct {
+	// segments are the globs of the segments of the path, the unanchored patterns start with "**".
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// ParsePattern parses the pattern, it returns an error wrapping path.ErrBadPattern if the pattern is malformed.
+func ParsePattern(s string) (Pattern, error) {
+	p, err := parsePattern(s)
+	if err != nil {
+		return Pattern{}, fmt.Errorf("ignore: %w", err)
+	}
+	return p, nil
+}
+
+func parsePattern(s string) (Pattern, error) {
+	var p Pattern
+
+	switch {
+	case strings.HasPrefix(s, "!"):
+		p.negate = true
+		s = s[1:]
+	case strings.HasPrefix(s, `\!`), strings.HasPrefix(s, `\#`):
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, "/") {
+		p.dirOnly = true
+		s = strings.TrimRight(s, "/")
+	}
+
+	anchored := strings.Contains(s, "/")
+	s = strings.TrimPrefix(s, "/")
+	if s == "" {
+		return Pattern{}, errEmptyPattern
+	}
+
+	p.segments = strings.Split(s, "/")
+	if !anchored {
+		p.segments = append([]string{"**"}, p.segments...)
+	}
+	for _, segment := range p.segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return Pattern{}, fmt.Errorf("%w: %q", err, s)
+		}
+	}
+
+	return p, nil
+}
+
+// Match reports whether the slash-separated path relative to the base directory matches the pattern.
+// It does not take the negation into account.
+func (p Pattern) Match(name string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	return match(p.segments, strings.Split(name, "/"))
+}
+
+// match reports whether the segments of the name match the globs of the segments of the pattern.
+func match(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			// the trailing "**" matches everything inside the directory, but not the directory itself
+			if len(pattern) == 0 {
+				return len(name) > 0
+			}
+			for i := range name {
+				if match(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// Matcher matches paths with the patterns of many .gitignore files, the last matching pattern wins.
+//
+// The zero value is a Matcher without patterns, it matches nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// rule is the pattern relative to the base directory.
+type rule struct {
+	base string
+	Pattern
+}
+
+// Add adds the pattern relative to the slash-separated base directory, the empty base is the root.
+func (m *Matcher) Add(base string, p Pattern) {
+	m.rules = append(m.rules, rule{base: base, Pattern: p})
+}
+
+// ReadPatterns adds the patterns read from r in the format of .gitignore files relative to the base directory,
+// see Add. Blank lines and lines starting with "#" are skipped, the trailing spaces are trimmed.
+func (m *Matcher) ReadPatterns(base string, r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimRight(s.Text(), " \t\r")
+		if strings.HasSuffix(text, `\`) && len(text) < len(s.Text()) {
+			// the escaped trailing space is kept
+			text += " "
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		p, err := parsePattern(text)
+		if err != nil {
+			return fmt.Errorf("ignore: line %d: %w", line, err)
+		}
+		m.Add(base, p)
+	}
+
+	return s.Err()
+}
+
+// Match reports whether the slash-separated path relative to the root is matched by the patterns.
+//
+// Only the path itself is matched, the paths inside the matched directories are expected to be skipped
+// by the caller, as they can't be included again.
+func (m *Matcher) Match(name string, isDir bool) bool {
+	matched := false
+	for _, r := range m.rules {
+		rel := name
+		if r.base != "" {
+			if !strings.HasPrefix(name, r.base+"/") {
+				continue
+			}
+			rel = name[len(r.base)+1:]
+		}
+		if r.Match(rel, isDir) {
+			matched = !r.negate
+		}
+	}
+	return matched
+}
+
+// Empty reports whether the Matcher has no patterns.
+func (m *Matcher) Empty() bool {
+	return len(m.rules) == 0
+}
@@ -0,0 +1,129 @@
+package ignore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestPatternMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, name string
+		isDir, want   bool
+	}{
+		{pattern: "*.log", name: "a.log", want: true},
+		{pattern: "*.log", name: "logs/deep/a.log", want: true},
+		{pattern: "*.log", name: "a.txt"},
+		{pattern: "logs", name: "logs", isDir: true, want: true},
+		{pattern: "logs", name: "src/logs", want: true},
+		{pattern: "logs/", name: "src/logs", isDir: true, want: true},
+		{pattern: "logs/", name: "src/logs"},
+		{pattern: "/logs", name: "logs", want: true},
+		{pattern: "/logs", name: "src/logs"},
+		{pattern: "src/*.go", name: "src/main.go", want: true},
+		{pattern: "src/*.go", name: "src/cmd/main.go"},
+		{pattern: "src/*.go", name: "lib/src/main.go"},
+		{pattern: "**/cache", name: "cache", isDir: true, want: true},
+		{pattern: "**/cache", name: "a/b/cache", isDir: true, want: true},
+		{pattern: "src/**/*.go", name: "src/main.go", want: true},
+		{pattern: "src/**/*.go", name: "src/cmd/root/main.go", want: true},
+		{pattern: "src/**", name: "src/cmd/main.go", want: true},
+		{pattern: "src/**", name: "src", isDir: true},
+		{pattern: "a?c", name: "abc", want: true},
+		{pattern: "[0-9].txt", name: "dir/7.txt", want: true},
+		{pattern: `\!important`, name: "!important", want: true},
+		{pattern: `\#notes`, name: "#notes", want: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("matching %q with pattern %q", test.name, test.pattern), func(t *testing.T) {
+			t.Parallel()
+
+			p, err := ParsePattern(test.pattern)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, p.Match(test.name, test.isDir))
+		})
+	}
+}
+
+func TestParsePatternError(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{"", "/", "!", "[a", "src/[a-/*.go"}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("parsing %q", test), func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParsePattern(test)
+			assert.NotNil(t, err)
+		})
+	}
+
+	_, err := ParsePattern("[a")
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+}
+
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+
+	var m Matcher
+	assert.True(t, m.Empty())
+	assert.False(t, m.Match("a.log", false), "the zero Matcher must match nothing")
+
+	assert.Nil(t, m.ReadPatterns("", strings.NewReader(
+		"# logs are not archived\n"+
+			"*.log\n"+
+			"\n"+
+			"!keep.log\n"+
+			"build/\n"+
+			"trailing\\ \n"+
+			"spaces   \n",
+	)))
+	assert.Nil(t, m.ReadPatterns("docs", strings.NewReader("*.tmp\n!draft.log\n/index.html\n")))
+	assert.False(t, m.Empty())
+
+	tests := []struct {
+		name        string
+		isDir, want bool
+	}{
+		{name: "a.log", want: true},
+		{name: "dir/keep.log"},
+		{name: "build", isDir: true, want: true},
+		{name: "build"},
+		{name: "trailing ", want: true},
+		{name: "trailing"},
+		{name: "spaces", want: true},
+		{name: "a.tmp"},
+		{name: "docs/a.tmp", want: true},
+		{name: "docs/draft.log"},
+		{name: "docs/old.log", want: true},
+		{name: "draft.log", want: true},
+		{name: "docs/index.html", want: true},
+		{name: "docs/api/index.html"},
+		{name: "docsy/a.tmp"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("matching %q", test.name), func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, m.Match(test.name, test.isDir))
+		})
+	}
+}
+
+func TestMatcherReadPatternsError(t *testing.T) {
+	t.Parallel()
+
+	var m Matcher
+	err := m.ReadPatterns("", strings.NewReader("*.log\n\nsrc/[a\n"))
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+	assert.EqualError(t, err, `ignore: line 3: syntax error in pattern: "src/[a"`)
+}